@@ -1,9 +1,15 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"service-template/internal/config"
 	"service-template/internal/daemon"
+	"service-template/internal/daemon/tasks"
+	"service-template/internal/dev"
+	"service-template/internal/scheduler"
+	"service-template/internal/transfer"
+	"service-template/internal/worker"
 	"service-template/pkg/migrator"
 
 	"github.com/rs/zerolog"
@@ -45,19 +51,38 @@ func main() {
 
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:     "config",
-				Usage:    "Load configuration from `FILE`",
-				Aliases:  []string{"c"},
-				Required: true,
+				Name:    "config",
+				Usage:   "Load configuration from `FILE`",
+				Aliases: []string{"c"},
 			},
 		},
 
 		Commands: []*cli.Command{
 			migrator.MigrateCommands(),
+			transfer.UsersCommands(),
+			worker.JobsCommands(),
+			scheduler.Commands(tasks.New),
+			daemon.HealthcheckCommand(),
+			dev.Command(&logger),
 		},
 
 		// Перед выполнением action`s инициализируем параметры
 		Before: func(c *cli.Context) error {
+			// Команда dev создает конфигурацию сама, а healthcheck загружает ее,
+			// только если не задан адрес проверки
+			switch c.Args().First() {
+			case "dev":
+				return nil
+			case "healthcheck":
+				if c.String("config") == "" {
+					return nil
+				}
+			}
+
+			if c.String("config") == "" {
+				return errors.New(`required flag "config" not set`)
+			}
+
 			if cfg, err = config.New(c.String("config")); err != nil {
 				return err
 			}
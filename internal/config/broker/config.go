@@ -0,0 +1,79 @@
+package broker
+
+import (
+	"time"
+
+	"service-template/internal/config/queue"
+	"service-template/pkg/broker"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	DriverMemory = "memory"
+	DriverRedis  = "redis"
+	DriverNATS   = "nats"
+)
+
+// Config параметры брокера сообщений. Параметры группы потребителей,
+// повторов и конкурентности общие для всех драйверов и задаются в секции queue.
+type Config struct {
+	Driver         string        `json:"driver" yaml:"driver" env:"X_BROKER_DRIVER"`
+	NATSURL        string        `json:"nats_url" yaml:"nats_url" env:"X_BROKER_NATS_URL"`
+	NATSStream     string        `json:"nats_stream" yaml:"nats_stream" env:"X_BROKER_NATS_STREAM"`
+	NATSAckWait    time.Duration `json:"nats_ack_wait" yaml:"nats_ack_wait" env:"X_BROKER_NATS_ACK_WAIT"`
+	NATSTimeout    time.Duration `json:"nats_timeout" yaml:"nats_timeout" env:"X_BROKER_NATS_TIMEOUT"`
+	MemoryCapacity int           `json:"memory_capacity" yaml:"memory_capacity" env:"X_BROKER_MEMORY_CAPACITY"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		Driver:         DriverRedis,
+		NATSURL:        "nats://127.0.0.1:4222",
+		NATSStream:     "template",
+		NATSAckWait:    30 * time.Second,
+		NATSTimeout:    5 * time.Second,
+		MemoryCapacity: 10000,
+	}
+}
+
+func (cfg Config) Validate() error {
+	nats := cfg.Driver == DriverNATS
+
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Driver, validation.Required, validation.In(DriverMemory, DriverRedis, DriverNATS)),
+		validation.Field(&cfg.NATSURL, validation.Required.When(nats)),
+		validation.Field(&cfg.NATSStream, validation.Required.When(nats)),
+		validation.Field(&cfg.NATSAckWait, validation.Required.When(nats)),
+		validation.Field(&cfg.NATSTimeout, validation.Required.When(nats)),
+		validation.Field(&cfg.MemoryCapacity, validation.When(cfg.Driver == DriverMemory, validation.Required, validation.Min(1))),
+	)
+}
+
+// Memory возвращает параметры брокера в памяти.
+func (cfg *Config) Memory(q *queue.Config) broker.MemoryConfig {
+	return broker.MemoryConfig{
+		Capacity:    cfg.MemoryCapacity,
+		Concurrency: q.Concurrency,
+		MaxRetries:  q.MaxRetries,
+		Backoff:     q.Backoff,
+		MaxBackoff:  q.MaxBackoff,
+	}
+}
+
+// NATS возвращает параметры брокера NATS JetStream.
+func (cfg *Config) NATS(q *queue.Config) broker.NATSConfig {
+	return broker.NATSConfig{
+		URL:         cfg.NATSURL,
+		Stream:      cfg.NATSStream,
+		Group:       q.Group,
+		Concurrency: q.Concurrency,
+		MaxRetries:  q.MaxRetries,
+		Backoff:     q.Backoff,
+		MaxBackoff:  q.MaxBackoff,
+		AckWait:     cfg.NATSAckWait,
+		FetchWait:   q.Block,
+		Timeout:     cfg.NATSTimeout,
+	}
+}
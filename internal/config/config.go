@@ -1,28 +1,70 @@
 package config
 
 import (
+	"errors"
 	"path/filepath"
+	"service-template/internal/config/broker"
+	"service-template/internal/config/features"
+	"service-template/internal/config/health"
+	"service-template/internal/config/jobs"
 	"service-template/internal/config/logger"
+	"service-template/internal/config/metrics"
+	"service-template/internal/config/outbox"
+	"service-template/internal/config/queue"
+	"service-template/internal/config/scheduler"
 	"service-template/internal/config/server"
+	"service-template/internal/config/startup"
+	"service-template/internal/config/tracing"
+	"service-template/internal/config/webhooks"
 	"service-template/pkg/drivers/postgres"
 	"service-template/pkg/drivers/redisdb"
+	"service-template/pkg/drivers/sqlite"
+	"service-template/pkg/txmanager"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
-	Server   *server.Config   `json:"server" yaml:"server"`
-	Logger   *logger.Config   `json:"logger" yaml:"logger"`
-	Redis    *redisdb.Config  `json:"redis" yaml:"redis"`
-	Postgres *postgres.Config `json:"postgres" yaml:"postgres"`
+	Server    *server.Config    `json:"server" yaml:"server"`
+	Logger    *logger.Config    `json:"logger" yaml:"logger"`
+	Redis     *redisdb.Config   `json:"redis" yaml:"redis"`
+	Postgres  *postgres.Config  `json:"postgres" yaml:"postgres"`
+	SQLite    *sqlite.Config    `json:"sqlite" yaml:"sqlite"`
+	Tx        *txmanager.Config `json:"tx" yaml:"tx"`
+	Outbox    *outbox.Config    `json:"outbox" yaml:"outbox"`
+	Webhooks  *webhooks.Config  `json:"webhooks" yaml:"webhooks"`
+	Queue     *queue.Config     `json:"queue" yaml:"queue"`
+	Broker    *broker.Config    `json:"broker" yaml:"broker"`
+	Jobs      *jobs.Config      `json:"jobs" yaml:"jobs"`
+	Scheduler *scheduler.Config `json:"scheduler" yaml:"scheduler"`
+	Features  *features.Config  `json:"features" yaml:"features"`
+	Health    *health.Config    `json:"health" yaml:"health"`
+	Metrics   *metrics.Config   `json:"metrics" yaml:"metrics"`
+	Tracing   *tracing.Config   `json:"tracing" yaml:"tracing"`
+	Startup   *startup.Config   `json:"startup" yaml:"startup"`
 }
 
 // New создает новую конфигурацию и загружает значения из файла.
 // Если заданы переменные окружения, тогда они будут иметь приоритет.
 func New(filename string) (*Config, error) {
 	cfg := Config{
-		Postgres: &postgres.Config{},
+		Server:    server.NewConfig(),
+		Redis:     redisdb.NewConfig(),
+		Postgres:  postgres.NewConfig(),
+		SQLite:    sqlite.NewConfig(),
+		Tx:        txmanager.NewConfig(),
+		Outbox:    outbox.NewConfig(),
+		Webhooks:  webhooks.NewConfig(),
+		Queue:     queue.NewConfig(),
+		Broker:    broker.NewConfig(),
+		Jobs:      jobs.NewConfig(),
+		Scheduler: scheduler.NewConfig(),
+		Features:  features.NewConfig(),
+		Health:    health.NewConfig(),
+		Metrics:   metrics.NewConfig(),
+		Tracing:   tracing.NewConfig(),
+		Startup:   startup.NewConfig(),
 	}
 
 	path, err := filepath.Abs(filename)
@@ -38,11 +80,38 @@ func New(filename string) (*Config, error) {
 }
 
 // Validate проверяет заполнение обязательных полей.
+// Параметры Postgres не проверяются, если вместо него используется SQLite.
 func (cfg *Config) Validate() error {
 	return validation.ValidateStruct(cfg,
 		validation.Field(&cfg.Server),
 		validation.Field(&cfg.Logger),
 		validation.Field(&cfg.Redis),
-		validation.Field(&cfg.Postgres),
+		validation.Field(&cfg.Postgres, validation.Skip.When(cfg.SQLite.Enabled)),
+		validation.Field(&cfg.SQLite),
+		validation.Field(&cfg.Tx),
+		validation.Field(&cfg.Outbox),
+		validation.Field(&cfg.Webhooks, validation.By(func(interface{}) error {
+			// События для вебхуков ставятся в очередь через outbox.
+			if cfg.Webhooks.Enabled && !cfg.Outbox.Enabled {
+				return validation.Errors{"enabled": errors.New("webhooks require outbox to be enabled")}
+			}
+
+			return nil
+		})),
+		validation.Field(&cfg.Queue),
+		validation.Field(&cfg.Broker),
+		validation.Field(&cfg.Jobs),
+		validation.Field(&cfg.Scheduler, validation.By(func(interface{}) error {
+			if cfg.SQLite.Enabled && cfg.Scheduler.Locker == scheduler.LockerPostgres {
+				return validation.Errors{"locker": errors.New("postgres locker is not supported with sqlite")}
+			}
+
+			return nil
+		})),
+		validation.Field(&cfg.Features),
+		validation.Field(&cfg.Health),
+		validation.Field(&cfg.Metrics),
+		validation.Field(&cfg.Tracing),
+		validation.Field(&cfg.Startup),
 	)
 }
@@ -0,0 +1,30 @@
+package features
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	Environment     string        `json:"environment" yaml:"environment" env:"X_FEATURES_ENVIRONMENT"`
+	Channel         string        `json:"channel" yaml:"channel" env:"X_FEATURES_CHANNEL"`
+	RefreshInterval time.Duration `json:"refresh_interval" yaml:"refresh_interval" env:"X_FEATURES_REFRESH_INTERVAL"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		Environment:     "development",
+		Channel:         "features",
+		RefreshInterval: time.Minute,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Environment, validation.Required),
+		validation.Field(&cfg.Channel, validation.Required),
+		validation.Field(&cfg.RefreshInterval, validation.Required, validation.Min(time.Second)),
+	)
+}
@@ -0,0 +1,28 @@
+package health
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	// Timeout максимальное время выполнения одной проверки.
+	Timeout time.Duration `json:"timeout" yaml:"timeout" env:"X_HEALTH_TIMEOUT"`
+	// Migrations проверять, что все миграции применены.
+	Migrations bool `json:"migrations" yaml:"migrations" env:"X_HEALTH_MIGRATIONS"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		Timeout:    2 * time.Second,
+		Migrations: true,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Timeout, validation.Required, validation.Min(10*time.Millisecond)),
+	)
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"os"
+	"time"
+
+	"service-template/internal/config/valid"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled" env:"X_JOBS_ENABLED"`
+	Worker       string        `json:"worker" yaml:"worker" env:"X_JOBS_WORKER"`
+	Concurrency  int           `json:"concurrency" yaml:"concurrency" env:"X_JOBS_CONCURRENCY"`
+	Interval     time.Duration `json:"interval" yaml:"interval" env:"X_JOBS_INTERVAL"`
+	Backoff      time.Duration `json:"backoff" yaml:"backoff" env:"X_JOBS_BACKOFF"`
+	MaxBackoff   time.Duration `json:"max_backoff" yaml:"max_backoff" env:"X_JOBS_MAX_BACKOFF"`
+	LockTimeout  time.Duration `json:"lock_timeout" yaml:"lock_timeout" env:"X_JOBS_LOCK_TIMEOUT"`
+	DrainTimeout time.Duration `json:"drain_timeout" yaml:"drain_timeout" env:"X_JOBS_DRAIN_TIMEOUT"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+// Имя обработчика по умолчанию совпадает с именем хоста.
+func NewConfig() *Config {
+	hostname, _ := os.Hostname()
+
+	return &Config{
+		Worker:       hostname,
+		Concurrency:  10,
+		Interval:     time.Second,
+		Backoff:      10 * time.Second,
+		MaxBackoff:   time.Hour,
+		LockTimeout:  5 * time.Minute,
+		DrainTimeout: 30 * time.Second,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Worker, validation.Required, validation.Match(valid.Name)),
+		validation.Field(&cfg.Concurrency, validation.Required, validation.Min(1)),
+		validation.Field(&cfg.Interval, validation.Required, validation.Min(10*time.Millisecond)),
+		validation.Field(&cfg.Backoff, validation.Required),
+		validation.Field(&cfg.MaxBackoff, validation.Required),
+		validation.Field(&cfg.LockTimeout, validation.Required, validation.Min(time.Second)),
+		validation.Field(&cfg.DrainTimeout, validation.Required),
+	)
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type Config struct {
+	Enabled bool `json:"enabled" yaml:"enabled" env:"X_METRICS_ENABLED"`
+	// Port отдельный порт для метрик, если не задан, метрики отдаются основным сервером.
+	Port string `json:"port" yaml:"port" env:"X_METRICS_PORT"`
+	Path string `json:"path" yaml:"path" env:"X_METRICS_PATH"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		Enabled: true,
+		Path:    "/metrics",
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Port, is.Port),
+		validation.Field(&cfg.Path, validation.Required),
+	)
+}
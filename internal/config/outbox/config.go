@@ -0,0 +1,53 @@
+package outbox
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	// PublisherLog пишет события в лог.
+	PublisherLog = "log"
+	// PublisherBroker публикует события в брокер сообщений из секции broker.
+	PublisherBroker = "broker"
+)
+
+type Config struct {
+	Enabled    bool          `json:"enabled" yaml:"enabled" env:"X_OUTBOX_ENABLED"`
+	Publisher  string        `json:"publisher" yaml:"publisher" env:"X_OUTBOX_PUBLISHER"`
+	Topic      string        `json:"topic" yaml:"topic" env:"X_OUTBOX_TOPIC"`
+	Interval   time.Duration `json:"interval" yaml:"interval" env:"X_OUTBOX_INTERVAL"`
+	Batch      int           `json:"batch" yaml:"batch" env:"X_OUTBOX_BATCH"`
+	MaxBackoff time.Duration `json:"max_backoff" yaml:"max_backoff" env:"X_OUTBOX_MAX_BACKOFF"`
+	// ClaimTimeout время, на которое публикуемые события скрываются от других экземпляров.
+	// Если публикация не завершилась за это время, события могут быть опубликованы повторно.
+	ClaimTimeout time.Duration `json:"claim_timeout" yaml:"claim_timeout" env:"X_OUTBOX_CLAIM_TIMEOUT"`
+	// Retention срок хранения опубликованных событий
+	Retention time.Duration `json:"retention" yaml:"retention" env:"X_OUTBOX_RETENTION"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		Publisher:    PublisherLog,
+		Topic:        "events",
+		Interval:     time.Second,
+		Batch:        100,
+		MaxBackoff:   5 * time.Minute,
+		ClaimTimeout: time.Minute,
+		Retention:    7 * 24 * time.Hour,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Publisher, validation.Required, validation.In(PublisherLog, PublisherBroker)),
+		validation.Field(&cfg.Topic, validation.Required.When(cfg.Publisher == PublisherBroker)),
+		validation.Field(&cfg.Interval, validation.Required, validation.Min(10*time.Millisecond)),
+		validation.Field(&cfg.Batch, validation.Required, validation.Min(1)),
+		validation.Field(&cfg.MaxBackoff, validation.Required),
+		validation.Field(&cfg.ClaimTimeout, validation.Required),
+		validation.Field(&cfg.Retention, validation.Required),
+	)
+}
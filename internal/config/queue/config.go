@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"os"
+	"time"
+
+	"service-template/internal/config/valid"
+	"service-template/pkg/queue"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	Enabled       bool          `json:"enabled" yaml:"enabled" env:"X_QUEUE_ENABLED"`
+	Group         string        `json:"group" yaml:"group" env:"X_QUEUE_GROUP"`
+	Consumer      string        `json:"consumer" yaml:"consumer" env:"X_QUEUE_CONSUMER"`
+	Concurrency   int           `json:"concurrency" yaml:"concurrency" env:"X_QUEUE_CONCURRENCY"`
+	MaxRetries    int           `json:"max_retries" yaml:"max_retries" env:"X_QUEUE_MAX_RETRIES"`
+	Backoff       time.Duration `json:"backoff" yaml:"backoff" env:"X_QUEUE_BACKOFF"`
+	MaxBackoff    time.Duration `json:"max_backoff" yaml:"max_backoff" env:"X_QUEUE_MAX_BACKOFF"`
+	ClaimInterval time.Duration `json:"claim_interval" yaml:"claim_interval" env:"X_QUEUE_CLAIM_INTERVAL"`
+	ClaimTimeout  time.Duration `json:"claim_timeout" yaml:"claim_timeout" env:"X_QUEUE_CLAIM_TIMEOUT"`
+	Block         time.Duration `json:"block" yaml:"block" env:"X_QUEUE_BLOCK"`
+	DrainTimeout  time.Duration `json:"drain_timeout" yaml:"drain_timeout" env:"X_QUEUE_DRAIN_TIMEOUT"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+// Имя потребителя по умолчанию совпадает с именем хоста.
+func NewConfig() *Config {
+	hostname, _ := os.Hostname()
+
+	return &Config{
+		Group:         "template",
+		Consumer:      hostname,
+		Concurrency:   10,
+		MaxRetries:    5,
+		Backoff:       time.Second,
+		MaxBackoff:    5 * time.Minute,
+		ClaimInterval: 5 * time.Second,
+		ClaimTimeout:  time.Minute,
+		Block:         5 * time.Second,
+		DrainTimeout:  30 * time.Second,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Group, validation.Required, validation.Match(valid.Name)),
+		validation.Field(&cfg.Consumer, validation.Required, validation.Match(valid.Name)),
+		validation.Field(&cfg.Concurrency, validation.Required, validation.Min(1)),
+		validation.Field(&cfg.MaxRetries, validation.Min(0)),
+		validation.Field(&cfg.Backoff, validation.Required),
+		validation.Field(&cfg.MaxBackoff, validation.Required),
+		validation.Field(&cfg.ClaimInterval, validation.Required),
+		validation.Field(&cfg.ClaimTimeout, validation.Required),
+		validation.Field(&cfg.Block, validation.Required),
+		validation.Field(&cfg.DrainTimeout, validation.Required),
+	)
+}
+
+// Queue возвращает параметры потребителя очереди.
+func (cfg *Config) Queue() queue.Config {
+	return queue.Config{
+		Group:         cfg.Group,
+		Consumer:      cfg.Consumer,
+		Concurrency:   cfg.Concurrency,
+		MaxRetries:    cfg.MaxRetries,
+		Backoff:       cfg.Backoff,
+		MaxBackoff:    cfg.MaxBackoff,
+		ClaimInterval: cfg.ClaimInterval,
+		ClaimTimeout:  cfg.ClaimTimeout,
+		Block:         cfg.Block,
+	}
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"os"
+	"time"
+
+	"service-template/internal/config/valid"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	LockerRedis    = "redis"
+	LockerPostgres = "postgres"
+)
+
+// Disabled значение расписания, отключающее задачу.
+const Disabled = "-"
+
+type Config struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled" env:"X_SCHEDULER_ENABLED"`
+	Locker       string        `json:"locker" yaml:"locker" env:"X_SCHEDULER_LOCKER"`
+	Instance     string        `json:"instance" yaml:"instance" env:"X_SCHEDULER_INSTANCE"`
+	Timeout      time.Duration `json:"timeout" yaml:"timeout" env:"X_SCHEDULER_TIMEOUT"`
+	DrainTimeout time.Duration `json:"drain_timeout" yaml:"drain_timeout" env:"X_SCHEDULER_DRAIN_TIMEOUT"`
+	LockTTL      time.Duration `json:"lock_ttl" yaml:"lock_ttl" env:"X_SCHEDULER_LOCK_TTL"`
+	// Schedules переопределяет расписания задач, заданные в коде: имя задачи -> cron выражение.
+	// Значение "-" отключает задачу. В переменной окружения: "task:spec;task:spec".
+	Schedules map[string]string `json:"schedules" yaml:"schedules" env:"X_SCHEDULER_SCHEDULES" env-separator:";"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+// Имя экземпляра по умолчанию совпадает с именем хоста.
+func NewConfig() *Config {
+	hostname, _ := os.Hostname()
+
+	return &Config{
+		Locker:       LockerRedis,
+		Instance:     hostname,
+		Timeout:      time.Hour,
+		DrainTimeout: 30 * time.Second,
+		LockTTL:      30 * time.Second,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Locker, validation.Required, validation.In(LockerRedis, LockerPostgres)),
+		validation.Field(&cfg.Instance, validation.Required, validation.Match(valid.Name)),
+		validation.Field(&cfg.Timeout, validation.Required, validation.Min(time.Second)),
+		validation.Field(&cfg.DrainTimeout, validation.Required),
+		validation.Field(&cfg.LockTTL, validation.Required, validation.Min(time.Second)),
+	)
+}
@@ -1,8 +1,12 @@
 package server
 
 import (
+	"crypto/tls"
+	"errors"
 	"time"
 
+	"service-template/pkg/certs"
+
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 )
@@ -10,12 +14,40 @@ import (
 type Config struct {
 	Port string `json:"port" yaml:"port" env:"X_SRV_PORT"`
 	Auth Auth   `json:"auth" yaml:"auth"`
+	TLS  TLS    `json:"tls" yaml:"tls"`
+	// ProbesPort порт HTTP-сервера без TLS для проверок живости, готовности и метрик.
+	// Обязателен при client_auth=require: оркестратор и Prometheus не предъявляют клиентский сертификат.
+	ProbesPort string `json:"probes_port" yaml:"probes_port" env:"X_SRV_PROBES_PORT"`
+	// ShutdownTimeout максимальное время остановки сервиса, включая ожидание
+	// выполняемых запросов и фоновых процессов.
+	ShutdownTimeout time.Duration `json:"shutdown_timeout" yaml:"shutdown_timeout" env:"X_SRV_SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay время между снятием готовности и прекращением приема соединений,
+	// за которое балансировщик успевает исключить экземпляр.
+	ShutdownDelay time.Duration `json:"shutdown_delay" yaml:"shutdown_delay" env:"X_SRV_SHUTDOWN_DELAY"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		ShutdownTimeout: 30 * time.Second,
+		TLS: TLS{
+			MinVersion:     "1.2",
+			ClientAuth:     "none",
+			ReloadInterval: time.Minute,
+		},
+	}
 }
 
 func (cfg Config) Validate() error {
 	return validation.ValidateStruct(&cfg,
 		validation.Field(&cfg.Port, validation.Required, is.Port),
 		validation.Field(&cfg.Auth),
+		validation.Field(&cfg.TLS),
+		validation.Field(&cfg.ProbesPort, is.Port, validation.NotIn(cfg.Port).Error("must differ from port"),
+			validation.When(cfg.TLS.Enabled && cfg.TLS.ClientAuth == "require", validation.Required),
+		),
+		validation.Field(&cfg.ShutdownTimeout, validation.Required, validation.Min(time.Second)),
+		validation.Field(&cfg.ShutdownDelay, validation.Min(time.Duration(0)), validation.Max(cfg.ShutdownTimeout)),
 	)
 }
 
@@ -32,3 +64,81 @@ func (auth Auth) Validate() error {
 		validation.Field(&auth.RefreshExpire, validation.Required),
 	)
 }
+
+// TLS настройки TLS HTTP-сервера. Файлы сертификатов перечитываются при изменении.
+type TLS struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled" env:"X_SRV_TLS_ENABLED"`
+	CertFile string `json:"cert_file" yaml:"cert_file" env:"X_SRV_TLS_CERT_FILE"`
+	KeyFile  string `json:"key_file" yaml:"key_file" env:"X_SRV_TLS_KEY_FILE"`
+	// MinVersion минимальная версия протокола: 1.2 или 1.3.
+	MinVersion string `json:"min_version" yaml:"min_version" env:"X_SRV_TLS_MIN_VERSION"`
+	// CipherSuites наборы шифров для TLS 1.2 в именах crypto/tls, по умолчанию выбираются Go.
+	CipherSuites []string `json:"cipher_suites" yaml:"cipher_suites" env:"X_SRV_TLS_CIPHER_SUITES"`
+	// ClientCA корневые сертификаты для проверки клиентских сертификатов.
+	ClientCA string `json:"client_ca" yaml:"client_ca" env:"X_SRV_TLS_CLIENT_CA"`
+	// ClientAuth режим проверки клиентских сертификатов: none, request, verify_if_given или require.
+	ClientAuth string `json:"client_auth" yaml:"client_auth" env:"X_SRV_TLS_CLIENT_AUTH"`
+	// ReloadInterval период проверки изменения файлов сертификатов.
+	ReloadInterval time.Duration `json:"reload_interval" yaml:"reload_interval" env:"X_SRV_TLS_RELOAD_INTERVAL"`
+}
+
+func (t TLS) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.CertFile, validation.Required),
+		validation.Field(&t.KeyFile, validation.Required),
+		validation.Field(&t.MinVersion, validation.Required, validation.By(func(interface{}) error {
+			_, err := certs.ParseVersion(t.MinVersion)
+			return err
+		})),
+		validation.Field(&t.CipherSuites, validation.By(func(interface{}) error {
+			_, err := certs.ParseCipherSuites(t.CipherSuites)
+			return err
+		})),
+		validation.Field(&t.ClientAuth, validation.Required, validation.By(func(interface{}) error {
+			_, err := certs.ParseClientAuth(t.ClientAuth)
+			return err
+		})),
+		validation.Field(&t.ClientCA, validation.By(func(interface{}) error {
+			if t.ClientCA == "" && t.verifyClient() {
+				return errors.New("required to verify client certificates")
+			}
+
+			return nil
+		})),
+		validation.Field(&t.ReloadInterval, validation.Required, validation.Min(time.Second)),
+	)
+}
+
+// Config возвращает базовую TLS-конфигурацию без сертификатов.
+func (t TLS) Config() (*tls.Config, error) {
+	version, err := certs.ParseVersion(t.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var ciphers []uint16
+	if len(t.CipherSuites) > 0 {
+		if ciphers, err = certs.ParseCipherSuites(t.CipherSuites); err != nil {
+			return nil, err
+		}
+	}
+
+	clientAuth, err := certs.ParseClientAuth(t.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:   version,
+		CipherSuites: ciphers,
+		ClientAuth:   clientAuth,
+	}, nil
+}
+
+func (t TLS) verifyClient() bool {
+	return t.ClientAuth == "verify_if_given" || t.ClientAuth == "require"
+}
@@ -0,0 +1,53 @@
+package startup
+
+import (
+	"time"
+
+	"service-template/pkg/retry"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Config параметры ожидания зависимостей при запуске.
+type Config struct {
+	// MaxWait время ожидания доступности Postgres и Redis, 0 — без повторов.
+	MaxWait time.Duration `json:"max_wait" yaml:"max_wait" env:"X_STARTUP_MAX_WAIT"`
+	// InitialInterval задержка перед первым повтором подключения.
+	InitialInterval time.Duration `json:"initial_interval" yaml:"initial_interval" env:"X_STARTUP_INITIAL_INTERVAL"`
+	// MaxInterval максимальная задержка между повторами.
+	MaxInterval time.Duration `json:"max_interval" yaml:"max_interval" env:"X_STARTUP_MAX_INTERVAL"`
+	// Jitter доля случайного отклонения задержки.
+	Jitter float64 `json:"jitter" yaml:"jitter" env:"X_STARTUP_JITTER"`
+	// Migrate применяет миграции при запуске сервиса. Предназначен для локальной
+	// разработки, в остальных окружениях миграции применяются командой db migrate.
+	Migrate bool `json:"migrate" yaml:"migrate" env:"X_STARTUP_MIGRATE"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		MaxWait:         time.Minute,
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     10 * time.Second,
+		Jitter:          0.2,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.MaxWait, validation.Min(time.Duration(0))),
+		validation.Field(&cfg.InitialInterval, validation.When(cfg.MaxWait > 0, validation.Required)),
+		validation.Field(&cfg.MaxInterval, validation.When(cfg.MaxWait > 0, validation.Required, validation.Min(cfg.InitialInterval))),
+		validation.Field(&cfg.Jitter, validation.Min(0.0), validation.Max(1.0)),
+	)
+}
+
+// Retry возвращает параметры повторов подключения.
+func (cfg *Config) Retry() retry.Config {
+	return retry.Config{
+		InitialInterval: cfg.InitialInterval,
+		MaxInterval:     cfg.MaxInterval,
+		MaxWait:         cfg.MaxWait,
+		Jitter:          cfg.Jitter,
+	}
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"service-template/pkg/tracing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	Enabled     bool    `json:"enabled" yaml:"enabled" env:"X_TRACING_ENABLED"`
+	ServiceName string  `json:"service_name" yaml:"service_name" env:"X_TRACING_SERVICE_NAME"`
+	Exporter    string  `json:"exporter" yaml:"exporter" env:"X_TRACING_EXPORTER"`
+	Endpoint    string  `json:"endpoint" yaml:"endpoint" env:"X_TRACING_ENDPOINT"`
+	Insecure    bool    `json:"insecure" yaml:"insecure" env:"X_TRACING_INSECURE"`
+	SampleRatio float64 `json:"sample_ratio" yaml:"sample_ratio" env:"X_TRACING_SAMPLE_RATIO"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		ServiceName: "template",
+		Exporter:    tracing.ExporterOTLP,
+		Endpoint:    "localhost:4318",
+		Insecure:    true,
+		SampleRatio: 1,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.ServiceName, validation.When(cfg.Enabled, validation.Required)),
+		validation.Field(&cfg.Exporter, validation.In(tracing.ExporterOTLP, tracing.ExporterStdout)),
+		validation.Field(&cfg.Endpoint, validation.When(cfg.Enabled && cfg.Exporter == tracing.ExporterOTLP, validation.Required)),
+		validation.Field(&cfg.SampleRatio, validation.Min(0.0), validation.Max(1.0)),
+	)
+}
+
+// Tracing возвращает конфигурацию провайдера трассировки.
+func (cfg *Config) Tracing() tracing.Config {
+	return tracing.Config{
+		ServiceName: cfg.ServiceName,
+		Exporter:    cfg.Exporter,
+		Endpoint:    cfg.Endpoint,
+		Insecure:    cfg.Insecure,
+		SampleRatio: cfg.SampleRatio,
+	}
+}
@@ -0,0 +1,40 @@
+package webhooks
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled" env:"X_WEBHOOKS_ENABLED"`
+	Interval     time.Duration `json:"interval" yaml:"interval" env:"X_WEBHOOKS_INTERVAL"`
+	Batch        int           `json:"batch" yaml:"batch" env:"X_WEBHOOKS_BATCH"`
+	Timeout      time.Duration `json:"timeout" yaml:"timeout" env:"X_WEBHOOKS_TIMEOUT"`
+	MaxAttempts  int           `json:"max_attempts" yaml:"max_attempts" env:"X_WEBHOOKS_MAX_ATTEMPTS"`
+	MaxBackoff   time.Duration `json:"max_backoff" yaml:"max_backoff" env:"X_WEBHOOKS_MAX_BACKOFF"`
+	DisableAfter int           `json:"disable_after" yaml:"disable_after" env:"X_WEBHOOKS_DISABLE_AFTER"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		Interval:     time.Second,
+		Batch:        50,
+		Timeout:      10 * time.Second,
+		MaxAttempts:  8,
+		MaxBackoff:   time.Hour,
+		DisableAfter: 20,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Interval, validation.Required, validation.Min(10*time.Millisecond)),
+		validation.Field(&cfg.Batch, validation.Required, validation.Min(1)),
+		validation.Field(&cfg.Timeout, validation.Required),
+		validation.Field(&cfg.MaxAttempts, validation.Required, validation.Min(1)),
+		validation.Field(&cfg.MaxBackoff, validation.Required),
+		validation.Field(&cfg.DisableAfter, validation.Required, validation.Min(1)),
+	)
+}
@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"service-template/internal/config"
+
+	"github.com/urfave/cli/v2"
+)
+
+// HealthcheckCommand возвращает команду проверки готовности запущенного сервиса.
+// Команда завершается с ненулевым кодом, если сервис не готов, и подходит для HEALTHCHECK контейнера.
+func HealthcheckCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "healthcheck",
+		Usage: "check the running service health",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "Check `URL` instead of the local server",
+			},
+			&cli.BoolFlag{
+				Name:  "live",
+				Usage: "check liveness instead of readiness",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "request timeout",
+				Value: 5 * time.Second,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			client := http.Client{Timeout: c.Duration("timeout")}
+
+			url := c.String("url")
+			if url == "" {
+				if c.String("config") == "" {
+					return errors.New(`healthcheck: required flag "url" or "config" not set`)
+				}
+
+				cfg, err := config.New(c.String("config"))
+				if err != nil {
+					return err
+				}
+
+				path := "/readyz"
+				if c.Bool("live") {
+					path = "/livez"
+				}
+
+				port := cfg.Server.Port
+
+				scheme := "http"
+				switch {
+				case cfg.Server.ProbesPort != "":
+					// Сервер проверок не требует TLS и клиентского сертификата
+					port = cfg.Server.ProbesPort
+				case cfg.Server.TLS.Enabled:
+					scheme = "https"
+
+					// Сертификат выдан на внешнее имя сервиса, а проверяется локальный адрес
+					client.Transport = &http.Transport{
+						TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+					}
+				}
+
+				url = scheme + "://127.0.0.1:" + strings.TrimPrefix(port, ":") + path
+			}
+
+			resp, err := client.Get(url)
+			if err != nil {
+				return fmt.Errorf("healthcheck: %w", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return fmt.Errorf("healthcheck: %w", err)
+			}
+
+			fmt.Println(string(body))
+
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("healthcheck: %s", resp.Status)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,486 @@
+package daemon
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"service-template/internal/config/outbox"
+	"service-template/internal/daemon/services"
+	"service-template/internal/daemon/tasks"
+	"service-template/internal/db"
+	"service-template/internal/delivery"
+	"service-template/internal/relay"
+	"service-template/internal/scheduler"
+	"service-template/internal/worker"
+	"service-template/migrations"
+	"service-template/pkg/certs"
+	"service-template/pkg/health"
+	"service-template/pkg/lifecycle"
+	"service-template/pkg/metrics"
+	"service-template/pkg/migrator"
+	"service-template/pkg/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/uptrace/bun/extra/bunotel"
+)
+
+// Имена компонентов сервиса.
+const (
+	componentTracing   = "tracing"
+	componentStorage   = "storage"
+	componentFeatures  = "features"
+	componentMetrics   = "metrics"
+	componentProbes    = "probes"
+	componentBroker    = "broker"
+	componentOutbox    = "outbox"
+	componentJobs      = "jobs"
+	componentScheduler = "scheduler"
+	componentWebhooks  = "webhooks"
+	componentTLS       = "tls"
+	componentHTTP      = "http"
+)
+
+// tracingFlushTimeout время на отправку накопленных спанов при остановке.
+const tracingFlushTimeout = 5 * time.Second
+
+// register регистрирует компоненты сервиса. Новая подсистема добавляется
+// отдельным компонентом с указанием зависимостей.
+func (d *Daemon) register() {
+	d.lifecycle.Register(d.tracingComponent())
+	d.lifecycle.Register(d.storageComponent())
+	d.lifecycle.Register(d.featuresComponent())
+
+	if d.cfg.Metrics.Enabled && d.cfg.Metrics.Port != "" {
+		d.lifecycle.Register(d.metricsComponent())
+	}
+
+	if d.cfg.Server.ProbesPort != "" {
+		d.lifecycle.Register(d.probesComponent())
+	}
+
+	hasBroker := d.cfg.Queue.Enabled || d.cfg.Outbox.Publisher == outbox.PublisherBroker
+	if hasBroker {
+		d.lifecycle.Register(d.brokerComponent())
+	}
+
+	if d.cfg.Outbox.Enabled {
+		d.lifecycle.Register(d.outboxComponent(hasBroker))
+	}
+
+	if d.cfg.Jobs.Enabled {
+		d.lifecycle.Register(d.jobsComponent())
+	}
+
+	if d.cfg.Scheduler.Enabled {
+		d.lifecycle.Register(d.schedulerComponent())
+	}
+
+	if d.cfg.Webhooks.Enabled {
+		d.lifecycle.Register(d.webhooksComponent())
+	}
+
+	if d.cfg.Server.TLS.Enabled {
+		d.lifecycle.Register(d.tlsComponent())
+	}
+
+	// HTTP-сервер регистрируется последним, чтобы первым прекратить прием запросов
+	d.lifecycle.Register(d.httpComponent())
+}
+
+func (d *Daemon) tracingComponent() lifecycle.Component {
+	var shutdown func(ctx context.Context) error
+
+	return lifecycle.Component{
+		Name: componentTracing,
+		Start: func(ctx context.Context) (err error) {
+			if !d.cfg.Tracing.Enabled {
+				return nil
+			}
+
+			d.log.Info().Str("exporter", d.cfg.Tracing.Exporter).Msg("init tracing")
+			shutdown, err = tracing.Setup(ctx, d.cfg.Tracing.Tracing())
+
+			return err
+		},
+		Stop: func(context.Context) error {
+			if shutdown == nil {
+				return nil
+			}
+
+			// Спаны отправляются, даже если время остановки уже истекло
+			ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+			defer cancel()
+
+			return shutdown(ctx)
+		},
+	}
+}
+
+func (d *Daemon) storageComponent() lifecycle.Component {
+	database := "postgres"
+	if d.cfg.SQLite.Enabled {
+		database = "sqlite"
+	}
+
+	checks := map[string]health.Check{
+		database: func(ctx context.Context) error {
+			return d.storage.DB().PingContext(ctx)
+		},
+		"redis": func(ctx context.Context) error {
+			return d.storage.Redis().Ping(ctx).Err()
+		},
+	}
+
+	if d.cfg.Health.Migrations {
+		checks["migrations"] = func(ctx context.Context) error {
+			ms, err := migrator.Unapplied(ctx, d.storage.DB(), migrations.For(d.cfg.SQLite.Enabled))
+			if err != nil {
+				return err
+			}
+
+			if len(ms) > 0 {
+				return fmt.Errorf("unapplied migrations: %s", ms)
+			}
+
+			return nil
+		}
+	}
+
+	return lifecycle.Component{
+		Name:      componentStorage,
+		DependsOn: []string{componentTracing},
+		Start: func(ctx context.Context) (err error) {
+			if d.storage, err = db.NewStorage(ctx, d.cfg, d.log); err != nil {
+				return err
+			}
+
+			if d.cfg.Startup.Migrate {
+				group, err := migrator.Apply(ctx, d.storage.DB(), migrations.For(d.cfg.SQLite.Enabled))
+				if err != nil {
+					return err
+				}
+
+				if !group.IsZero() {
+					d.log.Info().Msgf("migrated to %s", group)
+				}
+			}
+
+			if d.seed != nil {
+				if err = d.seed(ctx, d.storage); err != nil {
+					return fmt.Errorf("seed: %w", err)
+				}
+			}
+
+			if d.cfg.Tracing.Enabled {
+				name := d.cfg.SQLite.Path
+				if !d.cfg.SQLite.Enabled {
+					// Имя базы данных может быть задано только в DSN
+					pg, err := d.cfg.Postgres.Resolve()
+					if err != nil {
+						return fmt.Errorf("postgres config: %w", err)
+					}
+
+					name = pg.Name
+				}
+
+				for _, db := range d.storage.Databases() {
+					db.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName(name)))
+				}
+
+				d.storage.Redis().AddHook(tracing.RedisHook{})
+			}
+
+			if d.cfg.Metrics.Enabled {
+				hook := metrics.NewQueryHook(d.registry)
+				for i, db := range d.storage.Databases() {
+					db.AddQueryHook(hook)
+
+					name := database
+					if i > 0 {
+						name = fmt.Sprintf("%s_replica_%d", database, i)
+					}
+
+					metrics.RegisterDBStats(d.registry, db.DB, name)
+				}
+
+				d.storage.Redis().AddHook(metrics.NewRedisHook(d.registry))
+				metrics.RegisterRedisPool(d.registry, d.storage.Redis())
+			}
+
+			d.interactor = services.NewInteractor(d.cfg, d.storage, d.registry)
+
+			return nil
+		},
+		Run: func(ctx context.Context) error {
+			// Отстающие и недоступные реплики исключаются из чтения
+			d.storage.Replicas().Run(ctx, d.cfg.Postgres.ReplicaCheckInterval)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			return d.storage.Close()
+		},
+		Checks: checks,
+	}
+}
+
+func (d *Daemon) featuresComponent() lifecycle.Component {
+	return lifecycle.Component{
+		Name:      componentFeatures,
+		DependsOn: []string{componentStorage},
+		Start: func(ctx context.Context) error {
+			return d.interactor.Features.Load(ctx)
+		},
+		Run: func(ctx context.Context) error {
+			d.interactor.Features.Watch(ctx, d.log)
+			return nil
+		},
+	}
+}
+
+func (d *Daemon) metricsComponent() lifecycle.Component {
+	var app *fiber.App
+
+	return lifecycle.Component{
+		Name: componentMetrics,
+		Start: func(context.Context) error {
+			app = fiber.New(fiber.Config{DisableStartupMessage: true})
+			app.Get(d.cfg.Metrics.Path, metrics.Handler(d.registry))
+
+			return nil
+		},
+		Run: func(context.Context) error {
+			d.log.Info().Str("port", d.cfg.Metrics.Port).Msg("start metrics server")
+			return app.Listen(":" + strings.TrimPrefix(d.cfg.Metrics.Port, ":"))
+		},
+		Stop: func(ctx context.Context) error {
+			return app.ShutdownWithContext(ctx)
+		},
+	}
+}
+
+// probesComponent сервер без TLS, который отдает проверки и метрики, если у них нет своего порта.
+// Запускается без зависимостей, чтобы оркестратор видел неготовность сервиса во время запуска.
+func (d *Daemon) probesComponent() lifecycle.Component {
+	var app *fiber.App
+
+	return lifecycle.Component{
+		Name: componentProbes,
+		Start: func(context.Context) error {
+			app = fiber.New(fiber.Config{DisableStartupMessage: true})
+			d.initProbeHandlers(app)
+
+			if d.cfg.Metrics.Enabled && d.cfg.Metrics.Port == "" {
+				app.Get(d.cfg.Metrics.Path, metrics.Handler(d.registry))
+			}
+
+			return nil
+		},
+		Run: func(context.Context) error {
+			d.log.Info().Str("port", d.cfg.Server.ProbesPort).Msg("start probes server")
+			return app.Listen(":" + strings.TrimPrefix(d.cfg.Server.ProbesPort, ":"))
+		},
+		Stop: func(ctx context.Context) error {
+			return app.ShutdownWithContext(ctx)
+		},
+		// Без сервера проверок оркестратор считает сервис неработающим
+		Critical: true,
+	}
+}
+
+func (d *Daemon) brokerComponent() lifecycle.Component {
+	c := lifecycle.Component{
+		Name:      componentBroker,
+		DependsOn: []string{componentStorage},
+		Start: func(context.Context) (err error) {
+			if d.broker, err = d.initBroker(); err != nil {
+				return err
+			}
+
+			if d.cfg.Queue.Enabled {
+				d.initQueueHandlers()
+			}
+
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			return d.broker.Close(ctx)
+		},
+		StopTimeout: d.cfg.Queue.DrainTimeout,
+	}
+
+	// Без очереди брокер только публикует события outbox
+	if d.cfg.Queue.Enabled {
+		c.Run = func(ctx context.Context) error {
+			return d.broker.Run(ctx)
+		}
+	}
+
+	return c
+}
+
+func (d *Daemon) outboxComponent(hasBroker bool) lifecycle.Component {
+	var outboxRelay *relay.Relay
+
+	deps := []string{componentStorage}
+	if hasBroker {
+		deps = append(deps, componentBroker)
+	}
+
+	return lifecycle.Component{
+		Name:      componentOutbox,
+		DependsOn: deps,
+		Start: func(context.Context) error {
+			publisher, err := relay.NewPublisher(d.cfg.Outbox, d.broker, d.log)
+			if err != nil {
+				return err
+			}
+
+			// Вебхуки получают события из outbox вместе с брокером
+			if d.cfg.Webhooks.Enabled {
+				publisher = relay.Multi(publisher, delivery.NewDispatcher(d.storage))
+			}
+
+			d.log.Info().Str("publisher", d.cfg.Outbox.Publisher).Msg("start outbox relay")
+			outboxRelay = relay.NewRelay(d.cfg.Outbox, d.log, d.storage, publisher)
+
+			return nil
+		},
+		Run: func(ctx context.Context) error {
+			outboxRelay.Run(ctx)
+			return nil
+		},
+	}
+}
+
+func (d *Daemon) jobsComponent() lifecycle.Component {
+	return lifecycle.Component{
+		Name:      componentJobs,
+		DependsOn: []string{componentStorage},
+		Start: func(context.Context) error {
+			d.log.Info().Int("concurrency", d.cfg.Jobs.Concurrency).Msg("start jobs worker")
+
+			d.worker = worker.NewPool(d.cfg.Jobs, d.log, d.storage)
+			d.initJobHandlers()
+
+			return nil
+		},
+		Run: func(ctx context.Context) error {
+			return d.worker.Run(ctx)
+		},
+		Stop: func(ctx context.Context) error {
+			return d.worker.Close(ctx)
+		},
+		StopTimeout: d.cfg.Jobs.DrainTimeout,
+	}
+}
+
+func (d *Daemon) schedulerComponent() lifecycle.Component {
+	return lifecycle.Component{
+		Name:      componentScheduler,
+		DependsOn: []string{componentStorage},
+		Start: func(context.Context) error {
+			d.log.Info().Str("locker", d.cfg.Scheduler.Locker).Msg("start scheduler")
+
+			d.scheduler = scheduler.New(d.cfg.Scheduler, d.log, d.storage)
+
+			return d.scheduler.Register(tasks.New(d.cfg, d.log, d.storage)...)
+		},
+		Run: func(ctx context.Context) error {
+			return d.scheduler.Run(ctx)
+		},
+		Stop: func(ctx context.Context) error {
+			return d.scheduler.Close(ctx)
+		},
+		StopTimeout: d.cfg.Scheduler.DrainTimeout,
+	}
+}
+
+func (d *Daemon) webhooksComponent() lifecycle.Component {
+	var sender *delivery.Sender
+
+	return lifecycle.Component{
+		Name:      componentWebhooks,
+		DependsOn: []string{componentStorage},
+		Start: func(context.Context) error {
+			d.log.Info().Msg("start webhooks sender")
+			sender = delivery.NewSender(d.cfg.Webhooks, d.log, d.storage)
+
+			return nil
+		},
+		Run: func(ctx context.Context) error {
+			sender.Run(ctx)
+			return nil
+		},
+	}
+}
+
+func (d *Daemon) tlsComponent() lifecycle.Component {
+	tlsCfg := d.cfg.Server.TLS
+
+	return lifecycle.Component{
+		Name: componentTLS,
+		Start: func(context.Context) (err error) {
+			d.log.Info().Str("cert", tlsCfg.CertFile).Str("client_auth", tlsCfg.ClientAuth).Msg("load TLS certificates")
+			d.certs, err = certs.NewReloader(d.log, tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ClientCA)
+
+			return err
+		},
+		Run: func(ctx context.Context) error {
+			d.certs.Run(ctx, tlsCfg.ReloadInterval)
+			return nil
+		},
+	}
+}
+
+func (d *Daemon) httpComponent() lifecycle.Component {
+	deps := []string{componentStorage, componentFeatures}
+	if d.cfg.Server.TLS.Enabled {
+		deps = append(deps, componentTLS)
+	}
+
+	return lifecycle.Component{
+		Name:      componentHTTP,
+		DependsOn: deps,
+		Start: func(context.Context) error {
+			d.log.Info().Msg("init HTTP server")
+			d.app = d.initServerHTTP()
+
+			d.log.Info().Msg("init HTTP handlers")
+			d.initServerHandlers()
+
+			return nil
+		},
+		Run: func(context.Context) error {
+			addr := ":" + strings.TrimPrefix(d.cfg.Server.Port, ":")
+			if d.certs == nil {
+				return d.app.Listen(addr)
+			}
+
+			return d.listenTLS(addr)
+		},
+		Stop: func(ctx context.Context) error {
+			return d.app.ShutdownWithContext(ctx)
+		},
+		// Сервис не может работать без HTTP-сервера
+		Critical: true,
+	}
+}
+
+// listenTLS принимает TLS-соединения с сертификатами, которые перечитываются при изменении файлов.
+func (d *Daemon) listenTLS(addr string) error {
+	base, err := d.cfg.Server.TLS.Config()
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return d.app.Listener(tls.NewListener(ln, d.certs.Config(base)))
+}
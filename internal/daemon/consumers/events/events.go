@@ -0,0 +1,34 @@
+package events
+
+import (
+	"context"
+
+	"service-template/internal/daemon/services"
+	"service-template/internal/events"
+
+	"github.com/rs/zerolog"
+)
+
+// Handler обработчик доменных событий, полученных из очереди.
+type Handler struct {
+	log        *zerolog.Logger
+	interactor *services.Interactor
+}
+
+func NewHandler(log *zerolog.Logger, interactor *services.Interactor) *Handler {
+	return &Handler{
+		log:        log,
+		interactor: interactor,
+	}
+}
+
+// Event Обработчик доменных событий из outbox.
+func (h *Handler) Event(ctx context.Context, event events.Event) error {
+	h.log.Debug().
+		Str("event_id", event.ID).
+		Str("type", string(event.Type)).
+		Str("aggregate", event.AggregateType+":"+event.AggregateID).
+		Msg("event received")
+
+	return nil
+}
@@ -3,29 +3,64 @@ package daemon
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
-	"strings"
+	"runtime/debug"
 	"syscall"
+	"time"
 
 	"service-template/internal/config"
+	brokercfg "service-template/internal/config/broker"
+	"service-template/internal/daemon/consumers/events"
 	"service-template/internal/daemon/handlers/auth"
+	"service-template/internal/daemon/handlers/features"
+	"service-template/internal/daemon/handlers/probes"
+	"service-template/internal/daemon/handlers/settings"
+	"service-template/internal/daemon/handlers/users"
+	"service-template/internal/daemon/handlers/webhooks"
+	"service-template/internal/daemon/jobs"
+	"service-template/internal/daemon/middleware"
 	"service-template/internal/daemon/services"
 	"service-template/internal/db"
+	"service-template/internal/model"
+	"service-template/internal/scheduler"
+	"service-template/internal/worker"
+	"service-template/pkg/broker"
+	"service-template/pkg/certs"
+	"service-template/pkg/health"
+	"service-template/pkg/lifecycle"
+	"service-template/pkg/metrics"
+	"service-template/pkg/problem"
+	"service-template/pkg/tracing"
 
 	"github.com/gofiber/contrib/fiberzerolog"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
-	"golang.org/x/exp/slices"
 )
 
 type Daemon struct {
-	log     *zerolog.Logger
-	cfg     *config.Config
-	app     *fiber.App
-	storage *db.Storage
+	log        *zerolog.Logger
+	cfg        *config.Config
+	app        *fiber.App
+	storage    *db.Storage
+	interactor *services.Interactor
+	broker     broker.Broker
+	worker     *worker.Pool
+	scheduler  *scheduler.Scheduler
+	health     *health.Health
+	registry   *prometheus.Registry
+	certs      *certs.Reloader
+	lifecycle  *lifecycle.Manager
+	seed       SeedFunc
 }
 
+// SeedFunc заполняет хранилище данными после подключения к нему.
+type SeedFunc func(ctx context.Context, storage *db.Storage) error
+
 // New create new daemon instance.
 func New(log *zerolog.Logger, cfg *config.Config) *Daemon {
 	return &Daemon{
@@ -34,122 +69,196 @@ func New(log *zerolog.Logger, cfg *config.Config) *Daemon {
 	}
 }
 
+// Seed задает функцию, которая выполняется после подключения к хранилищу
+// и применения миграций, до запуска остальных компонентов.
+func (d *Daemon) Seed(fn SeedFunc) {
+	d.seed = fn
+}
+
 func (d *Daemon) Run() error {
-	var err error
 	d.log.Info().Msg("daemon starting")
 
-	d.log.Info().Msg("init storages")
-	if d.storage, err = db.NewStorage(d.cfg, d.log); err != nil {
-		return err
-	}
-
-	d.log.Info().Msg("init HTTP server")
-	d.app = d.initServerHTTP()
-
-	d.log.Info().Msg("init HTTP handlers")
-	d.initServerHandlers()
+	d.health = health.New(d.cfg.Health.Timeout)
+	d.registry = metrics.NewRegistry()
+	d.lifecycle = lifecycle.New(d.log, d.health)
+	d.register()
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill, syscall.SIGTERM)
 	defer stop()
 
-	d.log.Info().Msg("init message queue")
+	if err := d.lifecycle.Start(ctx); err != nil {
+		return errors.Join(err, d.Close())
+	}
 
-	go func() {
-		for {
-			select {
-			//case errors = <-d.http.Errors():
-			//	d.log.Error().Err(errors)
+	select {
+	case err := <-d.lifecycle.Failed():
+		// Критичный компонент остановился не по сигналу, например, порт уже занят
+		stop()
 
-			case <-ctx.Done():
-				stop()
+		return errors.Join(err, d.Close())
 
-				if err = d.Close(); err != nil {
-					d.log.Error().Err(err)
-				}
+	case <-ctx.Done():
+		stop()
+		d.log.Info().Msg("shutdown signal received")
 
-				return
-			}
-		}
-	}()
-
-	if !strings.HasPrefix(d.cfg.Server.Port, ":") {
-		d.cfg.Server.Port = ":" + d.cfg.Server.Port
+		return d.Close()
 	}
-
-	return d.app.Listen(d.cfg.Server.Port)
 }
 
-// Close закрывает соединения с БД, и глушит сервер.
+// Close останавливает сервис: снимает готовность и останавливает компоненты
+// в порядке, обратном запуску. Вся остановка ограничена временем server.shutdown_timeout.
 func (d *Daemon) Close() error {
-	var errs []error
+	if d.lifecycle == nil {
+		return nil
+	}
 
-	if d.storage != nil {
-		d.log.Info().Msg("close storage")
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Server.ShutdownTimeout)
+	defer cancel()
 
-		if err := d.storage.Close(); err != nil {
-			errs = append(errs, err)
-		}
-	}
+	// Сервис перестает быть готовым до остановки компонентов,
+	// чтобы балансировщик успел исключить его из обработки запросов
+	d.health.Shutdown()
 
-	if d.app != nil {
-		d.log.Info().Msg("HTTP server shutdown")
+	if d.cfg.Server.ShutdownDelay > 0 {
+		d.log.Info().Dur("delay", d.cfg.Server.ShutdownDelay).Msg("wait for readiness propagation")
 
-		if err := d.app.Shutdown(); errs != nil {
-			errs = append(errs, err)
+		select {
+		case <-time.After(d.cfg.Server.ShutdownDelay):
+		case <-ctx.Done():
 		}
 	}
 
-	return errors.Join(errs...)
+	return d.lifecycle.Stop(ctx)
 }
 
 func (d *Daemon) initServerHTTP() *fiber.App {
 	app := fiber.New(fiber.Config{
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
-			code := fiber.StatusInternalServerError
-
-			var e *fiber.Error
-			if errors.As(err, &e) {
-				code = e.Code
-			}
-
-			// Показываем пользователю ошибку, только если она в списке разрешенных
-			var externalErrors = []int{fiber.StatusBadRequest}
-			if slices.Contains(externalErrors, code) {
-				return c.Status(code).SendString(err.Error())
-			}
-
-			return c.SendStatus(code)
-		},
+		// Ошибки отдаются в формате application/problem+json
+		ErrorHandler: problem.Handler(d.log),
 	})
 
+	app.Use(requestid.New())
+
+	if d.cfg.Server.TLS.Enabled {
+		app.Use(middleware.ClientCert())
+	}
+
+	if d.cfg.Metrics.Enabled {
+		app.Use(metrics.NewHTTP(d.registry).Middleware())
+	}
+
+	if d.cfg.Tracing.Enabled {
+		app.Use(tracing.Middleware())
+	}
+
 	// логирование запросов
 	app.Use(fiberzerolog.New(fiberzerolog.Config{
 		Logger:   d.log,
-		Fields:   []string{fiberzerolog.FieldStatus, fiberzerolog.FieldMethod, fiberzerolog.FieldURL, fiberzerolog.FieldError},
+		Fields:   []string{fiberzerolog.FieldRequestID, fiberzerolog.FieldStatus, fiberzerolog.FieldMethod, fiberzerolog.FieldURL, fiberzerolog.FieldError},
 		Messages: []string{"Server", "Client", "Success"},
 	}))
 
+	// Паника обработчика превращается в ответ 500 и не останавливает сервис.
+	// Подключается последней, чтобы запрос учли логирование, метрики и трассировка.
+	app.Use(recover.New(recover.Config{
+		EnableStackTrace: true,
+		StackTraceHandler: func(c *fiber.Ctx, e interface{}) {
+			d.log.Error().
+				Str("request_id", c.GetRespHeader(fiber.HeaderXRequestID)).
+				Interface("panic", e).
+				Bytes("stack", debug.Stack()).
+				Msg("request panic")
+		},
+	}))
+
 	return app
 }
 
+// initProbeHandlers регистрирует проверки живости и готовности для оркестратора.
+func (d *Daemon) initProbeHandlers(router fiber.Router) {
+	probesHandler := probes.NewHandler(d.log, d.health, d.lifecycle)
+
+	router.Get("/livez", probesHandler.Livez)
+	router.Get("/readyz", probesHandler.Readyz)
+}
+
 func (d *Daemon) initServerHandlers() {
-	interactor := services.NewInteractor(d.cfg, d.storage)
+	interactor := d.interactor
 
 	authHandler := auth.NewHandler(d.log, interactor)
+	usersHandler := users.NewHandler(d.log, interactor)
+	settingsHandler := settings.NewHandler(d.log, interactor)
+	webhooksHandler := webhooks.NewHandler(d.log, interactor)
+	featuresHandler := features.NewHandler(d.log, interactor)
+
+	d.initProbeHandlers(d.app)
+
+	// Метрики без своего порта отдаются сервером проверок, если он есть
+	if d.cfg.Metrics.Enabled && d.cfg.Metrics.Port == "" && d.cfg.Server.ProbesPort == "" {
+		d.app.Get(d.cfg.Metrics.Path, metrics.Handler(d.registry))
+	}
 
 	// Группа обработчиков, которые доступны неавторизованным пользователям
 	publicGroup := d.app.Group("")
 	publicGroup.Post("/signup", authHandler.SignUp)
 	publicGroup.Post("/signin", authHandler.SignIn)
-	//publicGroup.Get("/", authHandler.Root)
-	//
-	//// Группа обработчиков, которые требуют авторизации
-	//authorizedGroup := app.Group("")
-	//authorizedGroup.Use(jwtware.New(jwtware.Config{
-	//	SigningKey: handlers.SecretKey,
-	//	ContextKey: handlers.ContextKeyUser,
-	//}))
-	//authorizedGroup.Get("/profile", userHandler.Profile)
+	publicGroup.Post("/password", authHandler.ChangePassword)
+
+	authMiddleware := middleware.Auth(d.cfg.Server.Auth.TokenSecret)
+
+	// Группа обработчиков, которые требуют авторизации
+	meGroup := d.app.Group("/me", authMiddleware)
+	meGroup.Get("", usersHandler.Me)
+	meGroup.Get("/settings", settingsHandler.Get)
+	meGroup.Patch("/settings", settingsHandler.Update)
+	meGroup.Get("/features", featuresHandler.Me)
+
+	// Группа обработчиков, которые доступны только администраторам
+	adminGroup := d.app.Group("/admin", authMiddleware, middleware.Roles(model.RoleAdmin))
+	adminGroup.Get("/users/:id", usersHandler.Get)
+	adminGroup.Patch("/users/:id", usersHandler.Update)
+	adminGroup.Put("/users/:id/password", usersHandler.SetPassword)
+	adminGroup.Get("/users/:id/profile", usersHandler.GetProfile)
+	adminGroup.Put("/users/:id/profile", usersHandler.UpdateProfile)
+	adminGroup.Get("/webhooks", webhooksHandler.List)
+	adminGroup.Post("/webhooks", webhooksHandler.Create)
+	adminGroup.Get("/webhooks/:id", webhooksHandler.Get)
+	adminGroup.Patch("/webhooks/:id", webhooksHandler.Update)
+	adminGroup.Delete("/webhooks/:id", webhooksHandler.Delete)
+	adminGroup.Get("/webhooks/:id/deliveries", webhooksHandler.Deliveries)
+	adminGroup.Post("/webhooks/:id/deliveries/:delivery/redeliver", webhooksHandler.Redeliver)
+	adminGroup.Get("/features", featuresHandler.List)
+	adminGroup.Post("/features", featuresHandler.Create)
+	adminGroup.Get("/features/:key", featuresHandler.Get)
+	adminGroup.Patch("/features/:key", featuresHandler.Update)
+	adminGroup.Delete("/features/:key", featuresHandler.Delete)
+}
+
+// initBroker создает брокер сообщений для драйвера, указанного в конфигурации.
+func (d *Daemon) initBroker() (broker.Broker, error) {
+	d.log.Info().Str("driver", d.cfg.Broker.Driver).Msg("init message broker")
+
+	switch d.cfg.Broker.Driver {
+	case brokercfg.DriverMemory:
+		return broker.NewMemory(d.cfg.Broker.Memory(d.cfg.Queue)), nil
+	case brokercfg.DriverRedis:
+		return broker.NewRedis(d.storage.Redis(), d.cfg.Queue.Queue(), d.log), nil
+	case brokercfg.DriverNATS:
+		return broker.NewNATS(d.cfg.Broker.NATS(d.cfg.Queue), d.log)
+	}
+
+	return nil, fmt.Errorf("unknown broker driver %q", d.cfg.Broker.Driver)
+}
+
+func (d *Daemon) initQueueHandlers() {
+	eventsHandler := events.NewHandler(d.log, d.interactor)
+
+	// Доменные события, опубликованные outbox
+	broker.SubscribeJSON(d.broker, d.cfg.Outbox.Topic, eventsHandler.Event)
+}
+
+func (d *Daemon) initJobHandlers() {
+	usersJobs := jobs.NewUsers(d.log, d.interactor)
 
+	worker.HandleJSON(d.worker, jobs.KindPurgeDeletedUsers, usersJobs.PurgeDeleted)
 }
@@ -1,11 +1,10 @@
 package auth
 
 import (
-	"errors"
-	"fmt"
+	"service-template/internal/daemon/middleware"
 	"service-template/internal/daemon/services"
-	"service-template/internal/daemon/services/auth"
 	"service-template/internal/daemon/services/auth/request"
+	"service-template/pkg/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -25,24 +24,20 @@ func NewHandler(log *zerolog.Logger, interactor *services.Interactor) *Handler {
 
 // SignUp Обработчик HTTP-запросов на вход в аккаунт пользователя.
 func (h *Handler) SignUp(c *fiber.Ctx) error {
-	ctx := h.log.WithContext(c.Context())
+	ctx := middleware.Context(c, h.log)
 
 	signup := request.SignUp{}
 	if err := c.BodyParser(&signup); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Errorf("body parser: %w", err).Error())
+		return problem.Malformed(err)
 	}
 
 	if err := signup.Validate(); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		return problem.Invalid(err)
 	}
 
 	response, err := h.interactor.Auth.SignUp(ctx, &signup)
 	if err != nil {
-		if errors.Is(err, auth.ErrUserAlreadyExists) {
-			return fiber.NewError(fiber.StatusBadRequest, err.Error())
-		}
-
-		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(response)
@@ -50,25 +45,41 @@ func (h *Handler) SignUp(c *fiber.Ctx) error {
 
 // SignIn Обработчик HTTP-запросов на вход в аккаунт пользователя.
 func (h *Handler) SignIn(c *fiber.Ctx) error {
-	ctx := h.log.WithContext(c.Context())
+	ctx := middleware.Context(c, h.log)
 
 	signin := request.SignIn{}
 	if err := c.BodyParser(&signin); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Errorf("body parser: %w", err).Error())
+		return problem.Malformed(err)
 	}
 
 	if err := signin.Validate(); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		return problem.Invalid(err)
 	}
 
 	response, err := h.interactor.Auth.SignIn(ctx, &signin)
 	if err != nil {
-		if errors.Is(err, auth.ErrWrongUsernameOrPassword) {
-			return fiber.NewError(fiber.StatusBadRequest, err.Error())
-		}
-
-		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(response)
 }
+
+// ChangePassword Обработчик HTTP-запросов на смену пароля пользователем.
+func (h *Handler) ChangePassword(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	change := request.ChangePassword{}
+	if err := c.BodyParser(&change); err != nil {
+		return problem.Malformed(err)
+	}
+
+	if err := change.Validate(); err != nil {
+		return problem.Invalid(err)
+	}
+
+	if err := h.interactor.Auth.ChangePassword(ctx, &change); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,129 @@
+package features
+
+import (
+	"errors"
+
+	"service-template/internal/daemon/middleware"
+	"service-template/internal/daemon/services"
+	"service-template/internal/daemon/services/features"
+	"service-template/internal/daemon/services/features/request"
+	"service-template/pkg/etag"
+	"service-template/pkg/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	log        *zerolog.Logger
+	interactor *services.Interactor
+}
+
+func NewHandler(log *zerolog.Logger, interactor *services.Interactor) *Handler {
+	return &Handler{
+		log:        log,
+		interactor: interactor,
+	}
+}
+
+// Me Обработчик HTTP-запросов на получение значений флагов для текущего пользователя.
+func (h *Handler) Me(c *fiber.Ctx) error {
+	return c.JSON(h.interactor.Features.Evaluate(middleware.Subject(c)))
+}
+
+// Create Обработчик HTTP-запросов на создание флага.
+func (h *Handler) Create(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	create := request.CreateFlag{}
+	if err := c.BodyParser(&create); err != nil {
+		return problem.Malformed(err)
+	}
+
+	if err := create.Validate(); err != nil {
+		return problem.Invalid(err)
+	}
+
+	response, err := h.interactor.Features.Create(ctx, &create)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(response.Version))
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// List Обработчик HTTP-запросов на получение списка флагов.
+func (h *Handler) List(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	response, err := h.interactor.Features.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+// Get Обработчик HTTP-запросов на получение флага.
+func (h *Handler) Get(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	response, err := h.interactor.Features.Get(ctx, c.Params("key"))
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(response.Version))
+
+	return c.JSON(response)
+}
+
+// Update Обработчик HTTP-запросов на изменение флага.
+func (h *Handler) Update(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	version, err := etag.Parse(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	update := request.UpdateFlag{}
+	if err = c.BodyParser(&update); err != nil {
+		return problem.Malformed(err)
+	}
+
+	if err = update.Validate(); err != nil {
+		return problem.Invalid(err)
+	}
+
+	response, err := h.interactor.Features.Update(ctx, c.Params("key"), &update, version)
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(response.Version))
+
+	return c.JSON(response)
+}
+
+// Delete Обработчик HTTP-запросов на удаление флага.
+func (h *Handler) Delete(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	if err := h.interactor.Features.Delete(ctx, c.Params("key")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// error отвечает 412 вместо 409 при конфликте версий, если клиент явно указал ожидаемую версию флага.
+func (h *Handler) error(c *fiber.Ctx, err error) error {
+	if errors.Is(err, features.ErrVersionConflict) && c.Get(fiber.HeaderIfMatch) != "" {
+		return features.ErrVersionConflict.WithStatus(fiber.StatusPreconditionFailed)
+	}
+
+	return err
+}
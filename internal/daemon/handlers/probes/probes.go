@@ -0,0 +1,57 @@
+package probes
+
+import (
+	"service-template/pkg/health"
+	"service-template/pkg/lifecycle"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	log       *zerolog.Logger
+	health    *health.Health
+	lifecycle *lifecycle.Manager
+}
+
+// Ready ответ проверки готовности, дополненный состоянием компонентов сервиса.
+type Ready struct {
+	health.Report
+	Components []lifecycle.Status `json:"components"`
+}
+
+func NewHandler(log *zerolog.Logger, health *health.Health, lifecycle *lifecycle.Manager) *Handler {
+	return &Handler{
+		log:       log,
+		health:    health,
+		lifecycle: lifecycle,
+	}
+}
+
+// Livez Обработчик HTTP-запросов проверки живости сервиса.
+func (h *Handler) Livez(c *fiber.Ctx) error {
+	report := h.health.Liveness(c.Context())
+
+	return h.send(c, report, report)
+}
+
+// Readyz Обработчик HTTP-запросов проверки готовности сервиса принимать запросы.
+// В ответе также перечислены компоненты сервиса и их состояние.
+func (h *Handler) Readyz(c *fiber.Ctx) error {
+	report := h.health.Readiness(c.Context())
+
+	return h.send(c, report, Ready{Report: report, Components: h.lifecycle.Status()})
+}
+
+// send отдает body со статусом 503, если проверки report не пройдены.
+func (h *Handler) send(c *fiber.Ctx, report health.Report, body any) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	if !report.Up() {
+		h.log.Warn().Interface("checks", report.Checks).Str("path", c.Path()).Msg("health check failed")
+
+		return c.Status(fiber.StatusServiceUnavailable).JSON(body)
+	}
+
+	return c.JSON(body)
+}
@@ -0,0 +1,52 @@
+package probes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"service-template/pkg/health"
+	"service-template/pkg/lifecycle"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Readyz(t *testing.T) {
+	log := zerolog.Nop()
+	h := health.New(time.Second)
+	m := lifecycle.New(&log, h)
+
+	m.Register(lifecycle.Component{Name: "storage", Checks: map[string]health.Check{
+		"postgres": func(context.Context) error {
+			return errors.New("connection refused")
+		},
+	}})
+	require.NoError(t, m.Start(context.Background()))
+	defer func() { _ = m.Stop(context.Background()) }()
+
+	handler := NewHandler(&log, h, m)
+
+	app := fiber.New()
+	app.Get("/livez", handler.Livez)
+	app.Get("/readyz", handler.Readyz)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/readyz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+	var ready Ready
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ready))
+	assert.Equal(t, health.StatusDown, ready.Status)
+	assert.Equal(t, "connection refused", ready.Checks["postgres"].Error)
+	assert.Equal(t, []lifecycle.Status{{Name: "storage", State: lifecycle.StateRunning}}, ready.Components)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/livez", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
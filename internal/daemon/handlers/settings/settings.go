@@ -0,0 +1,82 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"service-template/internal/daemon/middleware"
+	"service-template/internal/daemon/services"
+	"service-template/internal/daemon/services/settings"
+	"service-template/pkg/etag"
+	"service-template/pkg/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+// MIMEApplicationMergePatchJSON тип содержимого JSON Merge Patch (RFC 7396).
+const MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+
+type Handler struct {
+	log        *zerolog.Logger
+	interactor *services.Interactor
+}
+
+func NewHandler(log *zerolog.Logger, interactor *services.Interactor) *Handler {
+	return &Handler{
+		log:        log,
+		interactor: interactor,
+	}
+}
+
+// Get Обработчик HTTP-запросов на получение настроек текущего пользователя.
+func (h *Handler) Get(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	response, version, err := h.interactor.Settings.Get(ctx, middleware.Subject(c).ID)
+	if err != nil {
+		return err
+	}
+
+	// У несохраненных настроек версии еще нет
+	if version != 0 {
+		c.Set(fiber.HeaderETag, etag.Format(version))
+	}
+
+	return c.JSON(response)
+}
+
+// Update Обработчик HTTP-запросов на изменение настроек текущего пользователя.
+func (h *Handler) Update(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	ctype := strings.ToLower(c.Get(fiber.HeaderContentType))
+	if !strings.HasPrefix(ctype, MIMEApplicationMergePatchJSON) && !strings.HasPrefix(ctype, fiber.MIMEApplicationJSON) {
+		return fiber.NewError(fiber.StatusUnsupportedMediaType)
+	}
+
+	version, err := etag.Parse(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	patch := map[string]interface{}{}
+	if err = json.Unmarshal(c.Body(), &patch); err != nil {
+		return problem.Malformed(err)
+	}
+
+	response, version, err := h.interactor.Settings.Update(ctx, middleware.Subject(c).ID, patch, version)
+	if err != nil {
+		// Клиент явно указал ожидаемую версию настроек
+		if errors.Is(err, settings.ErrVersionConflict) && c.Get(fiber.HeaderIfMatch) != "" {
+			return settings.ErrVersionConflict.WithStatus(fiber.StatusPreconditionFailed)
+		}
+
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(version))
+
+	return c.JSON(response)
+}
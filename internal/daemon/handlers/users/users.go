@@ -0,0 +1,187 @@
+package users
+
+import (
+	"errors"
+
+	"service-template/internal/daemon/middleware"
+	"service-template/internal/daemon/services"
+	"service-template/internal/daemon/services/users"
+	"service-template/internal/daemon/services/users/request"
+	"service-template/pkg/etag"
+	"service-template/pkg/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	log        *zerolog.Logger
+	interactor *services.Interactor
+}
+
+func NewHandler(log *zerolog.Logger, interactor *services.Interactor) *Handler {
+	return &Handler{
+		log:        log,
+		interactor: interactor,
+	}
+}
+
+// Me Обработчик HTTP-запросов на получение текущего пользователя.
+func (h *Handler) Me(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	response, err := h.interactor.Users.Get(ctx, middleware.Subject(c).ID)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(response.Version))
+
+	return c.JSON(response)
+}
+
+// Get Обработчик HTTP-запросов на получение пользователя.
+func (h *Handler) Get(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user id")
+	}
+
+	response, err := h.interactor.Users.Get(ctx, uint64(id))
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(response.Version))
+
+	return c.JSON(response)
+}
+
+// Update Обработчик HTTP-запросов на частичное обновление пользователя.
+func (h *Handler) Update(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user id")
+	}
+
+	version, err := etag.Parse(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	update := request.UpdateUser{}
+	if err = c.BodyParser(&update); err != nil {
+		return problem.Malformed(err)
+	}
+
+	if err = update.Validate(); err != nil {
+		return problem.Invalid(err)
+	}
+
+	response, err := h.interactor.Users.Update(ctx, uint64(id), &update, version)
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(response.Version))
+
+	return c.JSON(response)
+}
+
+// SetPassword Обработчик HTTP-запросов на установку пароля пользователя.
+func (h *Handler) SetPassword(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user id")
+	}
+
+	version, err := etag.Parse(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	in := request.SetPassword{}
+	if err = c.BodyParser(&in); err != nil {
+		return problem.Malformed(err)
+	}
+
+	if err = in.Validate(); err != nil {
+		return problem.Invalid(err)
+	}
+
+	response, err := h.interactor.Users.SetPassword(ctx, uint64(id), &in, version)
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(response.Version))
+
+	return c.JSON(response)
+}
+
+// GetProfile Обработчик HTTP-запросов на получение профиля пользователя.
+func (h *Handler) GetProfile(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user id")
+	}
+
+	response, err := h.interactor.Users.GetProfile(ctx, uint64(id))
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(response.Version))
+
+	return c.JSON(response)
+}
+
+// UpdateProfile Обработчик HTTP-запросов на замену профиля пользователя.
+func (h *Handler) UpdateProfile(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user id")
+	}
+
+	version, err := etag.Parse(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	profile := request.Profile{}
+	if err = c.BodyParser(&profile); err != nil {
+		return problem.Malformed(err)
+	}
+
+	if err = profile.Validate(); err != nil {
+		return problem.Invalid(err)
+	}
+
+	response, err := h.interactor.Users.UpdateProfile(ctx, uint64(id), &profile, version)
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(response.Version))
+
+	return c.JSON(response)
+}
+
+// error отвечает 412 вместо 409 при конфликте версий, если клиент явно указал ожидаемую версию ресурса.
+func (h *Handler) error(c *fiber.Ctx, err error) error {
+	if errors.Is(err, users.ErrVersionConflict) && c.Get(fiber.HeaderIfMatch) != "" {
+		return users.ErrVersionConflict.WithStatus(fiber.StatusPreconditionFailed)
+	}
+
+	return err
+}
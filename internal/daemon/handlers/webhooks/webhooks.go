@@ -0,0 +1,154 @@
+package webhooks
+
+import (
+	"service-template/internal/daemon/middleware"
+	"service-template/internal/daemon/services"
+	"service-template/internal/daemon/services/webhooks/request"
+	"service-template/pkg/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	log        *zerolog.Logger
+	interactor *services.Interactor
+}
+
+func NewHandler(log *zerolog.Logger, interactor *services.Interactor) *Handler {
+	return &Handler{
+		log:        log,
+		interactor: interactor,
+	}
+}
+
+// Create Обработчик HTTP-запросов на создание подписки.
+func (h *Handler) Create(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	create := request.CreateWebhook{}
+	if err := c.BodyParser(&create); err != nil {
+		return problem.Malformed(err)
+	}
+
+	if err := create.Validate(); err != nil {
+		return problem.Invalid(err)
+	}
+
+	response, err := h.interactor.Webhooks.Create(ctx, &create)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// List Обработчик HTTP-запросов на получение списка подписок.
+func (h *Handler) List(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	response, err := h.interactor.Webhooks.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+// Get Обработчик HTTP-запросов на получение подписки.
+func (h *Handler) Get(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid webhook id")
+	}
+
+	response, err := h.interactor.Webhooks.Get(ctx, uint64(id))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+// Update Обработчик HTTP-запросов на изменение подписки.
+func (h *Handler) Update(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid webhook id")
+	}
+
+	update := request.UpdateWebhook{}
+	if err = c.BodyParser(&update); err != nil {
+		return problem.Malformed(err)
+	}
+
+	if err = update.Validate(); err != nil {
+		return problem.Invalid(err)
+	}
+
+	response, err := h.interactor.Webhooks.Update(ctx, uint64(id), &update)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+// Delete Обработчик HTTP-запросов на удаление подписки.
+func (h *Handler) Delete(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid webhook id")
+	}
+
+	if err = h.interactor.Webhooks.Delete(ctx, uint64(id)); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Deliveries Обработчик HTTP-запросов на получение журнала доставок подписки.
+func (h *Handler) Deliveries(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid webhook id")
+	}
+
+	response, err := h.interactor.Webhooks.Deliveries(ctx, uint64(id))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+// Redeliver Обработчик HTTP-запросов на повторную доставку события.
+func (h *Handler) Redeliver(c *fiber.Ctx) error {
+	ctx := middleware.Context(c, h.log)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid webhook id")
+	}
+
+	deliveryID, err := c.ParamsInt("delivery")
+	if err != nil || deliveryID <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid delivery id")
+	}
+
+	response, err := h.interactor.Webhooks.Redeliver(ctx, uint64(id), uint64(deliveryID))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(response)
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration длительность в данных задачи. Сохраняется строкой в формате time.Duration,
+// например "720h0m0s", чтобы данные задачи были читаемыми в списке задач.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON принимает строку в формате time.Duration.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+
+	value, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(value)
+
+	return nil
+}
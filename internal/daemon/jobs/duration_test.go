@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuration(t *testing.T) {
+	data, err := json.Marshal(PurgeDeletedUsers{Retention: Duration(30 * 24 * time.Hour)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"retention":"720h0m0s"}`, string(data))
+
+	var payload PurgeDeletedUsers
+	require.NoError(t, json.Unmarshal(data, &payload))
+	assert.Equal(t, 30*24*time.Hour, time.Duration(payload.Retention))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"retention":"month"}`), &payload))
+	assert.Error(t, json.Unmarshal([]byte(`{"retention":true}`), &payload))
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"service-template/internal/daemon/services"
+
+	"github.com/rs/zerolog"
+)
+
+// KindPurgeDeletedUsers безвозвратное удаление пользователей, удаленных раньше срока хранения.
+const KindPurgeDeletedUsers = "users.purge_deleted"
+
+// PurgeDeletedUsers данные задачи KindPurgeDeletedUsers.
+type PurgeDeletedUsers struct {
+	Retention Duration `json:"retention"`
+}
+
+// Users обработчик фоновых задач пользователей.
+type Users struct {
+	log        *zerolog.Logger
+	interactor *services.Interactor
+}
+
+func NewUsers(log *zerolog.Logger, interactor *services.Interactor) *Users {
+	return &Users{
+		log:        log,
+		interactor: interactor,
+	}
+}
+
+// PurgeDeleted Обработчик задачи KindPurgeDeletedUsers.
+func (h *Users) PurgeDeleted(ctx context.Context, payload PurgeDeletedUsers) error {
+	n, err := h.interactor.Users.PurgeDeleted(ctx, time.Now().Add(-time.Duration(payload.Retention)))
+	if err != nil {
+		return err
+	}
+
+	h.log.Info().Int("count", n).Msg("deleted users purged")
+
+	return nil
+}
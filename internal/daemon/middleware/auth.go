@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"service-template/internal/db/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/exp/slices"
+)
+
+// SubjectKey ключ, по которому данные авторизованного пользователя хранятся в контексте запроса.
+const SubjectKey = "subject"
+
+// Auth проверяет JWT-токен из заголовка Authorization и сохраняет данные пользователя в контексте.
+// Пользователь не перечитывается из хранилища, поэтому блокировка и смена ролей
+// вступают в силу после истечения токена.
+func Auth(secret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if !strings.HasPrefix(header, "Bearer ") {
+			return fiber.NewError(fiber.StatusUnauthorized, "missing bearer token")
+		}
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(strings.TrimPrefix(header, "Bearer "), claims, func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+			}
+
+			return []byte(secret), nil
+		})
+		if err != nil {
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+
+		c.Locals(SubjectKey, subjectFromClaims(claims))
+
+		return c.Next()
+	}
+}
+
+// Roles пропускает запрос, только если у пользователя есть одна из ролей.
+func Roles(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		subject := Subject(c)
+		if subject == nil {
+			return fiber.NewError(fiber.StatusUnauthorized)
+		}
+
+		for _, role := range roles {
+			if slices.Contains(subject.Roles, role) {
+				return c.Next()
+			}
+		}
+
+		return fiber.NewError(fiber.StatusForbidden)
+	}
+}
+
+// Subject возвращает данные авторизованного пользователя.
+func Subject(c *fiber.Ctx) *token.Subject {
+	subject, _ := c.Locals(SubjectKey).(*token.Subject)
+
+	return subject
+}
+
+func subjectFromClaims(claims jwt.MapClaims) *token.Subject {
+	subject := token.Subject{}
+
+	// Числа в JSON декодируются в float64
+	if sub, ok := claims["sub"].(float64); ok {
+		subject.ID = uint64(sub)
+	}
+
+	subject.Email, _ = claims["email"].(string)
+	subject.Phone, _ = claims["phone"].(string)
+
+	if roles, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range roles {
+			if r, ok := role.(string); ok {
+				subject.Roles = append(subject.Roles, r)
+			}
+		}
+	}
+
+	return &subject
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"service-template/internal/db"
+	"service-template/pkg/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+// Context возвращает контекст запроса для передачи в сервисы. Контекст содержит
+// спан запроса и логгер log с идентификаторами трассировки.
+// Запрос с Cache-Control: no-cache читает данные с основного сервера Postgres,
+// чтобы клиент увидел результат своей предыдущей записи.
+func Context(c *fiber.Ctx, log *zerolog.Logger) context.Context {
+	ctx := c.UserContext()
+
+	if strings.Contains(c.Get(fiber.HeaderCacheControl), "no-cache") {
+		ctx = db.WithPrimary(ctx)
+	}
+
+	return tracing.Logger(ctx, log).WithContext(ctx)
+}
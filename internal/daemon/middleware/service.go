@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"service-template/pkg/certs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ServiceKey ключ, по которому идентификатор сервиса-клиента хранится в контексте запроса.
+const ServiceKey = "service"
+
+// ClientCert сохраняет в контексте идентификатор сервиса из проверенного клиентского сертификата.
+func ClientCert() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		state := c.Context().TLSConnectionState()
+		if state != nil && len(state.VerifiedChains) > 0 {
+			c.Locals(ServiceKey, certs.Identity(state.VerifiedChains[0][0]))
+		}
+
+		return c.Next()
+	}
+}
+
+// Service возвращает идентификатор сервиса-клиента или пустую строку,
+// если клиент не предъявил проверенный сертификат.
+func Service(c *fiber.Ctx) string {
+	service, _ := c.Locals(ServiceKey).(string)
+
+	return service
+}
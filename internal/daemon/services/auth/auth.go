@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"service-template/internal/config"
@@ -11,48 +12,77 @@ import (
 	"service-template/internal/daemon/services/auth/response"
 	"service-template/internal/db"
 	"service-template/internal/db/users"
+	"service-template/internal/events"
+	"service-template/internal/model"
 	"service-template/internal/utils"
+	"service-template/pkg/password"
+	"service-template/pkg/problem"
+	"service-template/pkg/tracing"
 
 	"github.com/golang-jwt/jwt/v4"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/rs/zerolog"
 )
 
 var (
-	ErrUserAlreadyExists       = errors.New("user already exists")
-	ErrWrongUsernameOrPassword = errors.New("wrong username or password")
+	ErrUserAlreadyExists       = problem.New(http.StatusConflict, "user_already_exists", "user already exists")
+	ErrWrongUsernameOrPassword = problem.New(http.StatusUnauthorized, "wrong_credentials", "wrong username or password")
+	ErrPasswordResetRequired   = problem.New(http.StatusForbidden, "password_reset_required", "password reset required")
+	ErrUserBlocked             = problem.New(http.StatusForbidden, "user_blocked", "user is blocked")
+	ErrVersionConflict         = problem.New(http.StatusConflict, "version_conflict", "version conflict")
 )
 
 type Service struct {
 	cfg     *config.Config
 	storage *db.Storage
+	metrics *Metrics
 }
 
-func NewService(cfg *config.Config, storage *db.Storage) *Service {
+func NewService(cfg *config.Config, storage *db.Storage, metrics *Metrics) *Service {
 	return &Service{
 		cfg:     cfg,
 		storage: storage,
+		metrics: metrics,
 	}
 }
 
 // SignUp регистрация пользователя.
-func (s *Service) SignUp(ctx context.Context, signup *request.SignUp) (*response.SignUp, error) {
-	if bytes, err := bcrypt.GenerateFromPassword([]byte(signup.Password), bcrypt.DefaultCost); err != nil {
+func (s *Service) SignUp(ctx context.Context, signup *request.SignUp) (_ *response.SignUp, err error) {
+	ctx, span := tracing.Start(ctx, "auth.SignUp")
+	defer tracing.End(span, &err)
+
+	if hash, err := password.Hash(signup.Password); err != nil {
 		return nil, fmt.Errorf("user bcrypt: %w", err)
 	} else {
-		signup.Password = string(bytes)
+		signup.Password = hash
 	}
 
-	if exists, err := s.storage.Users.Exists(ctx, signup.ToModel()); err != nil {
-		return nil, fmt.Errorf("exists user: %w", err)
-	} else if exists {
-		return nil, ErrUserAlreadyExists
-	}
+	var user *model.User
+
+	// Пользователь и событие о регистрации сохраняются атомарно
+	err = s.storage.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		// Занятость email и телефона проверяет уникальный индекс, а не предварительный поиск,
+		// который не защищает от одновременной регистрации
+		if user, err = s.storage.Users.Create(ctx, signup.ToModel()); err != nil {
+			if errors.Is(err, users.ErrExists) {
+				return ErrUserAlreadyExists
+			}
+
+			return fmt.Errorf("user create: %w", err)
+		}
 
-	user, err := s.storage.Users.Create(ctx, signup.ToModel())
+		if err = s.storage.Outbox.AddUser(ctx, events.UserRegistered, user); err != nil {
+			return fmt.Errorf("outbox add: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("user create: %w", err)
+		return nil, err
 	}
 
+	s.metrics.signUps.Inc()
+
 	result := response.SignUp{
 		ID:    user.ID,
 		Email: user.Email,
@@ -63,22 +93,44 @@ func (s *Service) SignUp(ctx context.Context, signup *request.SignUp) (*response
 }
 
 // SignIn ход в аккаунт пользователя.
-func (s *Service) SignIn(ctx context.Context, signin *request.SignIn) (*response.SignIn, error) {
+func (s *Service) SignIn(ctx context.Context, signin *request.SignIn) (_ *response.SignIn, err error) {
+	ctx, span := tracing.Start(ctx, "auth.SignIn")
+	defer tracing.End(span, &err)
+
 	// Получаем пользователя по email или телефону
 	user, err := s.storage.Users.Get(ctx, signin.ToModel())
 	if err != nil {
 		if errors.Is(err, users.ErrNotExists) {
+			s.metrics.signInFailures.WithLabelValues("unknown_user").Inc()
 			return nil, ErrWrongUsernameOrPassword
 		}
 
 		return nil, fmt.Errorf("user get: %w", err)
 	}
 
-	// Проверяем совпадение пароля
-	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(signin.Password)); err != nil {
+	// Проверяем совпадение пароля. Требование сброса проверяется только после него,
+	// чтобы ответ без верного пароля не выдавал существование аккаунта.
+	if err = password.Compare(user.Password, signin.Password); err != nil {
+		s.metrics.signInFailures.WithLabelValues("wrong_password").Inc()
 		return nil, ErrWrongUsernameOrPassword
 	}
 
+	if user.BlockedAt != nil {
+		s.metrics.signInFailures.WithLabelValues("user_blocked").Inc()
+		return nil, ErrUserBlocked
+	}
+
+	// Пароль временный или неизвестен, пользователь должен сменить его через ChangePassword
+	if user.PasswordReset {
+		s.metrics.signInFailures.WithLabelValues("password_reset_required").Inc()
+		return nil, ErrPasswordResetRequired
+	}
+
+	// Событие о входе не должно мешать входу, поэтому ошибка записи только логируется
+	if err := s.storage.Outbox.AddUser(ctx, events.UserSignedIn, user); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Uint64("user_id", user.ID).Msg("outbox add")
+	}
+
 	// Время жизни токена
 	expiration := time.Now().Add(s.cfg.Server.Auth.AccessExpire).Unix()
 
@@ -88,6 +140,7 @@ func (s *Service) SignIn(ctx context.Context, signin *request.SignIn) (*response
 		"sub":   user.ID,
 		"email": user.Email,
 		"phone": user.Phone,
+		"roles": user.Roles,
 	}
 
 	// Создаем новый JWT-токен и подписываем его по алгоритму HS256
@@ -106,3 +159,56 @@ func (s *Service) SignIn(ctx context.Context, signin *request.SignIn) (*response
 
 	return &result, nil
 }
+
+// ChangePassword смена пароля пользователем по текущему паролю.
+// Снимает требование сброса пароля, поэтому доступна пользователям, которым нужен сброс,
+// но не заблокированным.
+func (s *Service) ChangePassword(ctx context.Context, change *request.ChangePassword) (err error) {
+	ctx, span := tracing.Start(ctx, "auth.ChangePassword")
+	defer tracing.End(span, &err)
+
+	user, err := s.storage.Users.Get(ctx, change.ToModel())
+	if err != nil {
+		if errors.Is(err, users.ErrNotExists) {
+			return ErrWrongUsernameOrPassword
+		}
+
+		return fmt.Errorf("user get: %w", err)
+	}
+
+	if err = password.Compare(user.Password, change.Password); err != nil {
+		return ErrWrongUsernameOrPassword
+	}
+
+	if user.BlockedAt != nil {
+		return ErrUserBlocked
+	}
+
+	hash, err := password.Hash(change.NewPassword)
+	if err != nil {
+		return fmt.Errorf("user bcrypt: %w", err)
+	}
+
+	user.Password = hash
+	user.PasswordReset = false
+
+	// Пароль и событие о его смене сохраняются атомарно, при повторе обновляется копия
+	return s.storage.WithinTx(ctx, func(ctx context.Context) error {
+		changed := *user
+
+		saved, err := s.storage.Users.Update(ctx, &changed)
+		if err != nil {
+			if errors.Is(err, users.ErrConflict) {
+				return ErrVersionConflict
+			}
+
+			return fmt.Errorf("user update: %w", err)
+		}
+
+		if err = s.storage.Outbox.AddUser(ctx, events.PasswordChanged, saved); err != nil {
+			return fmt.Errorf("outbox add: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"service-template/internal/config"
+	"service-template/internal/config/server"
+	"service-template/internal/daemon/services/auth/request"
+	"service-template/internal/db"
+	"service-template/internal/db/outbox"
+	"service-template/internal/events"
+	"service-template/internal/model"
+	"service-template/pkg/password"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T) (*Service, *db.Storage) {
+	t.Helper()
+
+	cfg := &config.Config{Server: server.NewConfig()}
+	cfg.Server.Auth.TokenSecret = "secret"
+	cfg.Server.Auth.AccessExpire = time.Hour
+
+	storage := db.NewMemoryStorage(cfg)
+
+	return NewService(cfg, storage, NewMetrics(prometheus.NewRegistry())), storage
+}
+
+func TestService_SignUp(t *testing.T) {
+	service, storage := newService(t)
+	ctx := context.Background()
+
+	result, err := service.SignUp(ctx, &request.SignUp{Email: "user@example.com", Password: "Password1!"})
+	require.NoError(t, err)
+	assert.NotZero(t, result.ID)
+	assert.Equal(t, "user@example.com", result.Email)
+
+	user, err := storage.Users.GetByID(ctx, result.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, "Password1!", user.Password, "password is hashed")
+
+	rows, err := storage.Outbox.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, string(events.UserRegistered), rows[0].Type)
+
+	_, err = service.SignUp(ctx, &request.SignUp{Email: "user@example.com", Password: "Password1!"})
+	assert.ErrorIs(t, err, ErrUserAlreadyExists)
+}
+
+func TestService_SignIn(t *testing.T) {
+	service, _ := newService(t)
+	ctx := context.Background()
+
+	_, err := service.SignUp(ctx, &request.SignUp{Email: "user@example.com", Password: "Password1!"})
+	require.NoError(t, err)
+
+	result, err := service.SignIn(ctx, &request.SignIn{Email: "user@example.com", Password: "Password1!"})
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(result.AccessToken, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", claims["email"])
+
+	_, err = service.SignIn(ctx, &request.SignIn{Email: "user@example.com", Password: "Wrong1!"})
+	assert.ErrorIs(t, err, ErrWrongUsernameOrPassword)
+
+	_, err = service.SignIn(ctx, &request.SignIn{Email: "missing@example.com", Password: "Password1!"})
+	assert.ErrorIs(t, err, ErrWrongUsernameOrPassword)
+}
+
+// failingOutbox хранилище событий, запись в которое всегда завершается ошибкой.
+type failingOutbox struct {
+	outbox.Discard
+}
+
+func (failingOutbox) AddUser(context.Context, events.Type, *model.User) error {
+	return errors.New("outbox unavailable")
+}
+
+func TestService_SignIn_outboxFailure(t *testing.T) {
+	service, storage := newService(t)
+	ctx := context.Background()
+
+	_, err := service.SignUp(ctx, &request.SignUp{Email: "user@example.com", Password: "Password1!"})
+	require.NoError(t, err)
+
+	// Событие о входе не обязательно, вход выполняется и без него
+	storage.Outbox = failingOutbox{}
+
+	_, err = service.SignIn(ctx, &request.SignIn{Email: "user@example.com", Password: "Password1!"})
+	assert.NoError(t, err)
+}
+
+func TestService_SignIn_passwordReset(t *testing.T) {
+	service, storage := newService(t)
+	ctx := context.Background()
+
+	hash, err := password.Hash("Temporary1!")
+	require.NoError(t, err)
+
+	_, err = storage.Users.Create(ctx, &model.User{Email: "imported@example.com", Password: hash, PasswordReset: true})
+	require.NoError(t, err)
+
+	// Без верного пароля ответ не отличается от несуществующего пользователя
+	_, err = service.SignIn(ctx, &request.SignIn{Email: "imported@example.com", Password: "Password1!"})
+	assert.ErrorIs(t, err, ErrWrongUsernameOrPassword)
+
+	_, err = service.SignIn(ctx, &request.SignIn{Email: "imported@example.com", Password: "Temporary1!"})
+	assert.ErrorIs(t, err, ErrPasswordResetRequired)
+
+	failures := service.metrics.signInFailures
+	assert.Equal(t, 1.0, testutil.ToFloat64(failures.WithLabelValues("wrong_password")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(failures.WithLabelValues("password_reset_required")))
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	service, storage := newService(t)
+	ctx := context.Background()
+
+	hash, err := password.Hash("Temporary1!")
+	require.NoError(t, err)
+
+	user, err := storage.Users.Create(ctx, &model.User{Email: "imported@example.com", Password: hash, PasswordReset: true})
+	require.NoError(t, err)
+
+	err = service.ChangePassword(ctx, &request.ChangePassword{Email: "imported@example.com", Password: "Wrong1!", NewPassword: "Password1!"})
+	assert.ErrorIs(t, err, ErrWrongUsernameOrPassword)
+
+	err = service.ChangePassword(ctx, &request.ChangePassword{Email: "imported@example.com", Password: "Temporary1!", NewPassword: "Password1!"})
+	require.NoError(t, err)
+
+	changed, err := storage.Users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, changed.PasswordReset)
+
+	rows, err := storage.Outbox.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, string(events.PasswordChanged), rows[0].Type)
+
+	_, err = service.SignIn(ctx, &request.SignIn{Email: "imported@example.com", Password: "Temporary1!"})
+	assert.ErrorIs(t, err, ErrWrongUsernameOrPassword)
+
+	_, err = service.SignIn(ctx, &request.SignIn{Email: "imported@example.com", Password: "Password1!"})
+	assert.NoError(t, err)
+}
+
+func TestService_blocked(t *testing.T) {
+	service, storage := newService(t)
+	ctx := context.Background()
+
+	hash, err := password.Hash("Password1!")
+	require.NoError(t, err)
+
+	blockedAt := time.Now()
+	_, err = storage.Users.Create(ctx, &model.User{Email: "blocked@example.com", Password: hash, BlockedAt: &blockedAt})
+	require.NoError(t, err)
+
+	// Без верного пароля блокировка не раскрывается
+	_, err = service.SignIn(ctx, &request.SignIn{Email: "blocked@example.com", Password: "Wrong1!"})
+	assert.ErrorIs(t, err, ErrWrongUsernameOrPassword)
+
+	_, err = service.SignIn(ctx, &request.SignIn{Email: "blocked@example.com", Password: "Password1!"})
+	assert.ErrorIs(t, err, ErrUserBlocked)
+
+	err = service.ChangePassword(ctx, &request.ChangePassword{Email: "blocked@example.com", Password: "Password1!", NewPassword: "Password2!"})
+	assert.ErrorIs(t, err, ErrUserBlocked)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(service.metrics.signInFailures.WithLabelValues("user_blocked")))
+}
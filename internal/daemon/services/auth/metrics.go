@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics бизнес-метрики регистрации и входа.
+type Metrics struct {
+	signUps        prometheus.Counter
+	signInFailures *prometheus.CounterVec
+}
+
+// NewMetrics создает метрики и регистрирует их в reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		signUps: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "auth_signups_total",
+			Help: "Number of registered users.",
+		}),
+		signInFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_signin_failures_total",
+			Help: "Number of failed sign in attempts by reason.",
+		}, []string{"reason"}),
+	}
+
+	reg.MustRegister(m.signUps, m.signInFailures)
+
+	return m
+}
@@ -0,0 +1,34 @@
+package request
+
+import (
+	"service-template/internal/config/valid"
+	"service-template/internal/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// ChangePassword Структура HTTP-запроса на смену пароля пользователем.
+// Доступна без токена, чтобы пользователь с требованием сброса мог сменить временный пароль.
+type ChangePassword struct {
+	Email       string `json:"email,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	Password    string `json:"password"`
+	NewPassword string `json:"new_password"`
+}
+
+func (in ChangePassword) Validate() error {
+	return validation.ValidateStruct(&in,
+		validation.Field(&in.Phone, validation.Required.When(in.Email == "").Error("either phone or email is required")),
+		validation.Field(&in.Email, validation.Required.When(in.Phone == "").Error("either phone or email is required")),
+		validation.Field(&in.Password, validation.Required, validation.Match(valid.Password)),
+		validation.Field(&in.NewPassword, validation.Required, validation.Match(valid.Password),
+			validation.NotIn(in.Password).Error("must differ from the current password")),
+	)
+}
+
+func (in ChangePassword) ToModel() *model.User {
+	return &model.User{
+		Email: in.Email,
+		Phone: in.Phone,
+	}
+}
@@ -0,0 +1,261 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"service-template/internal/config"
+	"service-template/internal/daemon/services/features/request"
+	"service-template/internal/daemon/services/features/response"
+	"service-template/internal/db"
+	"service-template/internal/db/features"
+	"service-template/internal/db/token"
+	"service-template/pkg/cache"
+	"service-template/pkg/flags"
+	"service-template/pkg/problem"
+	"service-template/pkg/tracing"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrFlagNotFound    = problem.New(http.StatusNotFound, "feature_flag_not_found", "feature flag not found")
+	ErrFlagExists      = problem.New(http.StatusConflict, "feature_flag_exists", "feature flag already exists")
+	ErrVersionConflict = problem.New(http.StatusConflict, "version_conflict", "version conflict")
+)
+
+// Service управляет флагами функциональности и вычисляет их значения.
+// Флаги вычисляются по копии в памяти, которая обновляется при изменении
+// флага любым экземпляром сервиса через Redis pub/sub и периодически целиком.
+type Service struct {
+	cfg     *config.Config
+	storage *db.Storage
+	flags   *cache.Cache[string, *flags.Flag]
+}
+
+func NewService(cfg *config.Config, storage *db.Storage) *Service {
+	return &Service{
+		cfg:     cfg,
+		storage: storage,
+		flags:   cache.NewCache[string, *flags.Flag](),
+	}
+}
+
+// Enabled вычисляет флаг key для пользователя. Subject равен nil для анонимных запросов.
+// Неизвестные флаги выключены.
+func (s *Service) Enabled(key string, subject *token.Subject) bool {
+	flag, _ := s.flags.Get(key)
+
+	return flag.Evaluate(s.target(subject))
+}
+
+// Evaluate вычисляет все флаги для пользователя.
+func (s *Service) Evaluate(subject *token.Subject) response.Evaluation {
+	target := s.target(subject)
+	result := response.Evaluation{}
+
+	for _, flag := range s.flags.ToList() {
+		result[flag.Key] = flag.Evaluate(target)
+	}
+
+	return result
+}
+
+func (s *Service) Create(ctx context.Context, in *request.CreateFlag) (_ *response.Flag, err error) {
+	ctx, span := tracing.Start(ctx, "features.Create")
+	defer tracing.End(span, &err)
+
+	flag, err := s.storage.Features.Create(ctx, in.ToModel())
+	if err != nil {
+		if errors.Is(err, features.ErrExists) {
+			return nil, ErrFlagExists
+		}
+
+		return nil, fmt.Errorf("feature flag create: %w", err)
+	}
+
+	s.changed(ctx, flag.Key)
+
+	return response.NewFlag(flag), nil
+}
+
+func (s *Service) Get(ctx context.Context, key string) (_ *response.Flag, err error) {
+	ctx, span := tracing.Start(ctx, "features.Get")
+	defer tracing.End(span, &err)
+
+	flag, err := s.storage.Features.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, features.ErrNotExists) {
+			return nil, ErrFlagNotFound
+		}
+
+		return nil, fmt.Errorf("feature flag get: %w", err)
+	}
+
+	return response.NewFlag(flag), nil
+}
+
+func (s *Service) List(ctx context.Context) (_ []*response.Flag, err error) {
+	ctx, span := tracing.Start(ctx, "features.List")
+	defer tracing.End(span, &err)
+
+	list, err := s.storage.Features.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("feature flag list: %w", err)
+	}
+
+	result := make([]*response.Flag, 0, len(list))
+	for _, flag := range list {
+		result = append(result, response.NewFlag(flag))
+	}
+
+	return result, nil
+}
+
+// Update изменяет флаг. Если version не равна нулю, флаг изменяется, только если его
+// текущая версия совпадает с version. Изменения, сделанные после чтения флага, не перезаписываются.
+func (s *Service) Update(ctx context.Context, key string, in *request.UpdateFlag, version uint64) (_ *response.Flag, err error) {
+	ctx, span := tracing.Start(ctx, "features.Update")
+	defer tracing.End(span, &err)
+
+	flag, err := s.storage.Features.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, features.ErrNotExists) {
+			return nil, ErrFlagNotFound
+		}
+
+		return nil, fmt.Errorf("feature flag get: %w", err)
+	}
+
+	if version != 0 && version != flag.Version {
+		return nil, ErrVersionConflict
+	}
+
+	in.Apply(flag)
+
+	if flag, err = s.storage.Features.Update(ctx, flag); err != nil {
+		if errors.Is(err, features.ErrNotExists) {
+			return nil, ErrFlagNotFound
+		}
+
+		if errors.Is(err, features.ErrConflict) {
+			return nil, ErrVersionConflict
+		}
+
+		return nil, fmt.Errorf("feature flag update: %w", err)
+	}
+
+	s.changed(ctx, flag.Key)
+
+	return response.NewFlag(flag), nil
+}
+
+func (s *Service) Delete(ctx context.Context, key string) (err error) {
+	ctx, span := tracing.Start(ctx, "features.Delete")
+	defer tracing.End(span, &err)
+
+	if err := s.storage.Features.Delete(ctx, key); err != nil {
+		if errors.Is(err, features.ErrNotExists) {
+			return ErrFlagNotFound
+		}
+
+		return fmt.Errorf("feature flag delete: %w", err)
+	}
+
+	s.changed(ctx, key)
+
+	return nil
+}
+
+// Load загружает все флаги в память.
+func (s *Service) Load(ctx context.Context) error {
+	list, err := s.storage.Features.List(ctx)
+	if err != nil {
+		return fmt.Errorf("feature flags load: %w", err)
+	}
+
+	loaded := make(map[string]*flags.Flag, len(list))
+	for _, flag := range list {
+		loaded[flag.Key] = flag.Flag()
+	}
+
+	s.flags.Load(loaded)
+
+	return nil
+}
+
+// Watch обновляет флаги в памяти при получении уведомлений об изменениях
+// и периодически перезагружает их целиком до отмены контекста.
+func (s *Service) Watch(ctx context.Context, log *zerolog.Logger) {
+	sub := s.storage.Redis().Subscribe(ctx, s.cfg.Features.Channel)
+	defer sub.Close()
+
+	ticker := time.NewTicker(s.cfg.Features.RefreshInterval)
+	defer ticker.Stop()
+
+	messages := sub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			if err := s.reload(ctx, msg.Payload); err != nil {
+				log.Error().Err(err).Str("flag", msg.Payload).Msg("feature flag reload")
+			}
+		case <-ticker.C:
+			if err := s.Load(ctx); err != nil {
+				log.Error().Err(err).Msg("feature flags refresh")
+			}
+		}
+	}
+}
+
+// reload обновляет в памяти один флаг.
+func (s *Service) reload(ctx context.Context, key string) error {
+	flag, err := s.storage.Features.Get(ctx, key)
+	if errors.Is(err, features.ErrNotExists) {
+		s.flags.Delete(key)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	s.flags.Set(key, flag.Flag())
+
+	return nil
+}
+
+// changed обновляет флаг в памяти и уведомляет остальные экземпляры сервиса.
+// Если уведомление не доставлено, флаг обновится при периодической перезагрузке.
+func (s *Service) changed(ctx context.Context, key string) {
+	logger := zerolog.Ctx(ctx)
+
+	if err := s.reload(ctx, key); err != nil {
+		logger.Error().Err(err).Str("flag", key).Msg("feature flag reload")
+	}
+
+	if err := s.storage.Redis().Publish(ctx, s.cfg.Features.Channel, key).Err(); err != nil {
+		logger.Error().Err(err).Str("flag", key).Msg("feature flag publish")
+	}
+}
+
+func (s *Service) target(subject *token.Subject) flags.Target {
+	target := flags.Target{Environment: s.cfg.Features.Environment}
+
+	if subject != nil {
+		target.UserID = subject.ID
+		target.Roles = subject.Roles
+	}
+
+	return target
+}
@@ -0,0 +1,70 @@
+package request
+
+import (
+	"service-template/internal/config/valid"
+	"service-template/internal/model"
+	"service-template/pkg/flags"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// CreateFlag Структура HTTP-запроса на создание флага функциональности.
+type CreateFlag struct {
+	Key         string       `json:"key"`
+	Description string       `json:"description,omitempty"`
+	Enabled     bool         `json:"enabled"`
+	Rules       []flags.Rule `json:"rules,omitempty"`
+}
+
+func (in CreateFlag) Validate() error {
+	return validation.ValidateStruct(&in,
+		validation.Field(&in.Key, validation.Required, validation.Length(1, 128), validation.Match(valid.Name)),
+		validation.Field(&in.Rules, validation.Each(validation.By(validateRule))),
+	)
+}
+
+func (in CreateFlag) ToModel() *model.FeatureFlag {
+	return &model.FeatureFlag{
+		Key:         in.Key,
+		Description: in.Description,
+		Enabled:     in.Enabled,
+		Rules:       in.Rules,
+	}
+}
+
+// UpdateFlag Структура HTTP-запроса на частичное обновление флага функциональности.
+// Переданные правила заменяют текущие целиком.
+type UpdateFlag struct {
+	Description *string       `json:"description,omitempty"`
+	Enabled     *bool         `json:"enabled,omitempty"`
+	Rules       *[]flags.Rule `json:"rules,omitempty"`
+}
+
+func (in UpdateFlag) Validate() error {
+	return validation.ValidateStruct(&in,
+		validation.Field(&in.Rules, validation.Each(validation.By(validateRule))),
+	)
+}
+
+// Apply применяет изменения к флагу.
+func (in UpdateFlag) Apply(flag *model.FeatureFlag) {
+	if in.Description != nil {
+		flag.Description = *in.Description
+	}
+
+	if in.Enabled != nil {
+		flag.Enabled = *in.Enabled
+	}
+
+	if in.Rules != nil {
+		flag.Rules = *in.Rules
+	}
+}
+
+func validateRule(value interface{}) error {
+	rule, _ := value.(flags.Rule)
+
+	return validation.ValidateStruct(&rule,
+		validation.Field(&rule.Percentage, validation.Min(0), validation.Max(100)),
+	)
+}
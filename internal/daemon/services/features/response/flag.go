@@ -0,0 +1,33 @@
+package response
+
+import (
+	"time"
+
+	"service-template/internal/model"
+	"service-template/pkg/flags"
+)
+
+type Flag struct {
+	Key         string       `json:"key"`
+	Description string       `json:"description,omitempty"`
+	Enabled     bool         `json:"enabled"`
+	Rules       []flags.Rule `json:"rules"`
+	CreatedAt   *time.Time   `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time   `json:"updated_at,omitempty"`
+	Version     uint64       `json:"version"`
+}
+
+func NewFlag(flag *model.FeatureFlag) *Flag {
+	return &Flag{
+		Key:         flag.Key,
+		Description: flag.Description,
+		Enabled:     flag.Enabled,
+		Rules:       flag.Rules,
+		CreatedAt:   flag.CreatedAt,
+		UpdatedAt:   flag.UpdatedAt,
+		Version:     flag.Version,
+	}
+}
+
+// Evaluation значения флагов для текущего пользователя.
+type Evaluation map[string]bool
@@ -3,15 +3,30 @@ package services
 import (
 	"service-template/internal/config"
 	"service-template/internal/daemon/services/auth"
+	"service-template/internal/daemon/services/features"
+	"service-template/internal/daemon/services/settings"
+	"service-template/internal/daemon/services/users"
+	"service-template/internal/daemon/services/webhooks"
 	"service-template/internal/db"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type Interactor struct {
-	Auth *auth.Service
+	Auth     *auth.Service
+	Users    *users.Service
+	Settings *settings.Service
+	Webhooks *webhooks.Service
+	Features *features.Service
 }
 
-func NewInteractor(cfg *config.Config, storage *db.Storage) *Interactor {
+// NewInteractor создает сервисы. Бизнес-метрики сервисов регистрируются в reg.
+func NewInteractor(cfg *config.Config, storage *db.Storage, reg prometheus.Registerer) *Interactor {
 	return &Interactor{
-		Auth: auth.NewService(cfg, storage),
+		Auth:     auth.NewService(cfg, storage, auth.NewMetrics(reg)),
+		Users:    users.NewService(cfg, storage),
+		Settings: settings.NewService(cfg, storage),
+		Webhooks: webhooks.NewService(cfg, storage),
+		Features: features.NewService(cfg, storage),
 	}
 }
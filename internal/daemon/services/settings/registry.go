@@ -0,0 +1,112 @@
+package settings
+
+import (
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Type тип значения настройки в JSON.
+type Type string
+
+const (
+	TypeString Type = "string"
+	TypeNumber Type = "number"
+	TypeBool   Type = "bool"
+)
+
+// Key описание известной настройки.
+type Key struct {
+	Name    string
+	Type    Type
+	Default interface{}
+	Rules   []validation.Rule
+}
+
+// Registry реестр известных настроек пользователя.
+type Registry struct {
+	keys map[string]Key
+}
+
+// NewRegistry создает реестр из описаний настроек.
+func NewRegistry(keys ...Key) *Registry {
+	registry := Registry{
+		keys: make(map[string]Key, len(keys)),
+	}
+
+	for _, key := range keys {
+		registry.keys[key.Name] = key
+	}
+
+	return &registry
+}
+
+// DefaultRegistry настройки, известные сервису.
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		Key{Name: "locale", Type: TypeString, Default: "en", Rules: []validation.Rule{validation.In("en", "ru")}},
+		Key{Name: "theme", Type: TypeString, Default: "system", Rules: []validation.Rule{validation.In("light", "dark", "system")}},
+		Key{Name: "notifications_email", Type: TypeBool, Default: true},
+		Key{Name: "notifications_push", Type: TypeBool, Default: true},
+	)
+}
+
+// Defaults возвращает значения по умолчанию всех известных настроек.
+func (r *Registry) Defaults() map[string]interface{} {
+	result := make(map[string]interface{}, len(r.keys))
+	for name, key := range r.keys {
+		result[name] = key.Default
+	}
+
+	return result
+}
+
+// Known возвращает только настройки, известные реестру. Нужен для сохраненных значений
+// настроек, удаленных из реестра.
+func (r *Registry) Known(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		if _, ok := r.keys[name]; ok {
+			result[name] = value
+		}
+	}
+
+	return result
+}
+
+// Validate проверяет, что все настройки известны и имеют корректные значения.
+func (r *Registry) Validate(values map[string]interface{}) error {
+	errs := validation.Errors{}
+
+	for name, value := range values {
+		key, ok := r.keys[name]
+		if !ok {
+			errs[name] = fmt.Errorf("unknown setting")
+			continue
+		}
+
+		if !key.Type.match(value) {
+			errs[name] = fmt.Errorf("must be a %s", key.Type)
+			continue
+		}
+
+		if err := validation.Validate(value, key.Rules...); err != nil {
+			errs[name] = err
+		}
+	}
+
+	return errs.Filter()
+}
+
+func (t Type) match(value interface{}) bool {
+	switch value.(type) {
+	case string:
+		return t == TypeString
+	case float64:
+		return t == TypeNumber
+	case bool:
+		return t == TypeBool
+	}
+
+	return false
+}
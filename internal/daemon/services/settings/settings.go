@@ -0,0 +1,91 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"service-template/internal/config"
+	"service-template/internal/db"
+	"service-template/internal/db/settings"
+	"service-template/pkg/mergepatch"
+	"service-template/pkg/problem"
+	"service-template/pkg/tracing"
+)
+
+var (
+	ErrInvalidSettings = problem.New(http.StatusBadRequest, "invalid_settings", "invalid settings")
+	ErrVersionConflict = problem.New(http.StatusConflict, "version_conflict", "version conflict")
+)
+
+// maxUpdateAttempts количество попыток применить изменения без ожидаемой версии,
+// если настройки одновременно изменил другой запрос.
+const maxUpdateAttempts = 3
+
+type Service struct {
+	cfg      *config.Config
+	storage  *db.Storage
+	registry *Registry
+}
+
+func NewService(cfg *config.Config, storage *db.Storage) *Service {
+	return &Service{
+		cfg:      cfg,
+		storage:  storage,
+		registry: DefaultRegistry(),
+	}
+}
+
+// Get возвращает настройки пользователя, дополненные значениями по умолчанию, и их версию.
+// Сохраненные настройки, которых больше нет в реестре, не возвращаются.
+func (s *Service) Get(ctx context.Context, userID uint64) (_ map[string]interface{}, _ uint64, err error) {
+	ctx, span := tracing.Start(ctx, "settings.Get")
+	defer tracing.End(span, &err)
+
+	values, version, err := s.storage.Settings.Get(ctx, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("settings get: %w", err)
+	}
+
+	return mergepatch.Apply(s.registry.Defaults(), s.registry.Known(values)), version, nil
+}
+
+// Update применяет JSON Merge Patch к настройкам пользователя и возвращает их новую версию.
+// Значение null сбрасывает настройку к значению по умолчанию. Если version не равна нулю,
+// настройки должны иметь эту версию, иначе патч применяется к последней версии.
+// Сохраненные настройки, которых больше нет в реестре, удаляются.
+func (s *Service) Update(ctx context.Context, userID uint64, patch map[string]interface{}, version uint64) (_ map[string]interface{}, _ uint64, err error) {
+	ctx, span := tracing.Start(ctx, "settings.Update")
+	defer tracing.End(span, &err)
+
+	for attempt := 1; ; attempt++ {
+		values, current, err := s.storage.Settings.Get(ctx, userID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("settings get: %w", err)
+		}
+
+		if version != 0 && version != current {
+			return nil, 0, ErrVersionConflict
+		}
+
+		values = mergepatch.Apply(s.registry.Known(values), patch)
+
+		if err = s.registry.Validate(values); err != nil {
+			return nil, 0, ErrInvalidSettings.WithFields(err)
+		}
+
+		saved, err := s.storage.Settings.Save(ctx, userID, values, current)
+		if errors.Is(err, settings.ErrConflict) {
+			if version == 0 && attempt < maxUpdateAttempts {
+				continue
+			}
+
+			return nil, 0, ErrVersionConflict
+		} else if err != nil {
+			return nil, 0, fmt.Errorf("settings save: %w", err)
+		}
+
+		return mergepatch.Apply(s.registry.Defaults(), values), saved, nil
+	}
+}
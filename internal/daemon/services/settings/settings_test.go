@@ -0,0 +1,72 @@
+package settings
+
+import (
+	"context"
+	"testing"
+
+	"service-template/internal/db"
+	"service-template/internal/db/dbtest"
+	settingsdb "service-template/internal/db/settings"
+	"service-template/internal/db/users"
+	"service-template/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T) (*Service, *db.Storage, uint64) {
+	t.Helper()
+
+	sqldb := dbtest.SQLite(t)
+	storage := &db.Storage{Settings: settingsdb.NewStorage(sqldb)}
+
+	user, err := users.NewStorage(sqldb).Create(context.Background(), &model.User{Email: "user@example.com", Password: "hash"})
+	require.NoError(t, err)
+
+	return NewService(nil, storage), storage, user.ID
+}
+
+func TestService_Update(t *testing.T) {
+	service, _, userID := newService(t)
+	ctx := context.Background()
+
+	values, version, err := service.Update(ctx, userID, map[string]interface{}{"theme": "dark"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "dark", values["theme"])
+	assert.Equal(t, "en", values["locale"])
+	assert.EqualValues(t, 1, version)
+
+	values, version, err = service.Update(ctx, userID, map[string]interface{}{"locale": "ru"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "dark", values["theme"], "previous change is kept")
+	assert.Equal(t, "ru", values["locale"])
+	assert.EqualValues(t, 2, version)
+
+	// Клиент изменяет устаревшую версию
+	_, _, err = service.Update(ctx, userID, map[string]interface{}{"theme": "light"}, 1)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	_, _, err = service.Update(ctx, userID, map[string]interface{}{"unknown": true}, 0)
+	assert.ErrorIs(t, err, ErrInvalidSettings)
+}
+
+func TestService_unknownStoredKeys(t *testing.T) {
+	service, storage, userID := newService(t)
+	ctx := context.Background()
+
+	// Настройка удалена из реестра после сохранения
+	_, err := storage.Settings.Save(ctx, userID, map[string]interface{}{"theme": "dark", "removed": "value"}, 0)
+	require.NoError(t, err)
+
+	values, _, err := service.Get(ctx, userID)
+	require.NoError(t, err)
+	assert.NotContains(t, values, "removed")
+
+	_, version, err := service.Update(ctx, userID, map[string]interface{}{"locale": "ru"}, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, version)
+
+	stored, _, err := storage.Settings.Get(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"theme": "dark", "locale": "ru"}, stored)
+}
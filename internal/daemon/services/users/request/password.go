@@ -0,0 +1,20 @@
+package request
+
+import (
+	"service-template/internal/config/valid"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// SetPassword Структура HTTP-запроса на установку пароля администратором.
+// Если Reset установлен, пароль временный и пользователь должен сменить его перед входом.
+type SetPassword struct {
+	Password string `json:"password"`
+	Reset    bool   `json:"reset"`
+}
+
+func (in SetPassword) Validate() error {
+	return validation.ValidateStruct(&in,
+		validation.Field(&in.Password, validation.Required, validation.Match(valid.Password)),
+	)
+}
@@ -0,0 +1,45 @@
+package request
+
+import (
+	"time"
+
+	"service-template/internal/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Profile Структура HTTP-запроса на замену профиля пользователя.
+type Profile struct {
+	Name       string     `json:"name"`
+	Surname    string     `json:"surname"`
+	Patronymic string     `json:"patronymic"`
+	Sex        bool       `json:"sex"`
+	Birthday   *time.Time `json:"birthday,omitempty"`
+	Country    string     `json:"country"`
+	City       string     `json:"city"`
+	Address    string     `json:"address"`
+}
+
+func (in Profile) Validate() error {
+	return validation.ValidateStruct(&in,
+		validation.Field(&in.Name, validation.Length(0, 255)),
+		validation.Field(&in.Surname, validation.Length(0, 255)),
+		validation.Field(&in.Patronymic, validation.Length(0, 255)),
+		validation.Field(&in.Birthday, validation.Max(time.Now())),
+		validation.Field(&in.Country, validation.Length(0, 255)),
+		validation.Field(&in.City, validation.Length(0, 255)),
+		validation.Field(&in.Address, validation.Length(0, 255)),
+	)
+}
+
+// Apply переносит поля запроса в профиль.
+func (in Profile) Apply(profile *model.Profile) {
+	profile.Name = in.Name
+	profile.Surname = in.Surname
+	profile.Patronymic = in.Patronymic
+	profile.Sex = in.Sex
+	profile.Birthday = in.Birthday
+	profile.Country = in.Country
+	profile.City = in.City
+	profile.Address = in.Address
+}
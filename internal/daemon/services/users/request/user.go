@@ -0,0 +1,25 @@
+package request
+
+import (
+	"service-template/internal/config/valid"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// UpdateUser Структура HTTP-запроса на частичное обновление пользователя.
+// Незаполненные поля не изменяются.
+type UpdateUser struct {
+	Email   *string   `json:"email,omitempty"`
+	Phone   *string   `json:"phone,omitempty"`
+	Roles   *[]string `json:"roles,omitempty"`
+	Blocked *bool     `json:"blocked,omitempty"` // запрещает вход и смену пароля, выданные токены действуют до истечения
+}
+
+func (in UpdateUser) Validate() error {
+	return validation.ValidateStruct(&in,
+		validation.Field(&in.Email, validation.NilOrNotEmpty, is.EmailFormat),
+		validation.Field(&in.Phone, validation.NilOrNotEmpty, is.Digit),
+		validation.Field(&in.Roles, validation.Each(validation.Required, validation.Match(valid.Key))),
+	)
+}
@@ -0,0 +1,62 @@
+package response
+
+import (
+	"time"
+
+	"service-template/internal/model"
+)
+
+type User struct {
+	ID      uint64   `json:"id"`
+	Email   string   `json:"email,omitempty"`
+	Phone   string   `json:"phone,omitempty"`
+	Roles   []string `json:"roles,omitempty"`
+	Version uint64   `json:"version"`
+	// PasswordReset пользователь должен сменить пароль перед входом
+	PasswordReset bool       `json:"password_reset,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+	BlockedAt     *time.Time `json:"blocked_at,omitempty"`
+}
+
+func NewUser(user *model.User) *User {
+	return &User{
+		ID:            user.ID,
+		Email:         user.Email,
+		Phone:         user.Phone,
+		Roles:         user.Roles,
+		Version:       user.Version,
+		PasswordReset: user.PasswordReset,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		BlockedAt:     user.BlockedAt,
+	}
+}
+
+type Profile struct {
+	UserID     uint64     `json:"user_id"`
+	Name       string     `json:"name"`
+	Surname    string     `json:"surname"`
+	Patronymic string     `json:"patronymic"`
+	Sex        bool       `json:"sex"`
+	Birthday   *time.Time `json:"birthday,omitempty"`
+	Country    string     `json:"country"`
+	City       string     `json:"city"`
+	Address    string     `json:"address"`
+	Version    uint64     `json:"version"`
+}
+
+func NewProfile(profile *model.Profile) *Profile {
+	return &Profile{
+		UserID:     profile.UserID,
+		Name:       profile.Name,
+		Surname:    profile.Surname,
+		Patronymic: profile.Patronymic,
+		Sex:        profile.Sex,
+		Birthday:   profile.Birthday,
+		Country:    profile.Country,
+		City:       profile.City,
+		Address:    profile.Address,
+		Version:    profile.Version,
+	}
+}
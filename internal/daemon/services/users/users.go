@@ -0,0 +1,297 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"service-template/internal/config"
+	"service-template/internal/daemon/services/users/request"
+	"service-template/internal/daemon/services/users/response"
+	"service-template/internal/db"
+	"service-template/internal/db/profiles"
+	"service-template/internal/db/users"
+	"service-template/internal/events"
+	"service-template/internal/model"
+	"service-template/pkg/password"
+	"service-template/pkg/problem"
+	"service-template/pkg/tracing"
+)
+
+var (
+	ErrUserNotFound    = problem.New(http.StatusNotFound, "user_not_found", "user not found")
+	ErrProfileNotFound = problem.New(http.StatusNotFound, "profile_not_found", "profile not found")
+	ErrVersionConflict = problem.New(http.StatusConflict, "version_conflict", "version conflict")
+)
+
+type Service struct {
+	cfg     *config.Config
+	storage *db.Storage
+}
+
+func NewService(cfg *config.Config, storage *db.Storage) *Service {
+	return &Service{
+		cfg:     cfg,
+		storage: storage,
+	}
+}
+
+// Get возвращает пользователя по идентификатору.
+func (s *Service) Get(ctx context.Context, id uint64) (_ *response.User, err error) {
+	ctx, span := tracing.Start(ctx, "users.Get")
+	defer tracing.End(span, &err)
+
+	user, err := s.storage.Read(ctx).Users.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, users.ErrNotExists) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, fmt.Errorf("user get: %w", err)
+	}
+
+	return response.NewUser(user), nil
+}
+
+// Update частично обновляет пользователя.
+// Если version не равна нулю, обновление выполняется только для этой версии пользователя.
+func (s *Service) Update(ctx context.Context, id uint64, update *request.UpdateUser, version uint64) (_ *response.User, err error) {
+	ctx, span := tracing.Start(ctx, "users.Update")
+	defer tracing.End(span, &err)
+
+	user, err := s.storage.Users.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, users.ErrNotExists) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, fmt.Errorf("user get: %w", err)
+	}
+
+	if version != 0 && version != user.Version {
+		return nil, ErrVersionConflict
+	}
+
+	if update.Email != nil {
+		user.Email = *update.Email
+	}
+
+	if update.Phone != nil {
+		user.Phone = *update.Phone
+	}
+
+	if update.Roles != nil {
+		user.Roles = *update.Roles
+	}
+
+	changes := []events.Type{events.UserUpdated}
+
+	if update.Blocked != nil {
+		if !*update.Blocked && user.BlockedAt != nil {
+			user.BlockedAt = nil
+			changes = append(changes, events.UserUnblocked)
+		} else if *update.Blocked && user.BlockedAt == nil {
+			now := time.Now()
+			user.BlockedAt = &now
+			changes = append(changes, events.UserBlocked)
+		}
+	}
+
+	var saved *model.User
+
+	// Изменения и события о них сохраняются атомарно. Update меняет версию переданного
+	// пользователя, поэтому при повторе транзакции обновляется копия исходного.
+	err = s.storage.WithinTx(ctx, func(ctx context.Context) error {
+		changed := *user
+
+		var err error
+		if saved, err = s.storage.Users.Update(ctx, &changed); err != nil {
+			switch {
+			case errors.Is(err, users.ErrNotExists):
+				return ErrUserNotFound
+			case errors.Is(err, users.ErrConflict):
+				return ErrVersionConflict
+			}
+
+			return fmt.Errorf("user update: %w", err)
+		}
+
+		for _, typ := range changes {
+			if err = s.storage.Outbox.AddUser(ctx, typ, saved); err != nil {
+				return fmt.Errorf("outbox add: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.NewUser(saved), nil
+}
+
+// SetPassword устанавливает пароль пользователя. Если in.Reset установлен, пароль временный:
+// пользователь не сможет войти, пока не сменит его.
+// Если version не равна нулю, пароль устанавливается только для этой версии пользователя.
+func (s *Service) SetPassword(ctx context.Context, id uint64, in *request.SetPassword, version uint64) (_ *response.User, err error) {
+	ctx, span := tracing.Start(ctx, "users.SetPassword")
+	defer tracing.End(span, &err)
+
+	user, err := s.storage.Users.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, users.ErrNotExists) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, fmt.Errorf("user get: %w", err)
+	}
+
+	if version != 0 && version != user.Version {
+		return nil, ErrVersionConflict
+	}
+
+	if user.Password, err = password.Hash(in.Password); err != nil {
+		return nil, fmt.Errorf("user bcrypt: %w", err)
+	}
+
+	user.PasswordReset = in.Reset
+
+	var saved *model.User
+
+	err = s.storage.WithinTx(ctx, func(ctx context.Context) error {
+		changed := *user
+
+		var err error
+		if saved, err = s.storage.Users.Update(ctx, &changed); err != nil {
+			switch {
+			case errors.Is(err, users.ErrNotExists):
+				return ErrUserNotFound
+			case errors.Is(err, users.ErrConflict):
+				return ErrVersionConflict
+			}
+
+			return fmt.Errorf("user update: %w", err)
+		}
+
+		if err = s.storage.Outbox.AddUser(ctx, events.PasswordChanged, saved); err != nil {
+			return fmt.Errorf("outbox add: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.NewUser(saved), nil
+}
+
+// GetProfile возвращает профиль пользователя.
+func (s *Service) GetProfile(ctx context.Context, userID uint64) (_ *response.Profile, err error) {
+	ctx, span := tracing.Start(ctx, "users.GetProfile")
+	defer tracing.End(span, &err)
+
+	profile, err := s.storage.Read(ctx).Profiles.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, profiles.ErrNotExists) {
+			return nil, ErrProfileNotFound
+		}
+
+		return nil, fmt.Errorf("profile get: %w", err)
+	}
+
+	return response.NewProfile(profile), nil
+}
+
+// UpdateProfile заменяет профиль пользователя, создавая его при необходимости.
+// Если version не равна нулю, профиль должен существовать и иметь эту версию.
+func (s *Service) UpdateProfile(ctx context.Context, userID uint64, in *request.Profile, version uint64) (_ *response.Profile, err error) {
+	ctx, span := tracing.Start(ctx, "users.UpdateProfile")
+	defer tracing.End(span, &err)
+
+	user, err := s.storage.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, users.ErrNotExists) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, fmt.Errorf("user get: %w", err)
+	}
+
+	profile, err := s.storage.Profiles.GetByUserID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, profiles.ErrNotExists) {
+			return nil, fmt.Errorf("profile get: %w", err)
+		}
+
+		// Ожидалась конкретная версия, а профиля еще нет
+		if version != 0 {
+			return nil, ErrVersionConflict
+		}
+
+		profile = &model.Profile{UserID: userID}
+		in.Apply(profile)
+
+		return s.saveProfile(ctx, user, profile, s.storage.Profiles.Create)
+	}
+
+	if version != 0 && version != profile.Version {
+		return nil, ErrVersionConflict
+	}
+
+	in.Apply(profile)
+
+	return s.saveProfile(ctx, user, profile, s.storage.Profiles.Update)
+}
+
+// PurgeDeleted безвозвратно удаляет пользователей, удаленных раньше before.
+func (s *Service) PurgeDeleted(ctx context.Context, before time.Time) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "users.PurgeDeleted")
+	defer tracing.End(span, &err)
+
+	n, err := s.storage.Users.PurgeDeleted(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("users purge: %w", err)
+	}
+
+	return n, nil
+}
+
+// saveProfile сохраняет профиль функцией save вместе с событием об изменении.
+// save заполняет ID и версию переданного профиля, поэтому при повторе транзакции
+// сохраняется копия исходного.
+func (s *Service) saveProfile(ctx context.Context, user *model.User, profile *model.Profile,
+	save func(ctx context.Context, profile *model.Profile) (*model.Profile, error),
+) (*response.Profile, error) {
+	var saved *model.Profile
+
+	err := s.storage.WithinTx(ctx, func(ctx context.Context) error {
+		changed := *profile
+
+		var err error
+		if saved, err = save(ctx, &changed); err != nil {
+			switch {
+			case errors.Is(err, profiles.ErrNotExists):
+				return ErrProfileNotFound
+			case errors.Is(err, profiles.ErrConflict):
+				return ErrVersionConflict
+			}
+
+			return fmt.Errorf("profile save: %w", err)
+		}
+
+		if err = s.storage.Outbox.AddUser(ctx, events.ProfileUpdated, user); err != nil {
+			return fmt.Errorf("outbox add: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.NewProfile(saved), nil
+}
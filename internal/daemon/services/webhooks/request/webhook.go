@@ -0,0 +1,49 @@
+package request
+
+import (
+	"service-template/internal/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// CreateWebhook Структура HTTP-запроса на создание подписки.
+// Если секрет не указан, он будет сгенерирован.
+type CreateWebhook struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+func (in CreateWebhook) Validate() error {
+	return validation.ValidateStruct(&in,
+		validation.Field(&in.URL, validation.Required, is.URL),
+		validation.Field(&in.EventTypes, validation.Required, validation.Each(validation.Required)),
+		validation.Field(&in.Secret, validation.When(in.Secret != "", validation.Length(16, 128))),
+	)
+}
+
+func (in CreateWebhook) ToModel() *model.Webhook {
+	return &model.Webhook{
+		URL:        in.URL,
+		EventTypes: in.EventTypes,
+		Secret:     in.Secret,
+		Enabled:    true,
+	}
+}
+
+// UpdateWebhook Структура HTTP-запроса на частичное обновление подписки.
+type UpdateWebhook struct {
+	URL        *string   `json:"url,omitempty"`
+	EventTypes *[]string `json:"event_types,omitempty"`
+	Secret     *string   `json:"secret,omitempty"`
+	Enabled    *bool     `json:"enabled,omitempty"`
+}
+
+func (in UpdateWebhook) Validate() error {
+	return validation.ValidateStruct(&in,
+		validation.Field(&in.URL, validation.NilOrNotEmpty, is.URL),
+		validation.Field(&in.EventTypes, validation.NilOrNotEmpty, validation.Each(validation.Required)),
+		validation.Field(&in.Secret, validation.NilOrNotEmpty, validation.Length(16, 128)),
+	)
+}
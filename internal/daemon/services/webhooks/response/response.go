@@ -0,0 +1,66 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+
+	"service-template/internal/model"
+)
+
+type Webhook struct {
+	ID         uint64     `json:"id"`
+	URL        string     `json:"url"`
+	EventTypes []string   `json:"event_types"`
+	Secret     string     `json:"secret,omitempty"`
+	Enabled    bool       `json:"enabled"`
+	Failures   int        `json:"failures"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// NewWebhook создает ответ без секрета. Секрет возвращается только при создании подписки.
+func NewWebhook(webhook *model.Webhook) *Webhook {
+	return &Webhook{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		Enabled:    webhook.Enabled,
+		Failures:   webhook.Failures,
+		DisabledAt: webhook.DisabledAt,
+		CreatedAt:  webhook.CreatedAt,
+		UpdatedAt:  webhook.UpdatedAt,
+	}
+}
+
+type Delivery struct {
+	ID            uint64          `json:"id"`
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	ResponseCode  int             `json:"response_code,omitempty"`
+	ResponseBody  string          `json:"response_body,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	CreatedAt     *time.Time      `json:"created_at,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+}
+
+func NewDelivery(delivery *model.WebhookDelivery) *Delivery {
+	return &Delivery{
+		ID:            delivery.ID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		ResponseCode:  delivery.ResponseCode,
+		ResponseBody:  delivery.ResponseBody,
+		Error:         delivery.Error,
+		CreatedAt:     delivery.CreatedAt,
+		DeliveredAt:   delivery.DeliveredAt,
+	}
+}
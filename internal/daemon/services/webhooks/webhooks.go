@@ -0,0 +1,198 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"service-template/internal/config"
+	"service-template/internal/daemon/services/webhooks/request"
+	"service-template/internal/daemon/services/webhooks/response"
+	"service-template/internal/db"
+	"service-template/internal/db/webhooks"
+	"service-template/pkg/problem"
+	"service-template/pkg/tracing"
+)
+
+var (
+	ErrWebhookNotFound  = problem.New(http.StatusNotFound, "webhook_not_found", "webhook not found")
+	ErrDeliveryNotFound = problem.New(http.StatusNotFound, "webhook_delivery_not_found", "webhook delivery not found")
+)
+
+// deliveriesLimit количество последних доставок, возвращаемых в журнале.
+const deliveriesLimit = 100
+
+type Service struct {
+	cfg     *config.Config
+	storage *db.Storage
+}
+
+func NewService(cfg *config.Config, storage *db.Storage) *Service {
+	return &Service{
+		cfg:     cfg,
+		storage: storage,
+	}
+}
+
+// Create создает подписку. Секрет возвращается в ответе только здесь.
+func (s *Service) Create(ctx context.Context, in *request.CreateWebhook) (_ *response.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Create")
+	defer tracing.End(span, &err)
+
+	webhook := in.ToModel()
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("webhook secret: %w", err)
+		}
+
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	webhook, err = s.storage.Webhooks.Create(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("webhook create: %w", err)
+	}
+
+	result := response.NewWebhook(webhook)
+	result.Secret = webhook.Secret
+
+	return result, nil
+}
+
+func (s *Service) Get(ctx context.Context, id uint64) (_ *response.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Get")
+	defer tracing.End(span, &err)
+
+	webhook, err := s.storage.Read(ctx).Webhooks.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, webhooks.ErrNotExists) {
+			return nil, ErrWebhookNotFound
+		}
+
+		return nil, fmt.Errorf("webhook get: %w", err)
+	}
+
+	return response.NewWebhook(webhook), nil
+}
+
+func (s *Service) List(ctx context.Context) (_ []*response.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.List")
+	defer tracing.End(span, &err)
+
+	list, err := s.storage.Read(ctx).Webhooks.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("webhook list: %w", err)
+	}
+
+	result := make([]*response.Webhook, 0, len(list))
+	for _, webhook := range list {
+		result = append(result, response.NewWebhook(webhook))
+	}
+
+	return result, nil
+}
+
+// Update частично обновляет подписку. Включение подписки сбрасывает счетчик ошибок.
+func (s *Service) Update(ctx context.Context, id uint64, in *request.UpdateWebhook) (_ *response.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Update")
+	defer tracing.End(span, &err)
+
+	webhook, err := s.storage.Webhooks.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, webhooks.ErrNotExists) {
+			return nil, ErrWebhookNotFound
+		}
+
+		return nil, fmt.Errorf("webhook get: %w", err)
+	}
+
+	if in.URL != nil {
+		webhook.URL = *in.URL
+	}
+
+	if in.EventTypes != nil {
+		webhook.EventTypes = *in.EventTypes
+	}
+
+	if in.Secret != nil {
+		webhook.Secret = *in.Secret
+	}
+
+	if in.Enabled != nil {
+		if *in.Enabled && !webhook.Enabled {
+			webhook.Failures = 0
+			webhook.DisabledAt = nil
+		}
+
+		webhook.Enabled = *in.Enabled
+	}
+
+	if webhook, err = s.storage.Webhooks.Update(ctx, webhook); err != nil {
+		if errors.Is(err, webhooks.ErrNotExists) {
+			return nil, ErrWebhookNotFound
+		}
+
+		return nil, fmt.Errorf("webhook update: %w", err)
+	}
+
+	return response.NewWebhook(webhook), nil
+}
+
+func (s *Service) Delete(ctx context.Context, id uint64) (err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Delete")
+	defer tracing.End(span, &err)
+
+	if err := s.storage.Webhooks.Delete(ctx, id); err != nil {
+		if errors.Is(err, webhooks.ErrNotExists) {
+			return ErrWebhookNotFound
+		}
+
+		return fmt.Errorf("webhook delete: %w", err)
+	}
+
+	return nil
+}
+
+// Deliveries возвращает журнал последних доставок подписки.
+func (s *Service) Deliveries(ctx context.Context, id uint64) (_ []*response.Delivery, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Deliveries")
+	defer tracing.End(span, &err)
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	list, err := s.storage.Read(ctx).Webhooks.ListDeliveries(ctx, id, deliveriesLimit)
+	if err != nil {
+		return nil, fmt.Errorf("webhook deliveries: %w", err)
+	}
+
+	result := make([]*response.Delivery, 0, len(list))
+	for _, delivery := range list {
+		result = append(result, response.NewDelivery(delivery))
+	}
+
+	return result, nil
+}
+
+// Redeliver повторно ставит доставку в очередь.
+func (s *Service) Redeliver(ctx context.Context, id, deliveryID uint64) (_ *response.Delivery, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Redeliver")
+	defer tracing.End(span, &err)
+
+	delivery, err := s.storage.Webhooks.Redeliver(ctx, id, deliveryID)
+	if err != nil {
+		if errors.Is(err, webhooks.ErrDeliveryNotExists) {
+			return nil, ErrDeliveryNotFound
+		}
+
+		return nil, fmt.Errorf("webhook redeliver: %w", err)
+	}
+
+	return response.NewDelivery(delivery), nil
+}
@@ -0,0 +1,83 @@
+package tasks
+
+import (
+	"context"
+	"time"
+
+	"service-template/internal/config"
+	"service-template/internal/daemon/jobs"
+	"service-template/internal/db"
+	"service-template/internal/model"
+	"service-template/internal/scheduler"
+	"service-template/internal/worker"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// deletedUsersRetention срок хранения удаленных пользователей.
+	deletedUsersRetention = 30 * 24 * time.Hour
+	// jobsRetention срок хранения выполненных и dead фоновых задач.
+	jobsRetention = 7 * 24 * time.Hour
+)
+
+// New возвращает периодические задачи сервиса. Расписание можно переопределить
+// в секции scheduler.schedules конфигурации.
+func New(cfg *config.Config, log *zerolog.Logger, storage *db.Storage) []scheduler.Task {
+	tasks := []scheduler.Task{
+		{
+			Name:     "jobs.purge",
+			Schedule: "30 3 * * *",
+			Missed:   scheduler.MissedRunOnce,
+			Run: func(ctx context.Context) error {
+				n, err := storage.Jobs.Purge(ctx, []string{model.JobSucceeded, model.JobDead}, time.Now().Add(-jobsRetention))
+				if err != nil {
+					return err
+				}
+
+				log.Info().Int("count", n).Msg("finished jobs purged")
+
+				return nil
+			},
+		},
+		{
+			Name:     "outbox.purge",
+			Schedule: "45 3 * * *",
+			Missed:   scheduler.MissedRunOnce,
+			Run: func(ctx context.Context) error {
+				n, err := storage.Outbox.PurgePublished(ctx, time.Now().Add(-cfg.Outbox.Retention))
+				if err != nil {
+					return err
+				}
+
+				log.Info().Int("count", n).Msg("published events purged")
+
+				return nil
+			},
+		},
+	}
+
+	// Задача только ставит фоновую задачу, которую выполняет обработчик jobs
+	if cfg.Jobs.Enabled {
+		tasks = append(tasks, scheduler.Task{
+			Name:     "users.purge_deleted",
+			Schedule: "0 3 * * *",
+			Missed:   scheduler.MissedRunOnce,
+			Run: func(ctx context.Context) error {
+				// Удаление выполняется фоновой задачей, чтобы получить повторы при ошибках
+				job, err := worker.NewJob(jobs.KindPurgeDeletedUsers, jobs.PurgeDeletedUsers{
+					Retention: jobs.Duration(deletedUsersRetention),
+				}, worker.Options{UniqueKey: jobs.KindPurgeDeletedUsers})
+				if err != nil {
+					return err
+				}
+
+				_, err = storage.Jobs.Enqueue(ctx, job)
+
+				return err
+			},
+		})
+	}
+
+	return tasks
+}
@@ -0,0 +1,139 @@
+// Package dbtest подготавливает базы данных для тестов репозиториев.
+package dbtest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"service-template/migrations"
+	"service-template/pkg/drivers/postgres"
+	"service-template/pkg/drivers/redisdb"
+	"service-template/pkg/drivers/sqlite"
+	"service-template/pkg/migrator"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/uptrace/bun"
+)
+
+// Переменные окружения с адресами тестовых серверов. Данные на них удаляются
+// перед каждым тестом, поэтому они должны указывать на отдельные тестовые базы.
+const (
+	EnvPostgres = "X_TEST_POSTGRES_DSN"
+	EnvRedis    = "X_TEST_REDIS_ADDR"
+)
+
+// SQLite возвращает пустую базу SQLite в памяти с примененными миграциями.
+func SQLite(t testing.TB) *bun.DB {
+	t.Helper()
+
+	cfg := sqlite.NewConfig()
+	cfg.Path = sqlite.Memory
+
+	db, err := sqlite.NewSQLiteDB(cfg)
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	if _, err = migrator.Apply(context.Background(), db, migrations.SQLite); err != nil {
+		t.Fatalf("sqlite migrations: %v", err)
+	}
+
+	return db
+}
+
+// Postgres возвращает базу из X_TEST_POSTGRES_DSN с примененными миграциями и
+// очищенными таблицами. Если переменная не задана, тест пропускается.
+func Postgres(t testing.TB) *bun.DB {
+	t.Helper()
+
+	dsn := os.Getenv(EnvPostgres)
+	if dsn == "" {
+		t.Skipf("%s is not set", EnvPostgres)
+	}
+
+	cfg := postgres.NewConfig()
+	cfg.DSN = dsn
+
+	db, err := postgres.NewPostgresDB(cfg)
+	if err != nil {
+		t.Fatalf("postgres: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	if _, err = migrator.Apply(ctx, db, migrations.FS); err != nil {
+		t.Fatalf("postgres migrations: %v", err)
+	}
+
+	var tables []string
+	err = db.NewRaw("SELECT tablename FROM pg_tables WHERE schemaname = current_schema() AND tablename NOT LIKE 'bun_%'").
+		Scan(ctx, &tables)
+	if err != nil {
+		t.Fatalf("postgres tables: %v", err)
+	}
+
+	if len(tables) > 0 {
+		if _, err = db.NewRaw("TRUNCATE ? RESTART IDENTITY CASCADE", bun.In(bunIdents(tables))).Exec(ctx); err != nil {
+			t.Fatalf("postgres truncate: %v", err)
+		}
+	}
+
+	return db
+}
+
+// Redis возвращает клиент встроенного сервера Redis.
+func Redis(t testing.TB) redis.UniversalClient {
+	t.Helper()
+
+	cfg := redisdb.NewConfig()
+	cfg.Mode = redisdb.ModeMemory
+
+	rdb, err := redisdb.NewRedisDB(cfg)
+	if err != nil {
+		t.Fatalf("redis: %v", err)
+	}
+
+	t.Cleanup(func() { rdb.Close() })
+
+	return rdb
+}
+
+// RedisServer возвращает клиент Redis из X_TEST_REDIS_ADDR с очищенной базой.
+// Если переменная не задана, тест пропускается.
+func RedisServer(t testing.TB) redis.UniversalClient {
+	t.Helper()
+
+	addr := os.Getenv(EnvRedis)
+	if addr == "" {
+		t.Skipf("%s is not set", EnvRedis)
+	}
+
+	cfg := redisdb.NewConfig()
+	cfg.Addr = addr
+
+	rdb, err := redisdb.NewRedisDB(cfg)
+	if err != nil {
+		t.Fatalf("redis: %v", err)
+	}
+
+	t.Cleanup(func() { rdb.Close() })
+
+	if err = rdb.FlushDB(context.Background()).Err(); err != nil {
+		t.Fatalf("redis flush: %v", err)
+	}
+
+	return rdb
+}
+
+func bunIdents(names []string) []bun.Ident {
+	idents := make([]bun.Ident, 0, len(names))
+	for _, name := range names {
+		idents = append(idents, bun.Ident(name))
+	}
+
+	return idents
+}
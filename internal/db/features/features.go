@@ -0,0 +1,129 @@
+package features
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"service-template/internal/model"
+	"service-template/pkg/flags"
+	"service-template/pkg/txmanager"
+
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrNotExists = fmt.Errorf("feature flag not exists")
+	ErrExists    = fmt.Errorf("feature flag already exists")
+	ErrConflict  = fmt.Errorf("feature flag version conflict")
+)
+
+type Storage struct {
+	db bun.IDB
+}
+
+func NewStorage(db bun.IDB) *Storage {
+	return &Storage{
+		db: db,
+	}
+}
+
+func (s *Storage) Create(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	if flag.Rules == nil {
+		flag.Rules = []flags.Rule{}
+	}
+
+	res, err := txmanager.DB(ctx, s.db).NewInsert().Model(flag).
+		On("CONFLICT (key) DO NOTHING").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrExists
+	}
+
+	return flag, nil
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (*model.FeatureFlag, error) {
+	flag := model.FeatureFlag{}
+
+	if err := txmanager.DB(ctx, s.db).NewSelect().Model(&flag).Where("key = ?", key).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotExists
+		}
+
+		return nil, err
+	}
+
+	return &flag, nil
+}
+
+func (s *Storage) List(ctx context.Context) ([]*model.FeatureFlag, error) {
+	var list []*model.FeatureFlag
+
+	if err := txmanager.DB(ctx, s.db).NewSelect().Model(&list).Order("key").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Update обновляет флаг, если версия в БД совпадает с flag.Version.
+// Возвращает ErrNotExists или ErrConflict.
+func (s *Storage) Update(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	now := time.Now()
+	flag.UpdatedAt = &now
+
+	if flag.Rules == nil {
+		flag.Rules = []flags.Rule{}
+	}
+
+	res, err := txmanager.DB(ctx, s.db).NewUpdate().Model(flag).
+		Column("description", "enabled", "rules", "updated_at", "version").
+		Value("version", "version + 1").
+		WherePK().
+		Where("version = ?", flag.Version).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		// Различаем отсутствие флага и конфликт версий
+		if exists, err := txmanager.DB(ctx, s.db).NewSelect().Model((*model.FeatureFlag)(nil)).Where("id = ?", flag.ID).Exists(ctx); err != nil {
+			return nil, err
+		} else if !exists {
+			return nil, ErrNotExists
+		}
+
+		return nil, ErrConflict
+	}
+
+	return flag, nil
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	res, err := txmanager.DB(ctx, s.db).NewDelete().Model((*model.FeatureFlag)(nil)).Where("key = ?", key).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotExists
+	}
+
+	return nil
+}
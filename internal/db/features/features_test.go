@@ -0,0 +1,103 @@
+package features_test
+
+import (
+	"context"
+	"testing"
+
+	"service-template/internal/db/dbtest"
+	"service-template/internal/db/features"
+	"service-template/internal/model"
+	"service-template/pkg/flags"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestStorage_sqlite(t *testing.T) {
+	run(t, dbtest.SQLite)
+}
+
+func TestStorage_postgres(t *testing.T) {
+	run(t, dbtest.Postgres)
+}
+
+func run(t *testing.T, open func(t testing.TB) *bun.DB) {
+	tests := map[string]func(t *testing.T, storage *features.Storage){
+		"crud":     testCRUD,
+		"conflict": testConflict,
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			test(t, features.NewStorage(open(t)))
+		})
+	}
+}
+
+func testCRUD(t *testing.T, storage *features.Storage) {
+	ctx := context.Background()
+
+	flag, err := storage.Create(ctx, &model.FeatureFlag{Key: "feature", Enabled: true})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, flag.Version)
+	assert.Equal(t, []flags.Rule{}, flag.Rules)
+
+	_, err = storage.Create(ctx, &model.FeatureFlag{Key: "feature"})
+	assert.ErrorIs(t, err, features.ErrExists)
+
+	got, err := storage.Get(ctx, "feature")
+	require.NoError(t, err)
+	assert.True(t, got.Enabled)
+
+	got.Enabled = false
+	got.Description = "disabled"
+
+	updated, err := storage.Update(ctx, got)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, updated.Version)
+	assert.NotNil(t, updated.UpdatedAt)
+
+	list, err := storage.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.False(t, list[0].Enabled)
+	assert.Equal(t, "disabled", list[0].Description)
+
+	require.NoError(t, storage.Delete(ctx, "feature"))
+	assert.ErrorIs(t, storage.Delete(ctx, "feature"), features.ErrNotExists)
+
+	_, err = storage.Get(ctx, "feature")
+	assert.ErrorIs(t, err, features.ErrNotExists)
+
+	_, err = storage.Update(ctx, updated)
+	assert.ErrorIs(t, err, features.ErrNotExists)
+}
+
+func testConflict(t *testing.T, storage *features.Storage) {
+	ctx := context.Background()
+
+	_, err := storage.Create(ctx, &model.FeatureFlag{Key: "feature"})
+	require.NoError(t, err)
+
+	first, err := storage.Get(ctx, "feature")
+	require.NoError(t, err)
+
+	second, err := storage.Get(ctx, "feature")
+	require.NoError(t, err)
+
+	first.Enabled = true
+	_, err = storage.Update(ctx, first)
+	require.NoError(t, err)
+
+	// Изменение по устаревшей версии не перезаписывает предыдущее
+	second.Description = "stale"
+	_, err = storage.Update(ctx, second)
+	assert.ErrorIs(t, err, features.ErrConflict)
+
+	got, err := storage.Get(ctx, "feature")
+	require.NoError(t, err)
+	assert.True(t, got.Enabled)
+	assert.Empty(t, got.Description)
+}
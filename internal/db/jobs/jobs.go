@@ -0,0 +1,268 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"service-template/internal/model"
+	"service-template/pkg/drivers/sqlite"
+	"service-template/pkg/txmanager"
+
+	"github.com/uptrace/bun"
+)
+
+// ErrLockLost задача не выполняется обработчиком: блокировка истекла, и задача
+// возвращена в очередь или взята другим обработчиком.
+var ErrLockLost = errors.New("job lock lost")
+
+// DefaultMaxAttempts количество попыток задачи, для которой оно не задано.
+const DefaultMaxAttempts = 5
+
+type Storage struct {
+	db bun.IDB
+}
+
+func NewStorage(db bun.IDB) *Storage {
+	return &Storage{
+		db: db,
+	}
+}
+
+// Enqueue ставит задачу в очередь. Если у задачи задан UniqueKey и задача того же типа
+// с тем же ключом еще не завершена, новая задача не создается и возвращается false.
+func (s *Storage) Enqueue(ctx context.Context, job *model.Job) (bool, error) {
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+
+	// Явно переданный NULL не заменяется значением колонки по умолчанию
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+
+	job.Status = model.JobPending
+
+	res, err := txmanager.DB(ctx, s.db).NewInsert().Model(job).
+		Column("kind", "payload", "status", "unique_key", "max_attempts", "run_at").
+		On("CONFLICT (kind, unique_key) WHERE unique_key IS NOT NULL AND status IN (?, ?) DO NOTHING",
+			model.JobPending, model.JobRunning).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return n > 0, err
+}
+
+// Fetch блокирует готовые к выполнению задачи указанных типов, переводит их
+// в статус выполнения и увеличивает счетчик попыток.
+func (s *Storage) Fetch(ctx context.Context, kinds []string, limit int, worker string) ([]*model.Job, error) {
+	var list []*model.Job
+
+	ready := txmanager.DB(ctx, s.db).NewSelect().Model((*model.Job)(nil)).
+		Column("id").
+		Where("status = ?", model.JobPending).
+		Where("run_at <= ?", time.Now()).
+		Where("kind IN (?)", bun.In(kinds)).
+		OrderExpr("run_at, id").
+		Limit(limit)
+
+	// SQLite не блокирует строки: транзакции записи выполняются по одной
+	if !sqlite.Is(s.db) {
+		ready = ready.For("UPDATE SKIP LOCKED")
+	}
+
+	_, err := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.Job)(nil)).
+		Set("status = ?", model.JobRunning).
+		Set("attempts = attempts + 1").
+		Set("locked_at = ?", time.Now()).
+		Set("locked_by = ?", worker).
+		Where("id IN (?)", ready).
+		Returning("*").
+		Exec(ctx, &list)
+
+	return list, err
+}
+
+// Touch продлевает блокировку выполняемых задач.
+func (s *Storage) Touch(ctx context.Context, ids []uint64, worker string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.Job)(nil)).
+		Set("locked_at = ?", time.Now()).
+		Where("id IN (?)", bun.In(ids)).
+		Where("status = ?", model.JobRunning).
+		Where("locked_by = ?", worker).
+		Exec(ctx)
+
+	return err
+}
+
+// Complete отмечает задачу успешно выполненной. Возвращает ErrLockLost,
+// если задача не заблокирована обработчиком worker.
+func (s *Storage) Complete(ctx context.Context, id uint64, worker string) error {
+	query := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.Job)(nil)).
+		Set("status = ?", model.JobSucceeded).
+		Set("finished_at = ?", time.Now()).
+		Set("locked_at = NULL").
+		Set("last_error = NULL")
+
+	return s.finish(ctx, query, id, worker)
+}
+
+// Retry возвращает задачу в очередь с выполнением не раньше runAt. Возвращает ErrLockLost,
+// если задача не заблокирована обработчиком worker.
+func (s *Storage) Retry(ctx context.Context, id uint64, worker string, runAt time.Time, reason string) error {
+	query := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.Job)(nil)).
+		Set("status = ?", model.JobPending).
+		Set("run_at = ?", runAt).
+		Set("locked_at = NULL").
+		Set("last_error = ?", reason)
+
+	return s.finish(ctx, query, id, worker)
+}
+
+// Bury переводит задачу в статус dead, после чего она выполняется только по команде retry.
+// Возвращает ErrLockLost, если задача не заблокирована обработчиком worker.
+func (s *Storage) Bury(ctx context.Context, id uint64, worker string, reason string) error {
+	query := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.Job)(nil)).
+		Set("status = ?", model.JobDead).
+		Set("finished_at = ?", time.Now()).
+		Set("locked_at = NULL").
+		Set("last_error = ?", reason)
+
+	return s.finish(ctx, query, id, worker)
+}
+
+// finish обновляет задачу, только если она еще выполняется обработчиком worker.
+func (s *Storage) finish(ctx context.Context, query *bun.UpdateQuery, id uint64, worker string) error {
+	res, err := query.
+		Where("id = ?", id).
+		Where("status = ?", model.JobRunning).
+		Where("locked_by = ?", worker).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+// Rescue возвращает в очередь задачи, блокировка которых не продлевалась дольше timeout,
+// например, из-за падения обработчика. Задачи, исчерпавшие попытки, переводятся в статус dead.
+// Возвращает количество возвращенных и переведенных в dead задач.
+func (s *Storage) Rescue(ctx context.Context, timeout time.Duration) (requeued int, buried int, err error) {
+	const reason = "worker lost"
+
+	expired := time.Now().Add(-timeout)
+
+	res, err := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.Job)(nil)).
+		Set("status = ?", model.JobDead).
+		Set("finished_at = ?", time.Now()).
+		Set("locked_at = NULL").
+		Set("last_error = ?", reason).
+		Where("status = ?", model.JobRunning).
+		Where("locked_at < ?", expired).
+		Where("attempts >= max_attempts").
+		Exec(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	buried = int(n)
+
+	res, err = txmanager.DB(ctx, s.db).NewUpdate().Model((*model.Job)(nil)).
+		Set("status = ?", model.JobPending).
+		Set("run_at = ?", time.Now()).
+		Set("locked_at = NULL").
+		Set("last_error = ?", reason).
+		Where("status = ?", model.JobRunning).
+		Where("locked_at < ?", expired).
+		Exec(ctx)
+	if err != nil {
+		return 0, buried, err
+	}
+
+	n, err = res.RowsAffected()
+
+	return int(n), buried, err
+}
+
+// List возвращает последние задачи. Пустые status и kind не ограничивают выборку.
+func (s *Storage) List(ctx context.Context, status, kind string, limit int) ([]*model.Job, error) {
+	var list []*model.Job
+
+	query := txmanager.DB(ctx, s.db).NewSelect().Model(&list).OrderExpr("id DESC").Limit(limit)
+
+	if status != "" {
+		query.Where("status = ?", status)
+	}
+
+	if kind != "" {
+		query.Where("kind = ?", kind)
+	}
+
+	err := query.Scan(ctx)
+
+	return list, err
+}
+
+// Requeue возвращает dead задачи в очередь со сброшенным счетчиком попыток.
+// Если ids пуст, в очередь возвращаются все dead задачи. Возвращает количество задач.
+func (s *Storage) Requeue(ctx context.Context, ids []uint64) (int, error) {
+	query := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.Job)(nil)).
+		Set("status = ?", model.JobPending).
+		Set("attempts = 0").
+		Set("run_at = ?", time.Now()).
+		Set("finished_at = NULL").
+		Where("status = ?", model.JobDead)
+
+	if len(ids) > 0 {
+		query.Where("id IN (?)", bun.In(ids))
+	}
+
+	res, err := query.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return int(n), err
+}
+
+// Purge удаляет завершенные задачи со статусом из statuses, завершившиеся раньше before.
+// Возвращает количество удаленных задач.
+func (s *Storage) Purge(ctx context.Context, statuses []string, before time.Time) (int, error) {
+	res, err := txmanager.DB(ctx, s.db).NewDelete().Model((*model.Job)(nil)).
+		Where("status IN (?)", bun.In(statuses)).
+		Where("finished_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return int(n), err
+}
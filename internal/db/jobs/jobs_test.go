@@ -0,0 +1,185 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service-template/internal/db/dbtest"
+	"service-template/internal/db/jobs"
+	"service-template/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestStorage_sqlite(t *testing.T) {
+	run(t, dbtest.SQLite)
+}
+
+func TestStorage_postgres(t *testing.T) {
+	run(t, dbtest.Postgres)
+}
+
+func run(t *testing.T, open func(t testing.TB) *bun.DB) {
+	tests := map[string]func(t *testing.T, storage *jobs.Storage){
+		"fetch":  testFetch,
+		"finish": testFinish,
+		"rescue": testRescue,
+		"purge":  testPurge,
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			test(t, jobs.NewStorage(open(t)))
+		})
+	}
+}
+
+func enqueue(t *testing.T, storage *jobs.Storage, maxAttempts int) *model.Job {
+	t.Helper()
+
+	job := &model.Job{Kind: "test", Payload: []byte("{}"), MaxAttempts: maxAttempts}
+
+	ok, err := storage.Enqueue(context.Background(), job)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	return job
+}
+
+func fetch(t *testing.T, storage *jobs.Storage, worker string) []*model.Job {
+	t.Helper()
+
+	list, err := storage.Fetch(context.Background(), []string{"test"}, 10, worker)
+	require.NoError(t, err)
+
+	return list
+}
+
+func get(t *testing.T, storage *jobs.Storage, id uint64) *model.Job {
+	t.Helper()
+
+	list, err := storage.List(context.Background(), "", "", 100)
+	require.NoError(t, err)
+
+	for _, job := range list {
+		if job.ID == id {
+			return job
+		}
+	}
+
+	t.Fatalf("job %d not found", id)
+
+	return nil
+}
+
+func testFetch(t *testing.T, storage *jobs.Storage) {
+	ctx := context.Background()
+
+	job := enqueue(t, storage, 3)
+
+	delayed := &model.Job{Kind: "test", Payload: []byte("{}"), RunAt: time.Now().Add(time.Hour)}
+	_, err := storage.Enqueue(ctx, delayed)
+	require.NoError(t, err)
+
+	list := fetch(t, storage, "worker-1")
+	require.Len(t, list, 1)
+	assert.Equal(t, job.ID, list[0].ID)
+	assert.Equal(t, model.JobRunning, list[0].Status)
+	assert.Equal(t, 1, list[0].Attempts)
+	assert.Equal(t, "worker-1", list[0].LockedBy)
+
+	assert.Empty(t, fetch(t, storage, "worker-2"), "running job is not fetched again")
+}
+
+func testFinish(t *testing.T, storage *jobs.Storage) {
+	ctx := context.Background()
+
+	completed := enqueue(t, storage, 3)
+	retried := enqueue(t, storage, 3)
+	buried := enqueue(t, storage, 3)
+	fetch(t, storage, "worker-1")
+
+	// Задачи, заблокированные другим обработчиком, не изменяются
+	assert.ErrorIs(t, storage.Complete(ctx, completed.ID, "worker-2"), jobs.ErrLockLost)
+	assert.ErrorIs(t, storage.Retry(ctx, retried.ID, "worker-2", time.Now(), "failed"), jobs.ErrLockLost)
+	assert.ErrorIs(t, storage.Bury(ctx, buried.ID, "worker-2", "failed"), jobs.ErrLockLost)
+
+	require.NoError(t, storage.Complete(ctx, completed.ID, "worker-1"))
+	require.NoError(t, storage.Retry(ctx, retried.ID, "worker-1", time.Now().Add(time.Hour), "failed"))
+	require.NoError(t, storage.Bury(ctx, buried.ID, "worker-1", "fatal"))
+
+	job := get(t, storage, completed.ID)
+	assert.Equal(t, model.JobSucceeded, job.Status)
+	assert.NotNil(t, job.FinishedAt)
+
+	job = get(t, storage, retried.ID)
+	assert.Equal(t, model.JobPending, job.Status)
+	assert.Equal(t, "failed", job.LastError)
+
+	job = get(t, storage, buried.ID)
+	assert.Equal(t, model.JobDead, job.Status)
+	assert.Equal(t, "fatal", job.LastError)
+
+	// Повторное завершение не изменяет результат
+	assert.ErrorIs(t, storage.Complete(ctx, buried.ID, "worker-1"), jobs.ErrLockLost)
+	assert.Equal(t, model.JobDead, get(t, storage, buried.ID).Status)
+}
+
+func testRescue(t *testing.T, storage *jobs.Storage) {
+	ctx := context.Background()
+
+	lost := enqueue(t, storage, 3)
+	exhausted := enqueue(t, storage, 1)
+	fetch(t, storage, "worker-1")
+
+	requeued, buried, err := storage.Rescue(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, requeued)
+	assert.Zero(t, buried)
+
+	requeued, buried, err = storage.Rescue(ctx, -time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+	assert.Equal(t, 1, buried)
+
+	job := get(t, storage, lost.ID)
+	assert.Equal(t, model.JobPending, job.Status)
+	assert.Equal(t, "worker lost", job.LastError)
+
+	job = get(t, storage, exhausted.ID)
+	assert.Equal(t, model.JobDead, job.Status, "job with exhausted attempts is not requeued")
+	assert.NotNil(t, job.FinishedAt)
+
+	// Потерянный обработчик не может завершить возвращенную задачу
+	assert.ErrorIs(t, storage.Complete(ctx, lost.ID, "worker-1"), jobs.ErrLockLost)
+}
+
+func testPurge(t *testing.T, storage *jobs.Storage) {
+	ctx := context.Background()
+
+	completed := enqueue(t, storage, 3)
+	buried := enqueue(t, storage, 3)
+	pending := enqueue(t, storage, 3)
+	fetch(t, storage, "worker-1")
+
+	require.NoError(t, storage.Complete(ctx, completed.ID, "worker-1"))
+	require.NoError(t, storage.Bury(ctx, buried.ID, "worker-1", "fatal"))
+	require.NoError(t, storage.Retry(ctx, pending.ID, "worker-1", time.Now(), "failed"))
+
+	n, err := storage.Purge(ctx, []string{model.JobSucceeded}, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n, "recently finished jobs are kept")
+
+	n, err = storage.Purge(ctx, []string{model.JobSucceeded, model.JobDead}, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	list, err := storage.List(ctx, "", "", 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, pending.ID, list[0].ID)
+}
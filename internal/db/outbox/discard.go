@@ -0,0 +1,27 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"service-template/internal/events"
+	"service-template/internal/model"
+)
+
+// Discard отбрасывает события. Используется, когда outbox выключен: сохраненные события
+// некому публиковать, и таблица росла бы без ограничений.
+type Discard struct{}
+
+func (Discard) Add(context.Context, ...*events.Event) error { return nil }
+
+func (Discard) AddUser(context.Context, events.Type, *model.User) error { return nil }
+
+func (Discard) Pending(context.Context, int) ([]*model.OutboxEvent, error) { return nil, nil }
+
+func (Discard) Claim(context.Context, int, time.Time) ([]*model.OutboxEvent, error) { return nil, nil }
+
+func (Discard) MarkPublished(context.Context, uint64) error { return nil }
+
+func (Discard) MarkFailed(context.Context, uint64, time.Time, string) error { return nil }
+
+func (Discard) PurgePublished(context.Context, time.Time) (int, error) { return 0, nil }
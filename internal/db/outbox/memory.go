@@ -0,0 +1,168 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"service-template/internal/events"
+	"service-template/internal/model"
+	"service-template/pkg/cache"
+)
+
+// Memory хранилище событий в памяти процесса для тестов.
+type Memory struct {
+	mu     sync.Mutex
+	seq    uint64
+	events *cache.Cache[uint64, model.OutboxEvent]
+}
+
+func NewMemoryStorage() *Memory {
+	return &Memory{
+		events: cache.NewCache[uint64, model.OutboxEvent](),
+	}
+}
+
+// Add сохраняет события. Как и в SQL БД, события с уже сохраненным ID не сохраняются вовсе.
+func (m *Memory) Add(_ context.Context, list ...*events.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make(map[string]bool, len(list))
+	for _, row := range m.events.ToList() {
+		ids[row.EventID] = true
+	}
+
+	for _, event := range list {
+		if ids[event.ID] {
+			return fmt.Errorf("event %s already exists", event.ID)
+		}
+
+		ids[event.ID] = true
+	}
+
+	for _, event := range list {
+		m.seq++
+		m.events.Set(m.seq, model.OutboxEvent{
+			ID:            m.seq,
+			EventID:       event.ID,
+			Type:          string(event.Type),
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			Payload:       append([]byte(nil), event.Payload...),
+			OccurredAt:    event.OccurredAt,
+			NextAttemptAt: event.OccurredAt,
+		})
+	}
+
+	return nil
+}
+
+func (m *Memory) AddUser(ctx context.Context, typ events.Type, user *model.User) error {
+	event, err := events.NewUser(typ, events.User{ID: user.ID, Email: user.Email, Phone: user.Phone})
+	if err != nil {
+		return err
+	}
+
+	return m.Add(ctx, event)
+}
+
+// Pending возвращает события, готовые к публикации, по одному самому раннему на агрегат.
+func (m *Memory) Pending(_ context.Context, limit int) ([]*model.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := m.events.ToList()
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	now := time.Now()
+	seen := make(map[[2]string]bool)
+	rows := make([]*model.OutboxEvent, 0, limit)
+
+	for i := range list {
+		row := list[i]
+		if row.PublishedAt != nil {
+			continue
+		}
+
+		aggregate := [2]string{row.AggregateType, row.AggregateID}
+		if seen[aggregate] {
+			continue
+		}
+		seen[aggregate] = true
+
+		if row.NextAttemptAt.After(now) {
+			continue
+		}
+
+		row.Payload = append([]byte(nil), row.Payload...)
+		rows = append(rows, &row)
+
+		if len(rows) == limit {
+			break
+		}
+	}
+
+	return rows, nil
+}
+
+func (m *Memory) Claim(ctx context.Context, limit int, until time.Time) ([]*model.OutboxEvent, error) {
+	rows, err := m.Pending(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		_ = m.update(row.ID, func(row *model.OutboxEvent) {
+			row.NextAttemptAt = until
+		})
+	}
+
+	return rows, nil
+}
+
+func (m *Memory) MarkPublished(_ context.Context, id uint64) error {
+	return m.update(id, func(row *model.OutboxEvent) {
+		now := time.Now()
+		row.PublishedAt = &now
+		row.LastError = ""
+	})
+}
+
+func (m *Memory) MarkFailed(_ context.Context, id uint64, next time.Time, reason string) error {
+	return m.update(id, func(row *model.OutboxEvent) {
+		row.Attempts++
+		row.NextAttemptAt = next
+		row.LastError = reason
+	})
+}
+
+func (m *Memory) PurgePublished(_ context.Context, before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for _, row := range m.events.ToList() {
+		if row.PublishedAt != nil && row.PublishedAt.Before(before) {
+			m.events.Delete(row.ID)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// update изменяет событие, если оно есть. Отсутствующее событие, как и в SQL БД, не ошибка.
+func (m *Memory) update(id uint64, fn func(row *model.OutboxEvent)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if row, ok := m.events.Get(id); ok {
+		fn(&row)
+		m.events.Set(id, row)
+	}
+
+	return nil
+}
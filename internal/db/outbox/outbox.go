@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"service-template/internal/events"
+	"service-template/internal/model"
+	"service-template/pkg/drivers/sqlite"
+	"service-template/pkg/txmanager"
+
+	"github.com/uptrace/bun"
+)
+
+// Repository хранилище событий, ожидающих публикации.
+type Repository interface {
+	Add(ctx context.Context, list ...*events.Event) error
+	AddUser(ctx context.Context, typ events.Type, user *model.User) error
+	Pending(ctx context.Context, limit int) ([]*model.OutboxEvent, error)
+	// Claim возвращает события, готовые к публикации, и откладывает их следующую попытку до until,
+	// чтобы другие экземпляры не публиковали их одновременно. В SQL БД вызывается внутри транзакции.
+	Claim(ctx context.Context, limit int, until time.Time) ([]*model.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uint64) error
+	MarkFailed(ctx context.Context, id uint64, next time.Time, reason string) error
+	// PurgePublished удаляет события, опубликованные раньше before, и возвращает их количество.
+	PurgePublished(ctx context.Context, before time.Time) (int, error)
+}
+
+// Storage хранилище событий в SQL БД.
+type Storage struct {
+	db bun.IDB
+}
+
+func NewStorage(db bun.IDB) *Storage {
+	return &Storage{
+		db: db,
+	}
+}
+
+// Add сохраняет события. Чтобы события были записаны атомарно с изменением состояния,
+// ctx должен содержать транзакцию, см. Storage.WithinTx.
+func (s *Storage) Add(ctx context.Context, list ...*events.Event) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	rows := make([]*model.OutboxEvent, 0, len(list))
+	for _, event := range list {
+		rows = append(rows, &model.OutboxEvent{
+			EventID:       event.ID,
+			Type:          string(event.Type),
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			Payload:       event.Payload,
+			OccurredAt:    event.OccurredAt,
+			NextAttemptAt: event.OccurredAt,
+		})
+	}
+
+	_, err := txmanager.DB(ctx, s.db).NewInsert().Model(&rows).Exec(ctx)
+
+	return err
+}
+
+// AddUser сохраняет событие пользователя.
+func (s *Storage) AddUser(ctx context.Context, typ events.Type, user *model.User) error {
+	event, err := events.NewUser(typ, events.User{ID: user.ID, Email: user.Email, Phone: user.Phone})
+	if err != nil {
+		return err
+	}
+
+	return s.Add(ctx, event)
+}
+
+// Pending блокирует и возвращает события, готовые к публикации.
+// Для каждого агрегата возвращается только самое раннее неопубликованное событие,
+// чтобы сохранить порядок публикации. Должен вызываться внутри транзакции.
+func (s *Storage) Pending(ctx context.Context, limit int) ([]*model.OutboxEvent, error) {
+	var rows []*model.OutboxEvent
+
+	query := txmanager.DB(ctx, s.db).NewSelect().Model(&rows).
+		Where("o.published_at IS NULL").
+		Where("o.next_attempt_at <= ?", time.Now()).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM outbox AS p
+			WHERE p.aggregate_type = o.aggregate_type AND p.aggregate_id = o.aggregate_id
+				AND p.published_at IS NULL AND p.id < o.id
+		)`).
+		OrderExpr("o.id").
+		Limit(limit)
+
+	// FOR UPDATE не поддерживается SQLite, транзакции там и так выполняются по одной
+	if !sqlite.Is(s.db) {
+		query = query.For("UPDATE SKIP LOCKED")
+	}
+
+	err := query.Scan(ctx)
+
+	return rows, err
+}
+
+func (s *Storage) Claim(ctx context.Context, limit int, until time.Time) ([]*model.OutboxEvent, error) {
+	rows, err := s.Pending(ctx, limit)
+	if err != nil || len(rows) == 0 {
+		return rows, err
+	}
+
+	ids := make([]uint64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	_, err = txmanager.DB(ctx, s.db).NewUpdate().Model((*model.OutboxEvent)(nil)).
+		Set("next_attempt_at = ?", until).
+		Where("id IN (?)", bun.In(ids)).
+		Exec(ctx)
+
+	return rows, err
+}
+
+// MarkPublished отмечает событие опубликованным.
+func (s *Storage) MarkPublished(ctx context.Context, id uint64) error {
+	_, err := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.OutboxEvent)(nil)).
+		Set("published_at = ?", time.Now()).
+		Set("last_error = NULL").
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return err
+}
+
+// MarkFailed сохраняет ошибку публикации и время следующей попытки.
+func (s *Storage) MarkFailed(ctx context.Context, id uint64, next time.Time, reason string) error {
+	_, err := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.OutboxEvent)(nil)).
+		Set("attempts = attempts + 1").
+		Set("next_attempt_at = ?", next).
+		Set("last_error = ?", reason).
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return err
+}
+
+// PurgePublished удаляет события, опубликованные раньше before.
+func (s *Storage) PurgePublished(ctx context.Context, before time.Time) (int, error) {
+	res, err := txmanager.DB(ctx, s.db).NewDelete().Model((*model.OutboxEvent)(nil)).
+		Where("published_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return int(n), err
+}
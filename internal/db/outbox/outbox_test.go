@@ -0,0 +1,27 @@
+package outbox_test
+
+import (
+	"testing"
+
+	"service-template/internal/db/dbtest"
+	"service-template/internal/db/outbox"
+	"service-template/internal/db/outbox/outboxtest"
+)
+
+func TestStorage_sqlite(t *testing.T) {
+	outboxtest.Run(t, func(t *testing.T) outbox.Repository {
+		return outbox.NewStorage(dbtest.SQLite(t))
+	})
+}
+
+func TestStorage_postgres(t *testing.T) {
+	outboxtest.Run(t, func(t *testing.T) outbox.Repository {
+		return outbox.NewStorage(dbtest.Postgres(t))
+	})
+}
+
+func TestMemory(t *testing.T) {
+	outboxtest.Run(t, func(t *testing.T) outbox.Repository {
+		return outbox.NewMemoryStorage()
+	})
+}
@@ -0,0 +1,237 @@
+// Package outboxtest проверяет, что реализации outbox.Repository ведут себя одинаково.
+package outboxtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service-template/internal/db/outbox"
+	"service-template/internal/events"
+	"service-template/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run запускает общие тесты хранилища. newRepo возвращает пустое хранилище для каждого теста.
+func Run(t *testing.T, newRepo func(t *testing.T) outbox.Repository) {
+	tests := map[string]func(t *testing.T, repo outbox.Repository){
+		"add":       testAdd,
+		"duplicate": testDuplicate,
+		"order":     testOrder,
+		"failed":    testFailed,
+		"limit":     testLimit,
+		"claim":     testClaim,
+		"purge":     testPurge,
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			test(t, newRepo(t))
+		})
+	}
+}
+
+func newEvent(t *testing.T, typ events.Type, userID uint64) *events.Event {
+	event, err := events.NewUser(typ, events.User{ID: userID, Email: "user@example.com"})
+	require.NoError(t, err)
+
+	// Время события в прошлом, чтобы оно сразу было готово к публикации
+	event.OccurredAt = event.OccurredAt.Add(-time.Second)
+
+	return event
+}
+
+func ids(rows []*model.OutboxEvent) []string {
+	list := make([]string, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, row.EventID)
+	}
+
+	return list
+}
+
+func testAdd(t *testing.T, repo outbox.Repository) {
+	ctx := context.Background()
+
+	require.NoError(t, repo.Add(ctx))
+
+	event := newEvent(t, events.UserRegistered, 1)
+	require.NoError(t, repo.Add(ctx, event))
+	require.NoError(t, repo.AddUser(ctx, events.UserSignedIn, &model.User{ID: 2, Email: "other@example.com"}))
+
+	rows, err := repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	row := rows[0]
+	assert.NotZero(t, row.ID)
+	assert.Equal(t, event.ID, row.EventID)
+	assert.Equal(t, string(events.UserRegistered), row.Type)
+	assert.Equal(t, events.AggregateUser, row.AggregateType)
+	assert.Equal(t, "1", row.AggregateID)
+	assert.JSONEq(t, string(event.Payload), string(row.Payload))
+	assert.WithinDuration(t, event.OccurredAt, row.OccurredAt, time.Millisecond)
+	assert.Nil(t, row.PublishedAt)
+	assert.Zero(t, row.Attempts)
+	assert.Empty(t, row.LastError)
+
+	assert.Equal(t, string(events.UserSignedIn), rows[1].Type)
+	assert.Equal(t, "2", rows[1].AggregateID)
+	assert.Greater(t, rows[1].ID, row.ID)
+
+	require.NoError(t, repo.MarkPublished(ctx, row.ID))
+
+	rows, err = repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "2", rows[0].AggregateID)
+}
+
+func testDuplicate(t *testing.T, repo outbox.Repository) {
+	ctx := context.Background()
+
+	first := newEvent(t, events.UserRegistered, 1)
+	require.NoError(t, repo.Add(ctx, first))
+
+	// События сохраняются все или ни одного
+	second := newEvent(t, events.UserSignedIn, 2)
+	assert.Error(t, repo.Add(ctx, second, first))
+
+	rows, err := repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID}, ids(rows))
+}
+
+func testOrder(t *testing.T, repo outbox.Repository) {
+	ctx := context.Background()
+
+	first := newEvent(t, events.UserRegistered, 1)
+	second := newEvent(t, events.UserSignedIn, 1)
+	other := newEvent(t, events.UserRegistered, 2)
+	require.NoError(t, repo.Add(ctx, first, second, other))
+
+	// Следующее событие агрегата возвращается только после публикации предыдущего
+	rows, err := repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID, other.ID}, ids(rows))
+
+	require.NoError(t, repo.MarkPublished(ctx, rows[0].ID))
+	require.NoError(t, repo.MarkPublished(ctx, rows[1].ID))
+
+	rows, err = repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.ID}, ids(rows))
+
+	require.NoError(t, repo.MarkPublished(ctx, rows[0].ID))
+
+	rows, err = repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+}
+
+func testFailed(t *testing.T, repo outbox.Repository) {
+	ctx := context.Background()
+
+	first := newEvent(t, events.UserRegistered, 1)
+	second := newEvent(t, events.UserSignedIn, 1)
+	require.NoError(t, repo.Add(ctx, first, second))
+
+	rows, err := repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	id := rows[0].ID
+
+	// Пока не наступило время следующей попытки, события агрегата не возвращаются
+	require.NoError(t, repo.MarkFailed(ctx, id, time.Now().Add(time.Hour), "broker unavailable"))
+
+	rows, err = repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	require.NoError(t, repo.MarkFailed(ctx, id, time.Now().Add(-time.Second), "timeout"))
+
+	rows, err = repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, first.ID, rows[0].EventID)
+	assert.Equal(t, 2, rows[0].Attempts)
+	assert.Equal(t, "timeout", rows[0].LastError)
+
+	require.NoError(t, repo.MarkPublished(ctx, id))
+
+	rows, err = repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.ID}, ids(rows))
+}
+
+func testLimit(t *testing.T, repo outbox.Repository) {
+	ctx := context.Background()
+
+	var added []string
+	for i := uint64(1); i <= 5; i++ {
+		event := newEvent(t, events.UserRegistered, i)
+		require.NoError(t, repo.Add(ctx, event))
+		added = append(added, event.ID)
+	}
+
+	rows, err := repo.Pending(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, added[:3], ids(rows))
+}
+
+func testClaim(t *testing.T, repo outbox.Repository) {
+	ctx := context.Background()
+
+	first := newEvent(t, events.UserRegistered, 1)
+	second := newEvent(t, events.UserSignedIn, 1)
+	other := newEvent(t, events.UserRegistered, 2)
+	require.NoError(t, repo.Add(ctx, first, second, other))
+
+	rows, err := repo.Claim(ctx, 10, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID, other.ID}, ids(rows))
+	assert.Zero(t, rows[0].Attempts, "claim is not an attempt")
+
+	id := rows[0].ID
+
+	// Пока события заняты, ни они, ни следующие события агрегатов не возвращаются
+	rows, err = repo.Claim(ctx, 10, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	require.NoError(t, repo.MarkPublished(ctx, id))
+
+	rows, err = repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.ID}, ids(rows))
+}
+
+func testPurge(t *testing.T, repo outbox.Repository) {
+	ctx := context.Background()
+
+	published := newEvent(t, events.UserRegistered, 1)
+	pending := newEvent(t, events.UserRegistered, 2)
+	require.NoError(t, repo.Add(ctx, published, pending))
+
+	rows, err := repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.NoError(t, repo.MarkPublished(ctx, rows[0].ID))
+
+	n, err := repo.PurgePublished(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n, "published recently")
+
+	n, err = repo.PurgePublished(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// Неопубликованные события не удаляются
+	rows, err = repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{pending.ID}, ids(rows))
+}
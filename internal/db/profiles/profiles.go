@@ -0,0 +1,79 @@
+package profiles
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"service-template/internal/model"
+	"service-template/pkg/txmanager"
+
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrNotExists = fmt.Errorf("profile not exists")
+	ErrConflict  = fmt.Errorf("profile version conflict")
+)
+
+type Storage struct {
+	db bun.IDB
+}
+
+func NewStorage(db bun.IDB) *Storage {
+	return &Storage{
+		db: db,
+	}
+}
+
+// GetByUserID возвращает профиль пользователя.
+func (s *Storage) GetByUserID(ctx context.Context, userID uint64) (*model.Profile, error) {
+	profile := model.Profile{}
+
+	if err := txmanager.DB(ctx, s.db).NewSelect().Model(&profile).Where("user_id = ?", userID).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotExists
+		}
+
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+func (s *Storage) Create(ctx context.Context, profile *model.Profile) (*model.Profile, error) {
+	if _, err := txmanager.DB(ctx, s.db).NewInsert().Model(profile).Returning("*").Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// Update обновляет профиль, если версия в БД совпадает с profile.Version.
+func (s *Storage) Update(ctx context.Context, profile *model.Profile) (*model.Profile, error) {
+	res, err := txmanager.DB(ctx, s.db).NewUpdate().Model(profile).
+		ExcludeColumn("id", "user_id").
+		Value("version", "version + 1").
+		WherePK().
+		Where("version = ?", profile.Version).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		if exists, err := txmanager.DB(ctx, s.db).NewSelect().Model((*model.Profile)(nil)).Where("id = ?", profile.ID).Exists(ctx); err != nil {
+			return nil, err
+		} else if !exists {
+			return nil, ErrNotExists
+		}
+
+		return nil, ErrConflict
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,49 @@
+package profiles_test
+
+import (
+	"context"
+	"testing"
+
+	"service-template/internal/db/dbtest"
+	"service-template/internal/db/profiles"
+	"service-template/internal/db/users"
+	"service-template/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_sex(t *testing.T) {
+	db := dbtest.SQLite(t)
+	ctx := context.Background()
+
+	// Пользователь без ролей: nil не должен записываться как NULL
+	user, err := users.NewStorage(db).Create(ctx, &model.User{Email: "user@example.com", Password: "hash"})
+	require.NoError(t, err)
+
+	storage := profiles.NewStorage(db)
+
+	created, err := storage.Create(ctx, &model.Profile{UserID: user.ID, Name: "User", Sex: false})
+	require.NoError(t, err)
+	assert.False(t, created.Sex)
+
+	got, err := storage.GetByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Sex)
+
+	got.Sex = true
+	got, err = storage.Update(ctx, got)
+	require.NoError(t, err)
+
+	got.Sex = false
+	_, err = storage.Update(ctx, got)
+	require.NoError(t, err)
+
+	got, err = storage.GetByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Sex)
+	assert.EqualValues(t, 3, got.Version)
+
+	_, err = storage.Update(ctx, &model.Profile{ID: got.ID, UserID: user.ID, Version: 1})
+	assert.ErrorIs(t, err, profiles.ErrConflict)
+}
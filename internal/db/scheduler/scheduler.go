@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"service-template/internal/model"
+	"service-template/pkg/txmanager"
+
+	"github.com/uptrace/bun"
+)
+
+type Storage struct {
+	db bun.IDB
+}
+
+func NewStorage(db bun.IDB) *Storage {
+	return &Storage{
+		db: db,
+	}
+}
+
+// Start сохраняет начало запуска задачи. Если запуск задачи на это же время
+// уже сохранен другим экземпляром сервиса, возвращает false.
+func (s *Storage) Start(ctx context.Context, run *model.SchedulerRun) (bool, error) {
+	run.Status = model.RunRunning
+	run.StartedAt = time.Now()
+
+	res, err := txmanager.DB(ctx, s.db).NewInsert().Model(run).
+		ExcludeColumn("id", "error", "finished_at").
+		On("CONFLICT (task, scheduled_at) DO NOTHING").
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return n > 0, err
+}
+
+// Finish сохраняет результат запуска задачи.
+func (s *Storage) Finish(ctx context.Context, run *model.SchedulerRun) error {
+	now := time.Now()
+	run.FinishedAt = &now
+
+	_, err := txmanager.DB(ctx, s.db).NewUpdate().Model(run).
+		Column("status", "error", "finished_at").
+		WherePK().
+		Exec(ctx)
+
+	return err
+}
+
+// Last возвращает последний запуск задачи по расписанию или nil, если задача не запускалась.
+func (s *Storage) Last(ctx context.Context, task string) (*model.SchedulerRun, error) {
+	run := model.SchedulerRun{}
+
+	err := txmanager.DB(ctx, s.db).NewSelect().Model(&run).
+		Where("task = ?", task).
+		Where("NOT manual").
+		OrderExpr("scheduled_at DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// List возвращает последние запуски. Пустой task не ограничивает выборку.
+func (s *Storage) List(ctx context.Context, task string, limit int) ([]*model.SchedulerRun, error) {
+	var list []*model.SchedulerRun
+
+	query := txmanager.DB(ctx, s.db).NewSelect().Model(&list).OrderExpr("id DESC").Limit(limit)
+
+	if task != "" {
+		query.Where("task = ?", task)
+	}
+
+	err := query.Scan(ctx)
+
+	return list, err
+}
@@ -0,0 +1,87 @@
+package settings
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"service-template/internal/model"
+	"service-template/pkg/txmanager"
+
+	"github.com/uptrace/bun"
+)
+
+var ErrConflict = fmt.Errorf("settings version conflict")
+
+type Storage struct {
+	db bun.IDB
+}
+
+func NewStorage(db bun.IDB) *Storage {
+	return &Storage{
+		db: db,
+	}
+}
+
+// Get возвращает сохраненные настройки пользователя и их версию.
+// Если настройки еще не сохранялись, возвращается пустая карта и версия 0.
+func (s *Storage) Get(ctx context.Context, userID uint64) (map[string]interface{}, uint64, error) {
+	settings := model.UserSettings{}
+
+	if err := txmanager.DB(ctx, s.db).NewSelect().Model(&settings).Where("user_id = ?", userID).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return map[string]interface{}{}, 0, nil
+		}
+
+		return nil, 0, err
+	}
+
+	if settings.Settings == nil {
+		settings.Settings = map[string]interface{}{}
+	}
+
+	return settings.Settings, settings.Version, nil
+}
+
+// Save сохраняет настройки пользователя целиком, если их версия в БД равна version,
+// и возвращает новую версию. Версия 0 означает, что настройки еще не сохранялись.
+// Если версия изменилась, возвращается ErrConflict.
+func (s *Storage) Save(ctx context.Context, userID uint64, values map[string]interface{}, version uint64) (uint64, error) {
+	now := time.Now()
+	settings := model.UserSettings{
+		UserID:    userID,
+		Settings:  values,
+		UpdatedAt: &now,
+		Version:   version + 1,
+	}
+
+	var (
+		res sql.Result
+		err error
+	)
+
+	if version == 0 {
+		res, err = txmanager.DB(ctx, s.db).NewInsert().Model(&settings).
+			On("CONFLICT (user_id) DO NOTHING").
+			Exec(ctx)
+	} else {
+		res, err = txmanager.DB(ctx, s.db).NewUpdate().Model(&settings).
+			Column("settings", "updated_at", "version").
+			WherePK().
+			Where("version = ?", version).
+			Exec(ctx)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, err
+	} else if n == 0 {
+		return 0, ErrConflict
+	}
+
+	return settings.Version, nil
+}
@@ -0,0 +1,49 @@
+package settings_test
+
+import (
+	"context"
+	"testing"
+
+	"service-template/internal/db/dbtest"
+	"service-template/internal/db/settings"
+	"service-template/internal/db/users"
+	"service-template/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_Save(t *testing.T) {
+	db := dbtest.SQLite(t)
+	ctx := context.Background()
+
+	user, err := users.NewStorage(db).Create(ctx, &model.User{Email: "user@example.com", Password: "hash"})
+	require.NoError(t, err)
+
+	storage := settings.NewStorage(db)
+
+	values, version, err := storage.Get(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, values)
+	assert.Zero(t, version)
+
+	version, err = storage.Save(ctx, user.ID, map[string]interface{}{"theme": "dark"}, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, version)
+
+	// Параллельная первая запись и запись с устаревшей версией отклоняются
+	_, err = storage.Save(ctx, user.ID, map[string]interface{}{"theme": "light"}, 0)
+	assert.ErrorIs(t, err, settings.ErrConflict)
+
+	version, err = storage.Save(ctx, user.ID, map[string]interface{}{"theme": "light"}, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, version)
+
+	_, err = storage.Save(ctx, user.ID, map[string]interface{}{"theme": "dark"}, 1)
+	assert.ErrorIs(t, err, settings.ErrConflict)
+
+	values, version, err = storage.Get(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"theme": "light"}, values)
+	assert.EqualValues(t, 2, version)
+}
@@ -1,12 +1,25 @@
 package db
 
 import (
+	"context"
 	"errors"
+	"fmt"
+
 	"service-template/internal/config"
+	"service-template/internal/db/features"
+	"service-template/internal/db/jobs"
+	"service-template/internal/db/outbox"
+	"service-template/internal/db/profiles"
+	"service-template/internal/db/scheduler"
+	"service-template/internal/db/settings"
 	"service-template/internal/db/token"
 	"service-template/internal/db/users"
+	"service-template/internal/db/webhooks"
 	"service-template/pkg/drivers/postgres"
 	"service-template/pkg/drivers/redisdb"
+	"service-template/pkg/drivers/sqlite"
+	"service-template/pkg/retry"
+	"service-template/pkg/txmanager"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
@@ -18,53 +31,249 @@ import (
 type Storage struct {
 	cfg *config.Config
 	log *zerolog.Logger
-	pg  *bun.DB
-	rdb *redis.Client
+	db  *bun.DB
+	tx  *txmanager.Manager
+	rdb redis.UniversalClient
 
-	Token token.Storage[string, *token.Subject]
-	Users *users.Storage
-}
+	// replicas реплики Postgres, reads хранилища с репозиториями, привязанными к каждой реплике
+	replicas *postgres.Replicas
+	reads    []*Storage
 
-func NewStorage(cfg *config.Config, log *zerolog.Logger) (*Storage, error) {
-	var err error
+	Token     token.Storage[string, *token.Subject]
+	Users     users.Repository
+	Profiles  *profiles.Storage
+	Settings  *settings.Storage
+	Outbox    outbox.Repository
+	Webhooks  *webhooks.Storage
+	Jobs      *jobs.Storage
+	Scheduler *scheduler.Storage
+	Features  *features.Storage
+}
 
+// NewStorage подключается к Postgres или SQLite и к Redis. Пока зависимости недоступны, подключение
+// повторяется в пределах startup.max_wait или до отмены ctx. Если Redis работает в режиме memory,
+// хранилища на Redis используют встроенный сервер в памяти процесса.
+func NewStorage(ctx context.Context, cfg *config.Config, log *zerolog.Logger) (*Storage, error) {
 	storage := Storage{
 		cfg: cfg,
 		log: log,
 	}
 
-	if storage.pg, err = postgres.NewPostgresDB(cfg.Postgres); err != nil {
-		return nil, err
+	if err := storage.connect(ctx); err != nil {
+		return nil, errors.Join(err, storage.Close())
 	}
 
 	// Логирование SQL запросов
 	if cfg.Logger.SQL.Enabled {
-		if cfg.Logger.SQL.Default {
-			// Стандартный логировщик
-			storage.pg.AddQueryHook(bundebug.NewQueryHook(
-				bundebug.WithVerbose(true),
-			))
-		} else {
-			// Логировщик с помощью zerolog
-			storage.pg.AddQueryHook(&bunzerolog.QueryHook{})
+		for _, db := range storage.Databases() {
+			if cfg.Logger.SQL.Default {
+				// Стандартный логировщик
+				db.AddQueryHook(bundebug.NewQueryHook(
+					bundebug.WithVerbose(true),
+				))
+			} else {
+				// Логировщик с помощью zerolog
+				db.AddQueryHook(&bunzerolog.QueryHook{})
+			}
 		}
 	}
 
-	if storage.rdb, err = redisdb.NewRedisDB(cfg.Redis); err != nil {
-		return nil, err
+	// Ошибки конфигурации не исправятся повтором, поэтому проверяются до ожидания сервера
+	if _, err := cfg.Redis.Options(); err != nil {
+		return nil, errors.Join(fmt.Errorf("redis: %w", err), storage.Close())
+	}
+
+	err := retry.Do(ctx, cfg.Startup.Retry(), log, "redis", func(context.Context) (err error) {
+		storage.rdb, err = redisdb.NewRedisDB(cfg.Redis)
+		if redisdb.IsConnectionRejected(err) {
+			return retry.Permanent(err)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, errors.Join(err, storage.Close())
 	}
 
 	storage.Token = token.NewRedisStorage[string, *token.Subject](storage.rdb, cfg.Server.Auth.AccessExpire)
-	storage.Users = users.NewStorage(storage.pg)
+	storage.tx = txmanager.NewManager(storage.db, cfg.Tx, log)
+	storage.bind(storage.db)
+
+	for _, db := range storage.replicas.DBs() {
+		read := storage
+		read.replicas, read.reads = nil, nil
+		read.bind(db)
+
+		storage.reads = append(storage.reads, &read)
+	}
 
 	return &storage, nil
 }
 
+// NewMemoryStorage хранилище без внешних зависимостей для тестов сервисов. Пользователи,
+// события и токены хранятся в памяти процесса, остальные репозитории не заданы.
+// WithinTx выполняет fn без транзакции, поэтому изменения при ошибке не откатываются.
+func NewMemoryStorage(cfg *config.Config) *Storage {
+	return &Storage{
+		cfg:    cfg,
+		Token:  token.NewMemoryStorage[string, *token.Subject](cfg.Server.Auth.AccessExpire),
+		Users:  users.NewMemoryStorage(),
+		Outbox: outbox.NewMemoryStorage(),
+	}
+}
+
+// NewSQLStorage хранилище с репозиториями SQL БД поверх открытого соединения, без Redis и реплик.
+// Нужно тестам компонентов, которые работают только с SQL БД.
+func NewSQLStorage(cfg *config.Config, log *zerolog.Logger, db *bun.DB) *Storage {
+	storage := Storage{
+		cfg: cfg,
+		log: log,
+		db:  db,
+		tx:  txmanager.NewManager(db, cfg.Tx, log),
+	}
+
+	storage.bind(db)
+
+	return &storage
+}
+
+// connect подключается к SQL БД. У SQLite нет реплик, и чтение всегда идет из основной базы.
+func (s *Storage) connect(ctx context.Context) (err error) {
+	if s.db, err = Open(ctx, s.cfg, s.log); err != nil {
+		return err
+	}
+
+	if s.cfg.SQLite.Enabled {
+		s.replicas, err = postgres.NewReplicas(&postgres.Config{}, s.log)
+		return err
+	}
+
+	// Реплики не проверяются при запуске: недоступные исключаются из чтения до восстановления
+	s.replicas, err = postgres.NewReplicas(s.cfg.Postgres, s.log)
+
+	return err
+}
+
+// Open подключается к SQLite, если он включен, иначе к основному серверу Postgres,
+// ожидая его доступности в пределах startup.max_wait.
+func Open(ctx context.Context, cfg *config.Config, log *zerolog.Logger) (db *bun.DB, err error) {
+	if cfg.SQLite.Enabled {
+		return sqlite.NewSQLiteDB(cfg.SQLite)
+	}
+
+	// Ошибки конфигурации не исправятся повтором, поэтому пул создается до ожидания сервера
+	if db, err = postgres.OpenDB(cfg.Postgres); err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+
+	err = retry.Do(ctx, cfg.Startup.Retry(), log, "postgres", func(ctx context.Context) error {
+		err := db.PingContext(ctx)
+		if postgres.IsConnectionRejected(err) {
+			return retry.Permanent(err)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+
+	return db, nil
+}
+
+// bind привязывает репозитории SQL БД к соединению. Если контекст запроса содержит
+// транзакцию, репозитории используют ее.
+func (s *Storage) bind(db bun.IDB) {
+	s.Users = users.NewStorage(db)
+	s.Profiles = profiles.NewStorage(db)
+	s.Settings = settings.NewStorage(db)
+	s.Outbox = outbox.NewStorage(db)
+
+	// Выключенный outbox никто не публикует и не очищает, события не сохраняются
+	if s.cfg.Outbox == nil || !s.cfg.Outbox.Enabled {
+		s.Outbox = outbox.Discard{}
+	}
+
+	s.Webhooks = webhooks.NewStorage(db)
+	s.Jobs = jobs.NewStorage(db)
+	s.Scheduler = scheduler.NewStorage(db)
+	s.Features = features.NewStorage(db)
+}
+
+// WithinTx выполняет fn в транзакции SQL БД. Репозитории хранилища, вызванные с контекстом fn,
+// работают в этой транзакции, вложенный вызов WithinTx создает точку сохранения.
+// При конфликте с параллельной транзакцией fn выполняется повторно.
+func (s *Storage) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.tx == nil {
+		return fn(ctx)
+	}
+
+	return s.tx.WithinTx(ctx, fn)
+}
+
+type primaryKey struct{}
+
+// WithPrimary возвращает контекст, в котором Read использует основной сервер.
+// Нужен запросам, которые должны видеть только что выполненные записи.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Read возвращает хранилище для запросов только на чтение. Репозитории привязаны к доступной
+// реплике Postgres, а если реплик нет, все отстают или контекст создан WithPrimary — к основному серверу.
+func (s *Storage) Read(ctx context.Context) *Storage {
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary || s.replicas == nil {
+		return s
+	}
+
+	i, ok := s.replicas.Next()
+	if !ok {
+		return s
+	}
+
+	return s.reads[i]
+}
+
+// Replicas возвращает реплики Postgres.
+func (s *Storage) Replicas() *postgres.Replicas {
+	return s.replicas
+}
+
+// Databases возвращает соединения с основным сервером и репликами.
+func (s *Storage) Databases() []*bun.DB {
+	if s.db == nil {
+		return nil
+	}
+
+	dbs := []*bun.DB{s.db}
+	if s.replicas != nil {
+		dbs = append(dbs, s.replicas.DBs()...)
+	}
+
+	return dbs
+}
+
+// DB возвращает соединение с основным сервером Postgres или с SQLite.
+func (s *Storage) DB() *bun.DB {
+	return s.db
+}
+
+// Redis возвращает клиент Redis.
+func (s *Storage) Redis() redis.UniversalClient {
+	return s.rdb
+}
+
 func (s *Storage) Close() error {
 	var errs []error
 
-	if s.pg != nil {
-		if err := s.pg.Close(); err != nil {
+	if s.db != nil {
+		if err := s.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if s.replicas != nil {
+		if err := s.replicas.Close(); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -0,0 +1,70 @@
+package token
+
+import (
+	"time"
+
+	"service-template/pkg/cache"
+)
+
+type entry struct {
+	subject Subject
+	expires time.Time
+}
+
+type memory[k string, v *Subject] struct {
+	cache      *cache.Cache[k, entry]
+	expiration time.Duration
+}
+
+// NewMemoryStorage хранилище токенов в памяти процесса для тестов.
+// Как и в Redis, токен истекает через expiration, нулевое значение — без истечения.
+func NewMemoryStorage[k string, v *Subject](expiration time.Duration) Storage[k, v] {
+	return &memory[k, v]{
+		cache:      cache.NewCache[k, entry](),
+		expiration: expiration,
+	}
+}
+
+func (m *memory[k, v]) Set(key k, value v) error {
+	item := entry{subject: copySubject((*Subject)(value))}
+
+	if m.expiration > 0 {
+		item.expires = time.Now().Add(m.expiration)
+	}
+
+	m.cache.Set(key, item)
+
+	return nil
+}
+
+func (m *memory[k, v]) Get(key k) (v, error) {
+	item, ok := m.cache.Get(key)
+	if !ok {
+		return nil, ErrNotExists
+	}
+
+	if !item.expires.IsZero() && !time.Now().Before(item.expires) {
+		m.cache.Delete(key)
+		return nil, ErrNotExists
+	}
+
+	subject := copySubject(&item.subject)
+
+	return v(&subject), nil
+}
+
+func (m *memory[k, v]) Del(key k) error {
+	m.cache.Delete(key)
+
+	return nil
+}
+
+// copySubject копирует данные токена, чтобы изменения вызывающего кода не попадали в хранилище.
+func copySubject(subject *Subject) Subject {
+	c := *subject
+	if subject.Roles != nil {
+		c.Roles = append([]string{}, subject.Roles...)
+	}
+
+	return c
+}
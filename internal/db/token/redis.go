@@ -3,17 +3,18 @@ package token
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type storage[k string, v *Subject] struct {
-	redis      *redis.Client
+	redis      redis.UniversalClient
 	expiration time.Duration
 }
 
-func NewRedisStorage[k string, v *Subject](redis *redis.Client, expiration time.Duration) Storage[k, v] {
+func NewRedisStorage[k string, v *Subject](redis redis.UniversalClient, expiration time.Duration) Storage[k, v] {
 	return &storage[k, v]{
 		redis:      redis,
 		expiration: expiration,
@@ -21,16 +22,23 @@ func NewRedisStorage[k string, v *Subject](redis *redis.Client, expiration time.
 }
 
 func (s *storage[k, v]) Set(key k, value v) error {
-	return s.redis.Set(context.Background(), string(key), value, s.expiration).Err()
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.redis.Set(context.Background(), string(key), buf, s.expiration).Err()
 }
 
 func (s *storage[k, v]) Get(key k) (v, error) {
 	buf, err := s.redis.Get(context.Background(), string(key)).Bytes()
-	if err != nil {
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotExists
+	} else if err != nil {
 		return nil, err
 	}
 
-	var value v
+	value := v(new(Subject))
 	if err := json.Unmarshal(buf, value); err != nil {
 		return nil, err
 	}
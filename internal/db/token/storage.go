@@ -1,5 +1,10 @@
 package token
 
+import "errors"
+
+// ErrNotExists токен не найден или истек.
+var ErrNotExists = errors.New("token not exists")
+
 // Subject данные для хранения токенов.
 type Subject struct {
 	ID    uint64
@@ -11,6 +16,7 @@ type Subject struct {
 // Storage интерфейс хранилища токенов.
 type Storage[k string, v *Subject] interface {
 	Set(key k, value v) error
+	// Get возвращает ErrNotExists, если токена нет.
 	Get(key k) (v, error)
 	Del(key k) error
 }
@@ -0,0 +1,38 @@
+package token_test
+
+import (
+	"testing"
+	"time"
+
+	"service-template/internal/db/dbtest"
+	"service-template/internal/db/token"
+	"service-template/internal/db/token/tokentest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	tokentest.Run(t, func(t *testing.T) token.Storage[string, *token.Subject] {
+		return token.NewMemoryStorage[string, *token.Subject](tokentest.Expiration)
+	}, time.Sleep)
+}
+
+func TestRedisStorage_miniredis(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	tokentest.Run(t, func(t *testing.T) token.Storage[string, *token.Subject] {
+		server.FlushAll()
+
+		rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { rdb.Close() })
+
+		return token.NewRedisStorage[string, *token.Subject](rdb, tokentest.Expiration)
+	}, server.FastForward)
+}
+
+func TestRedisStorage_server(t *testing.T) {
+	tokentest.Run(t, func(t *testing.T) token.Storage[string, *token.Subject] {
+		return token.NewRedisStorage[string, *token.Subject](dbtest.RedisServer(t), tokentest.Expiration)
+	}, time.Sleep)
+}
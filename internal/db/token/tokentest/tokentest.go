@@ -0,0 +1,73 @@
+// Package tokentest проверяет, что реализации token.Storage ведут себя одинаково.
+package tokentest
+
+import (
+	"testing"
+	"time"
+
+	"service-template/internal/db/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Expiration время жизни токенов в хранилищах, создаваемых для тестов.
+const Expiration = 200 * time.Millisecond
+
+// Run запускает общие тесты хранилища. newStorage возвращает пустое хранилище с временем жизни
+// токенов Expiration, wait переводит время хранилища вперед.
+func Run(t *testing.T, newStorage func(t *testing.T) token.Storage[string, *token.Subject], wait func(d time.Duration)) {
+	t.Run("set get", func(t *testing.T) {
+		storage := newStorage(t)
+
+		subject := &token.Subject{ID: 1, Email: "user@example.com", Phone: "+70000000001", Roles: []string{"admin"}}
+		require.NoError(t, storage.Set("key", subject))
+
+		// Изменение переданного значения не меняет хранилище
+		subject.Roles[0] = "user"
+
+		got, err := storage.Get("key")
+		require.NoError(t, err)
+		assert.Equal(t, &token.Subject{ID: 1, Email: "user@example.com", Phone: "+70000000001", Roles: []string{"admin"}}, got)
+
+		require.NoError(t, storage.Set("key", &token.Subject{ID: 2}))
+
+		got, err = storage.Get("key")
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, got.ID)
+		assert.Nil(t, got.Roles)
+	})
+
+	t.Run("not exists", func(t *testing.T) {
+		storage := newStorage(t)
+
+		_, err := storage.Get("missing")
+		assert.ErrorIs(t, err, token.ErrNotExists)
+
+		assert.NoError(t, storage.Del("missing"))
+	})
+
+	t.Run("del", func(t *testing.T) {
+		storage := newStorage(t)
+
+		require.NoError(t, storage.Set("key", &token.Subject{ID: 1}))
+		require.NoError(t, storage.Del("key"))
+
+		_, err := storage.Get("key")
+		assert.ErrorIs(t, err, token.ErrNotExists)
+	})
+
+	t.Run("expiration", func(t *testing.T) {
+		storage := newStorage(t)
+
+		require.NoError(t, storage.Set("key", &token.Subject{ID: 1}))
+
+		_, err := storage.Get("key")
+		require.NoError(t, err)
+
+		wait(Expiration + 50*time.Millisecond)
+
+		_, err = storage.Get("key")
+		assert.ErrorIs(t, err, token.ErrNotExists)
+	})
+}
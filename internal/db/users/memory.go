@@ -0,0 +1,177 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"service-template/internal/model"
+	"service-template/pkg/cache"
+)
+
+// Memory хранилище пользователей в памяти процесса для тестов.
+// Повторяет поведение Storage: уникальность email и телефона проверяется
+// и для удаленных пользователей, пока они не удалены окончательно.
+type Memory struct {
+	mu    sync.Mutex
+	seq   uint64
+	users *cache.Cache[uint64, model.User]
+}
+
+func NewMemoryStorage() *Memory {
+	return &Memory{
+		users: cache.NewCache[uint64, model.User](),
+	}
+}
+
+func (m *Memory) Create(_ context.Context, user *model.User) (*model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.unique(user); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	m.seq++
+	user.ID = m.seq
+	user.Version = 1
+	user.CreatedAt = &now
+	user.UpdatedAt = nil
+
+	m.users.Set(user.ID, clone(user))
+
+	return user, nil
+}
+
+func (m *Memory) Update(_ context.Context, user *model.User) (*model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.users.Get(user.ID)
+	if !ok || stored.DeletedAt != nil {
+		return nil, ErrNotExists
+	}
+
+	if stored.Version != user.Version {
+		return nil, ErrConflict
+	}
+
+	if err := m.unique(user); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	user.UpdatedAt = &now
+	user.Version++
+	user.CreatedAt = stored.CreatedAt
+	user.DeletedAt = stored.DeletedAt
+
+	m.users.Set(user.ID, clone(user))
+
+	return user, nil
+}
+
+func (m *Memory) Get(_ context.Context, user *model.User) (*model.User, error) {
+	if found, ok := m.find(user); ok {
+		*user = clone(&found)
+		return user, nil
+	}
+
+	return nil, ErrNotExists
+}
+
+func (m *Memory) Exists(_ context.Context, user *model.User) (bool, error) {
+	_, ok := m.find(user)
+
+	return ok, nil
+}
+
+func (m *Memory) GetByID(_ context.Context, id uint64) (*model.User, error) {
+	user, ok := m.users.Get(id)
+	if !ok || user.DeletedAt != nil {
+		return nil, ErrNotExists
+	}
+
+	user = clone(&user)
+
+	return &user, nil
+}
+
+func (m *Memory) PurgeDeleted(_ context.Context, before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for _, user := range m.users.ToList() {
+		if user.DeletedAt != nil && user.DeletedAt.Before(before) {
+			m.users.Delete(user.ID)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// find ищет не удаленного пользователя по заполненным email и телефону.
+func (m *Memory) find(query *model.User) (model.User, bool) {
+	for _, user := range m.users.ToList() {
+		if user.DeletedAt != nil {
+			continue
+		}
+
+		if query.Email != "" && user.Email != query.Email {
+			continue
+		}
+
+		if query.Phone != "" && user.Phone != query.Phone {
+			continue
+		}
+
+		return user, true
+	}
+
+	return model.User{}, false
+}
+
+// unique проверяет, что email и телефон не заняты другими пользователями.
+// Вызывается под m.mu.
+func (m *Memory) unique(user *model.User) error {
+	for _, other := range m.users.ToList() {
+		if other.ID == user.ID {
+			continue
+		}
+
+		if user.Email != "" && other.Email == user.Email {
+			return fmt.Errorf("email %q: %w", user.Email, ErrExists)
+		}
+
+		if user.Phone != "" && other.Phone == user.Phone {
+			return fmt.Errorf("phone %q: %w", user.Phone, ErrExists)
+		}
+	}
+
+	return nil
+}
+
+// clone копирует пользователя, чтобы изменения вызывающего кода не попадали в хранилище.
+func clone(user *model.User) model.User {
+	c := *user
+	c.Profile = nil
+
+	if user.Roles != nil {
+		c.Roles = append([]string{}, user.Roles...)
+	}
+
+	for _, t := range []**time.Time{&c.CreatedAt, &c.UpdatedAt, &c.DeletedAt, &c.BlockedAt} {
+		if *t != nil {
+			v := **t
+			*t = &v
+		}
+	}
+
+	return c
+}
@@ -5,39 +5,102 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
+
 	"service-template/internal/model"
+	"service-template/pkg/txmanager"
 
 	"github.com/uptrace/bun"
 )
 
 var (
 	ErrNotExists = fmt.Errorf("user not exists")
+	ErrExists    = fmt.Errorf("user already exists")
+	ErrConflict  = fmt.Errorf("user version conflict")
 )
 
+// Repository хранилище пользователей. Удаленные пользователи не находятся
+// методами поиска, пока не будут окончательно удалены PurgeDeleted.
+type Repository interface {
+	// Create сохраняет пользователя. Если email или телефон заняты, возвращает ErrExists.
+	Create(ctx context.Context, user *model.User) (*model.User, error)
+	// Update обновляет пользователя с проверкой версии, возвращает ErrNotExists или ErrConflict.
+	Update(ctx context.Context, user *model.User) (*model.User, error)
+	// Get ищет пользователя по заполненным email и телефону, возвращает ErrNotExists.
+	Get(ctx context.Context, user *model.User) (*model.User, error)
+	// Exists проверяет, есть ли пользователь с заполненными email и телефоном.
+	Exists(ctx context.Context, user *model.User) (bool, error)
+	GetByID(ctx context.Context, id uint64) (*model.User, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+}
+
+// Storage хранилище пользователей в SQL БД.
 type Storage struct {
-	db *bun.DB
+	db bun.IDB
 }
 
-func NewStorage(db *bun.DB) *Storage {
+func NewStorage(db bun.IDB) *Storage {
 	return &Storage{
 		db: db,
 	}
 }
 
 func (s *Storage) Create(ctx context.Context, user *model.User) (*model.User, error) {
-	if _, err := s.db.NewInsert().Model(user).Returning("*").Exec(ctx); err != nil {
+	// Конфликт не прерывает транзакцию, в которой создается пользователь
+	res, err := txmanager.DB(ctx, s.db).NewInsert().Model(user).
+		On("CONFLICT DO NOTHING").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
 		return nil, err
+	} else if n == 0 {
+		return nil, ErrExists
 	}
 
 	return user, nil
 }
 
-func (s *Storage) Update(user *model.User) error {
-	return nil
+// Update обновляет пользователя, если версия в БД совпадает с user.Version.
+// При успешном обновлении версия увеличивается на единицу.
+func (s *Storage) Update(ctx context.Context, user *model.User) (*model.User, error) {
+	now := time.Now()
+	user.UpdatedAt = &now
+
+	res, err := txmanager.DB(ctx, s.db).NewUpdate().Model(user).
+		Column("email", "phone", "password", "password_reset", "roles", "blocked_at", "updated_at", "version").
+		Value("version", "version + 1").
+		// Для нулевых значений полей с default bun подставляет NULL
+		Value("password_reset", "?", user.PasswordReset).
+		WherePK().
+		Where("version = ?", user.Version).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		// Различаем отсутствие пользователя и конфликт версий
+		if exists, err := txmanager.DB(ctx, s.db).NewSelect().Model((*model.User)(nil)).Where("id = ?", user.ID).Exists(ctx); err != nil {
+			return nil, err
+		} else if !exists {
+			return nil, ErrNotExists
+		}
+
+		return nil, ErrConflict
+	}
+
+	return user, nil
 }
 
 func (s *Storage) Get(ctx context.Context, user *model.User) (*model.User, error) {
-	query := s.db.NewSelect().Model(user)
+	query := txmanager.DB(ctx, s.db).NewSelect().Model(user)
 
 	if user.Email != "" {
 		query = query.Where("email = ?", user.Email)
@@ -58,7 +121,7 @@ func (s *Storage) Get(ctx context.Context, user *model.User) (*model.User, error
 	return user, nil
 }
 func (s *Storage) Exists(ctx context.Context, user *model.User) (bool, error) {
-	query := s.db.NewSelect().Model(user)
+	query := txmanager.DB(ctx, s.db).NewSelect().Model(user)
 
 	if user.Email != "" {
 		query = query.Where("email = ?", user.Email)
@@ -71,8 +134,18 @@ func (s *Storage) Exists(ctx context.Context, user *model.User) (bool, error) {
 	return query.Exists(ctx)
 }
 
-func (s *Storage) GetByID(id int64) (*model.User, error) {
-	return nil, nil
+func (s *Storage) GetByID(ctx context.Context, id uint64) (*model.User, error) {
+	user := model.User{}
+
+	if err := txmanager.DB(ctx, s.db).NewSelect().Model(&user).Where("id = ?", id).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotExists
+		}
+
+		return nil, err
+	}
+
+	return &user, nil
 }
 
 func (s *Storage) Delete(id int64) error {
@@ -82,3 +155,20 @@ func (s *Storage) Delete(id int64) error {
 func (s *Storage) List() ([]*model.User, error) {
 	return nil, nil
 }
+
+// PurgeDeleted безвозвратно удаляет пользователей, удаленных раньше before.
+// Возвращает количество удаленных пользователей.
+func (s *Storage) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	res, err := txmanager.DB(ctx, s.db).NewDelete().Model((*model.User)(nil)).
+		WhereDeleted().
+		Where("deleted_at < ?", before).
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return int(n), err
+}
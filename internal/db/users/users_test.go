@@ -0,0 +1,27 @@
+package users_test
+
+import (
+	"testing"
+
+	"service-template/internal/db/dbtest"
+	"service-template/internal/db/users"
+	"service-template/internal/db/users/userstest"
+)
+
+func TestStorage_sqlite(t *testing.T) {
+	userstest.Run(t, func(t *testing.T) users.Repository {
+		return users.NewStorage(dbtest.SQLite(t))
+	})
+}
+
+func TestStorage_postgres(t *testing.T) {
+	userstest.Run(t, func(t *testing.T) users.Repository {
+		return users.NewStorage(dbtest.Postgres(t))
+	})
+}
+
+func TestMemory(t *testing.T) {
+	userstest.Run(t, func(t *testing.T) users.Repository {
+		return users.NewMemoryStorage()
+	})
+}
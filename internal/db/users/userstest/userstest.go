@@ -0,0 +1,241 @@
+// Package userstest проверяет, что реализации users.Repository ведут себя одинаково.
+package userstest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service-template/internal/db/users"
+	"service-template/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run запускает общие тесты хранилища. newRepo возвращает пустое хранилище для каждого теста.
+func Run(t *testing.T, newRepo func(t *testing.T) users.Repository) {
+	tests := map[string]func(t *testing.T, repo users.Repository){
+		"create":       testCreate,
+		"unique":       testUnique,
+		"get":          testGet,
+		"update":       testUpdate,
+		"soft deleted": testSoftDeleted,
+		"purge":        testPurge,
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			test(t, newRepo(t))
+		})
+	}
+}
+
+func newUser(email, phone string) *model.User {
+	return &model.User{
+		Email:    email,
+		Phone:    phone,
+		Password: "hash",
+		Roles:    []string{model.RoleAdmin},
+	}
+}
+
+func testCreate(t *testing.T, repo users.Repository) {
+	ctx := context.Background()
+
+	first, err := repo.Create(ctx, newUser("first@example.com", "+70000000001"))
+	require.NoError(t, err)
+	assert.NotZero(t, first.ID)
+	assert.EqualValues(t, 1, first.Version)
+	require.NotNil(t, first.CreatedAt)
+
+	second, err := repo.Create(ctx, newUser("second@example.com", "+70000000002"))
+	require.NoError(t, err)
+	assert.Greater(t, second.ID, first.ID)
+
+	got, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, got.ID)
+	assert.Equal(t, "first@example.com", got.Email)
+	assert.Equal(t, "+70000000001", got.Phone)
+	assert.Equal(t, "hash", got.Password)
+	assert.Equal(t, []string{model.RoleAdmin}, got.Roles)
+	assert.EqualValues(t, 1, got.Version)
+	assert.Nil(t, got.UpdatedAt)
+
+	// Изменение возвращенного значения не меняет хранилище
+	got.Email = "changed@example.com"
+
+	again, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "first@example.com", again.Email)
+
+	_, err = repo.GetByID(ctx, second.ID+100)
+	assert.ErrorIs(t, err, users.ErrNotExists)
+}
+
+func testUnique(t *testing.T, repo users.Repository) {
+	ctx := context.Background()
+
+	_, err := repo.Create(ctx, newUser("user@example.com", "+70000000001"))
+	require.NoError(t, err)
+
+	_, err = repo.Create(ctx, newUser("user@example.com", "+70000000002"))
+	assert.ErrorIs(t, err, users.ErrExists, "duplicate email")
+
+	_, err = repo.Create(ctx, newUser("other@example.com", "+70000000001"))
+	assert.ErrorIs(t, err, users.ErrExists, "duplicate phone")
+
+	_, err = repo.Create(ctx, newUser("other@example.com", "+70000000002"))
+	assert.NoError(t, err)
+
+	// Незаполненные email и телефон не занимают значение
+	_, err = repo.Create(ctx, newUser("first@example.com", ""))
+	require.NoError(t, err)
+
+	_, err = repo.Create(ctx, newUser("second@example.com", ""))
+	assert.NoError(t, err, "several users without phone")
+
+	_, err = repo.Create(ctx, newUser("", "+70000000003"))
+	require.NoError(t, err)
+
+	_, err = repo.Create(ctx, newUser("", "+70000000004"))
+	assert.NoError(t, err, "several users without email")
+}
+
+func testGet(t *testing.T, repo users.Repository) {
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, newUser("user@example.com", "+70000000001"))
+	require.NoError(t, err)
+
+	_, err = repo.Create(ctx, newUser("other@example.com", "+70000000002"))
+	require.NoError(t, err)
+
+	for _, query := range []*model.User{
+		{Email: "user@example.com"},
+		{Phone: "+70000000001"},
+		{Email: "user@example.com", Phone: "+70000000001"},
+	} {
+		got, err := repo.Get(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, got.ID)
+		assert.Equal(t, "hash", got.Password)
+
+		exists, err := repo.Exists(ctx, &model.User{Email: query.Email, Phone: query.Phone})
+		require.NoError(t, err)
+		assert.True(t, exists)
+	}
+
+	for _, query := range []*model.User{
+		{Email: "missing@example.com"},
+		{Email: "user@example.com", Phone: "+70000000002"},
+	} {
+		_, err = repo.Get(ctx, query)
+		assert.ErrorIs(t, err, users.ErrNotExists)
+
+		exists, err := repo.Exists(ctx, &model.User{Email: query.Email, Phone: query.Phone})
+		require.NoError(t, err)
+		assert.False(t, exists)
+	}
+}
+
+func testUpdate(t *testing.T, repo users.Repository) {
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, newUser("user@example.com", "+70000000001"))
+	require.NoError(t, err)
+
+	now := time.Now()
+	update := *created
+	update.Email = "new@example.com"
+	update.Roles = []string{}
+	update.BlockedAt = &now
+
+	updated, err := repo.Update(ctx, &update)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, updated.Version)
+	assert.NotNil(t, updated.UpdatedAt)
+
+	got, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", got.Email)
+	assert.Empty(t, got.Roles)
+	assert.EqualValues(t, 2, got.Version)
+	require.NotNil(t, got.BlockedAt)
+	assert.WithinDuration(t, now, *got.BlockedAt, time.Second)
+
+	_, err = repo.Get(ctx, &model.User{Email: "user@example.com"})
+	assert.ErrorIs(t, err, users.ErrNotExists, "old email")
+
+	// Версия уже увеличена, повторное обновление с исходной версией — конфликт
+	stale := *created
+	_, err = repo.Update(ctx, &stale)
+	assert.ErrorIs(t, err, users.ErrConflict)
+
+	missing := *created
+	missing.ID += 100
+	_, err = repo.Update(ctx, &missing)
+	assert.ErrorIs(t, err, users.ErrNotExists)
+}
+
+func testSoftDeleted(t *testing.T, repo users.Repository) {
+	ctx := context.Background()
+
+	deletedAt := time.Now().Add(-time.Hour)
+	user := newUser("deleted@example.com", "+70000000001")
+	user.DeletedAt = &deletedAt
+
+	created, err := repo.Create(ctx, user)
+	require.NoError(t, err)
+
+	_, err = repo.GetByID(ctx, created.ID)
+	assert.ErrorIs(t, err, users.ErrNotExists)
+
+	_, err = repo.Get(ctx, &model.User{Email: "deleted@example.com"})
+	assert.ErrorIs(t, err, users.ErrNotExists)
+
+	exists, err := repo.Exists(ctx, &model.User{Email: "deleted@example.com"})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Email удаленного пользователя остается занятым до окончательного удаления
+	_, err = repo.Create(ctx, newUser("deleted@example.com", "+70000000002"))
+	assert.Error(t, err)
+}
+
+func testPurge(t *testing.T, repo users.Repository) {
+	ctx := context.Background()
+
+	old := time.Now().Add(-48 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+
+	for i, deletedAt := range []*time.Time{&old, &recent, nil} {
+		user := newUser(string(rune('a'+i))+"@example.com", "+7000000000"+string(rune('1'+i)))
+		user.DeletedAt = deletedAt
+
+		_, err := repo.Create(ctx, user)
+		require.NoError(t, err)
+	}
+
+	n, err := repo.PurgeDeleted(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = repo.PurgeDeleted(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = repo.PurgeDeleted(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// Окончательно удаленный пользователь освобождает email
+	_, err = repo.Create(ctx, newUser("a@example.com", "+70000000009"))
+	assert.NoError(t, err)
+
+	exists, err := repo.Exists(ctx, &model.User{Email: "c@example.com"})
+	require.NoError(t, err)
+	assert.True(t, exists, "not deleted user is kept")
+}
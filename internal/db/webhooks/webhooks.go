@@ -0,0 +1,277 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"service-template/internal/events"
+	"service-template/internal/model"
+	"service-template/pkg/drivers/sqlite"
+	"service-template/pkg/txmanager"
+
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrNotExists         = fmt.Errorf("webhook not exists")
+	ErrDeliveryNotExists = fmt.Errorf("webhook delivery not exists")
+)
+
+type Storage struct {
+	db bun.IDB
+}
+
+func NewStorage(db bun.IDB) *Storage {
+	return &Storage{
+		db: db,
+	}
+}
+
+func (s *Storage) Create(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error) {
+	if _, err := txmanager.DB(ctx, s.db).NewInsert().Model(webhook).Returning("*").Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *Storage) Get(ctx context.Context, id uint64) (*model.Webhook, error) {
+	webhook := model.Webhook{}
+
+	if err := txmanager.DB(ctx, s.db).NewSelect().Model(&webhook).Where("id = ?", id).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotExists
+		}
+
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (s *Storage) List(ctx context.Context) ([]*model.Webhook, error) {
+	var list []*model.Webhook
+
+	if err := txmanager.DB(ctx, s.db).NewSelect().Model(&list).Order("id").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (s *Storage) Update(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error) {
+	now := time.Now()
+	webhook.UpdatedAt = &now
+
+	// Нулевое значение поля с default bun записывает как NULL, поэтому счетчик задается явно
+	res, err := txmanager.DB(ctx, s.db).NewUpdate().Model(webhook).
+		Column("url", "event_types", "secret", "enabled", "disabled_at", "updated_at").
+		Value("failures", "?", webhook.Failures).
+		WherePK().
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrNotExists
+	}
+
+	return webhook, nil
+}
+
+func (s *Storage) Delete(ctx context.Context, id uint64) error {
+	res, err := txmanager.DB(ctx, s.db).NewDelete().Model((*model.Webhook)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotExists
+	}
+
+	return nil
+}
+
+// Enqueue создает доставки события всем включенным подпискам на его тип.
+// Повторная постановка того же события в очередь игнорируется.
+func (s *Storage) Enqueue(ctx context.Context, event *events.Event) (int, error) {
+	var list []*model.Webhook
+
+	query := txmanager.DB(ctx, s.db).NewSelect().Model(&list).
+		Column("id").
+		Where("enabled")
+
+	// В SQLite типы событий хранятся в JSON массиве
+	if sqlite.Is(s.db) {
+		query = query.Where("EXISTS (SELECT 1 FROM json_each(event_types) WHERE value IN (?, ?))",
+			string(event.Type), model.WebhookAllEvents)
+	} else {
+		query = query.Where("(? = ANY(event_types) OR ? = ANY(event_types))", string(event.Type), model.WebhookAllEvents)
+	}
+
+	err := query.Scan(ctx)
+	if err != nil || len(list) == 0 {
+		return 0, err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	deliveries := make([]*model.WebhookDelivery, 0, len(list))
+	for _, webhook := range list {
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     string(event.Type),
+			Payload:       payload,
+			Status:        model.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+
+	res, err := txmanager.DB(ctx, s.db).NewInsert().Model(&deliveries).
+		ExcludeColumn("id", "response_code", "response_body", "error", "created_at", "delivered_at").
+		On("CONFLICT (webhook_id, event_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return int(n), err
+}
+
+// PendingDeliveries блокирует и возвращает доставки, готовые к отправке, вместе с подписками.
+// Должен вызываться внутри транзакции.
+func (s *Storage) PendingDeliveries(ctx context.Context, limit int) ([]*model.WebhookDelivery, error) {
+	var list []*model.WebhookDelivery
+
+	query := txmanager.DB(ctx, s.db).NewSelect().Model(&list).
+		Relation("Webhook").
+		Where("d.status = ?", model.DeliveryPending).
+		Where("d.next_attempt_at <= ?", time.Now()).
+		Where("webhook.enabled").
+		OrderExpr("d.id").
+		Limit(limit)
+
+	// В SQLite блокировки строк нет, вся база блокируется транзакцией записи
+	if !sqlite.Is(s.db) {
+		query = query.For("UPDATE OF d SKIP LOCKED")
+	}
+
+	err := query.Scan(ctx)
+
+	return list, err
+}
+
+// ClaimDeliveries возвращает доставки, готовые к отправке, и откладывает их следующую попытку
+// до until, чтобы другие экземпляры не отправляли их одновременно. Должен вызываться внутри транзакции.
+func (s *Storage) ClaimDeliveries(ctx context.Context, limit int, until time.Time) ([]*model.WebhookDelivery, error) {
+	list, err := s.PendingDeliveries(ctx, limit)
+	if err != nil || len(list) == 0 {
+		return list, err
+	}
+
+	ids := make([]uint64, 0, len(list))
+	for _, delivery := range list {
+		ids = append(ids, delivery.ID)
+	}
+
+	_, err = txmanager.DB(ctx, s.db).NewUpdate().Model((*model.WebhookDelivery)(nil)).
+		Set("next_attempt_at = ?", until).
+		Where("id IN (?)", bun.In(ids)).
+		Exec(ctx)
+
+	return list, err
+}
+
+// SaveDelivery сохраняет результат попытки доставки.
+func (s *Storage) SaveDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	_, err := txmanager.DB(ctx, s.db).NewUpdate().Model(delivery).
+		Column("status", "attempts", "next_attempt_at", "response_code", "response_body", "error", "delivered_at").
+		WherePK().
+		Exec(ctx)
+
+	return err
+}
+
+// ListDeliveries возвращает последние доставки подписки.
+func (s *Storage) ListDeliveries(ctx context.Context, webhookID uint64, limit int) ([]*model.WebhookDelivery, error) {
+	var list []*model.WebhookDelivery
+
+	err := txmanager.DB(ctx, s.db).NewSelect().Model(&list).
+		Where("webhook_id = ?", webhookID).
+		OrderExpr("id DESC").
+		Limit(limit).
+		Scan(ctx)
+
+	return list, err
+}
+
+// Redeliver ставит доставку в очередь повторно, сбрасывая счетчик попыток.
+func (s *Storage) Redeliver(ctx context.Context, webhookID, id uint64) (*model.WebhookDelivery, error) {
+	delivery := model.WebhookDelivery{}
+
+	res, err := txmanager.DB(ctx, s.db).NewUpdate().Model(&delivery).
+		Set("status = ?", model.DeliveryPending).
+		Set("attempts = 0").
+		Set("next_attempt_at = ?", time.Now()).
+		Where("id = ?", id).
+		Where("webhook_id = ?", webhookID).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrDeliveryNotExists
+	}
+
+	return &delivery, nil
+}
+
+// RecordSuccess сбрасывает счетчик последовательных ошибок подписки.
+func (s *Storage) RecordSuccess(ctx context.Context, id uint64) error {
+	_, err := txmanager.DB(ctx, s.db).NewUpdate().Model((*model.Webhook)(nil)).
+		Set("failures = 0").
+		Where("id = ?", id).
+		Where("failures <> 0").
+		Exec(ctx)
+
+	return err
+}
+
+// RecordFailure увеличивает счетчик последовательных ошибок подписки
+// и отключает ее, если счетчик достиг disableAfter. Возвращает true, если подписка отключена.
+func (s *Storage) RecordFailure(ctx context.Context, id uint64, disableAfter int) (bool, error) {
+	webhook := model.Webhook{}
+
+	_, err := txmanager.DB(ctx, s.db).NewUpdate().Model(&webhook).
+		Set("failures = failures + 1").
+		Set("enabled = CASE WHEN failures + 1 >= ? THEN FALSE ELSE enabled END", disableAfter).
+		Set("disabled_at = CASE WHEN failures + 1 >= ? THEN ? ELSE disabled_at END", disableAfter, time.Now()).
+		Where("id = ?", id).
+		Returning("enabled").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return !webhook.Enabled, nil
+}
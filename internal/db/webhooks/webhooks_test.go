@@ -0,0 +1,285 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"service-template/internal/db/dbtest"
+	"service-template/internal/db/webhooks"
+	"service-template/internal/events"
+	"service-template/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestStorage_sqlite(t *testing.T) {
+	run(t, dbtest.SQLite)
+}
+
+func TestStorage_postgres(t *testing.T) {
+	run(t, dbtest.Postgres)
+}
+
+func run(t *testing.T, open func(t testing.TB) *bun.DB) {
+	tests := map[string]func(t *testing.T, storage *webhooks.Storage){
+		"crud":      testCRUD,
+		"enqueue":   testEnqueue,
+		"pending":   testPending,
+		"redeliver": testRedeliver,
+		"failures":  testFailures,
+		"claim":     testClaim,
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			test(t, webhooks.NewStorage(open(t)))
+		})
+	}
+}
+
+func newEvent(t *testing.T, typ events.Type, userID uint64) *events.Event {
+	event, err := events.NewUser(typ, events.User{ID: userID, Email: "user@example.com"})
+	require.NoError(t, err)
+
+	return event
+}
+
+func create(t *testing.T, storage *webhooks.Storage, enabled bool, types ...string) *model.Webhook {
+	webhook, err := storage.Create(context.Background(), &model.Webhook{
+		URL:        "https://example.com/hook",
+		EventTypes: types,
+		Secret:     "secret",
+		Enabled:    enabled,
+	})
+	require.NoError(t, err)
+
+	return webhook
+}
+
+func testCRUD(t *testing.T, storage *webhooks.Storage) {
+	ctx := context.Background()
+
+	webhook := create(t, storage, true, string(events.UserRegistered))
+	assert.NotZero(t, webhook.ID)
+
+	got, err := storage.Get(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{string(events.UserRegistered)}, got.EventTypes)
+	assert.True(t, got.Enabled)
+
+	got.URL = "https://example.com/other"
+	got.Enabled = false
+
+	_, err = storage.Update(ctx, got)
+	require.NoError(t, err)
+
+	list, err := storage.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "https://example.com/other", list[0].URL)
+	assert.False(t, list[0].Enabled)
+	assert.NotNil(t, list[0].UpdatedAt)
+
+	require.NoError(t, storage.Delete(ctx, webhook.ID))
+
+	_, err = storage.Get(ctx, webhook.ID)
+	assert.ErrorIs(t, err, webhooks.ErrNotExists)
+	assert.ErrorIs(t, storage.Delete(ctx, webhook.ID), webhooks.ErrNotExists)
+
+	_, err = storage.Update(ctx, got)
+	assert.ErrorIs(t, err, webhooks.ErrNotExists)
+}
+
+func testEnqueue(t *testing.T, storage *webhooks.Storage) {
+	ctx := context.Background()
+
+	registered := create(t, storage, true, string(events.UserRegistered))
+	all := create(t, storage, true, model.WebhookAllEvents)
+	create(t, storage, false, model.WebhookAllEvents)
+	create(t, storage, true, string(events.UserBlocked))
+
+	event := newEvent(t, events.UserRegistered, 1)
+
+	n, err := storage.Enqueue(ctx, event)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "enabled webhooks subscribed to the type or to all events")
+
+	// Повторная постановка того же события игнорируется
+	n, err = storage.Enqueue(ctx, event)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	list, err := storage.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	ids := []uint64{list[0].WebhookID, list[1].WebhookID}
+	assert.ElementsMatch(t, []uint64{registered.ID, all.ID}, ids)
+
+	delivery := list[0]
+	assert.Equal(t, event.ID, delivery.EventID)
+	assert.Equal(t, string(events.UserRegistered), delivery.EventType)
+	assert.Equal(t, model.DeliveryPending, delivery.Status)
+	assert.JSONEq(t, `"`+event.ID+`"`, jsonField(t, delivery.Payload, "id"))
+	require.NotNil(t, delivery.Webhook)
+	assert.Equal(t, "secret", delivery.Webhook.Secret)
+
+	n, err = storage.Enqueue(ctx, newEvent(t, events.UserSignedIn, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "only the webhook subscribed to all events")
+}
+
+func testPending(t *testing.T, storage *webhooks.Storage) {
+	ctx := context.Background()
+
+	webhook := create(t, storage, true, model.WebhookAllEvents)
+
+	_, err := storage.Enqueue(ctx, newEvent(t, events.UserRegistered, 1))
+	require.NoError(t, err)
+	_, err = storage.Enqueue(ctx, newEvent(t, events.UserRegistered, 2))
+	require.NoError(t, err)
+
+	list, err := storage.PendingDeliveries(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	first := list[0]
+
+	// Доставка, отложенная до следующей попытки, не возвращается
+	first.Attempts = 1
+	first.NextAttemptAt = time.Now().Add(time.Hour)
+	first.Error = "timeout"
+	require.NoError(t, storage.SaveDelivery(ctx, first))
+
+	list, err = storage.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.NotEqual(t, first.ID, list[0].ID)
+
+	now := time.Now()
+	list[0].Status = model.DeliverySucceeded
+	list[0].Attempts = 1
+	list[0].ResponseCode = 200
+	list[0].DeliveredAt = &now
+	require.NoError(t, storage.SaveDelivery(ctx, list[0]))
+
+	list, err = storage.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	history, err := storage.ListDeliveries(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, model.DeliverySucceeded, history[0].Status, "newest first")
+	assert.Equal(t, 200, history[0].ResponseCode)
+	assert.Equal(t, "timeout", history[1].Error)
+
+	// Доставки отключенной подписки не отправляются
+	_, err = storage.Redeliver(ctx, webhook.ID, first.ID)
+	require.NoError(t, err)
+
+	webhook.Enabled = false
+	_, err = storage.Update(ctx, webhook)
+	require.NoError(t, err)
+
+	list, err = storage.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func testRedeliver(t *testing.T, storage *webhooks.Storage) {
+	ctx := context.Background()
+
+	webhook := create(t, storage, true, model.WebhookAllEvents)
+	other := create(t, storage, true, string(events.UserBlocked))
+
+	_, err := storage.Enqueue(ctx, newEvent(t, events.UserRegistered, 1))
+	require.NoError(t, err)
+
+	list, err := storage.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	delivery := list[0]
+	delivery.Status = model.DeliveryFailed
+	delivery.Attempts = 8
+	require.NoError(t, storage.SaveDelivery(ctx, delivery))
+
+	_, err = storage.Redeliver(ctx, other.ID, delivery.ID)
+	assert.ErrorIs(t, err, webhooks.ErrDeliveryNotExists, "delivery of another webhook")
+
+	redelivered, err := storage.Redeliver(ctx, webhook.ID, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.DeliveryPending, redelivered.Status)
+	assert.Zero(t, redelivered.Attempts)
+
+	list, err = storage.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func testFailures(t *testing.T, storage *webhooks.Storage) {
+	ctx := context.Background()
+
+	webhook := create(t, storage, true, model.WebhookAllEvents)
+
+	disabled, err := storage.RecordFailure(ctx, webhook.ID, 2)
+	require.NoError(t, err)
+	assert.False(t, disabled)
+
+	// Успешная доставка сбрасывает счетчик последовательных ошибок
+	require.NoError(t, storage.RecordSuccess(ctx, webhook.ID))
+
+	disabled, err = storage.RecordFailure(ctx, webhook.ID, 2)
+	require.NoError(t, err)
+	assert.False(t, disabled)
+
+	disabled, err = storage.RecordFailure(ctx, webhook.ID, 2)
+	require.NoError(t, err)
+	assert.True(t, disabled)
+
+	got, err := storage.Get(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Enabled)
+	assert.Equal(t, 2, got.Failures)
+	assert.NotNil(t, got.DisabledAt)
+}
+
+func testClaim(t *testing.T, storage *webhooks.Storage) {
+	ctx := context.Background()
+
+	create(t, storage, true, model.WebhookAllEvents)
+
+	_, err := storage.Enqueue(ctx, newEvent(t, events.UserRegistered, 1))
+	require.NoError(t, err)
+
+	list, err := storage.ClaimDeliveries(ctx, 10, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.NotNil(t, list[0].Webhook)
+
+	// Занятая доставка не возвращается до истечения времени
+	claimed, err := storage.ClaimDeliveries(ctx, 10, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	history, err := storage.ListDeliveries(ctx, list[0].WebhookID, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, model.DeliveryPending, history[0].Status)
+	assert.Zero(t, history[0].Attempts, "claim is not an attempt")
+}
+
+func jsonField(t *testing.T, data []byte, field string) string {
+	t.Helper()
+
+	var values map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &values))
+
+	return string(values[field])
+}
@@ -0,0 +1,249 @@
+package delivery
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-template/internal/config"
+	"service-template/internal/config/outbox"
+	"service-template/internal/config/webhooks"
+	"service-template/internal/db"
+	"service-template/internal/db/dbtest"
+	"service-template/internal/events"
+	"service-template/internal/model"
+	"service-template/pkg/signature"
+	"service-template/pkg/txmanager"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSender(t *testing.T) (*Sender, *db.Storage) {
+	t.Helper()
+
+	log := zerolog.Nop()
+
+	cfg := webhooks.NewConfig()
+	cfg.Enabled = true
+	cfg.Interval = 10 * time.Millisecond
+	cfg.MaxAttempts = 2
+	cfg.DisableAfter = 3
+
+	storage := db.NewSQLStorage(&config.Config{Outbox: outbox.NewConfig(), Tx: txmanager.NewConfig()}, &log, dbtest.SQLite(t))
+
+	return NewSender(cfg, &log, storage), storage
+}
+
+func subscribe(t *testing.T, storage *db.Storage, url string) *model.Webhook {
+	t.Helper()
+
+	webhook, err := storage.Webhooks.Create(context.Background(), &model.Webhook{
+		URL:        url,
+		EventTypes: []string{model.WebhookAllEvents},
+		Secret:     "secret",
+		Enabled:    true,
+	})
+	require.NoError(t, err)
+
+	return webhook
+}
+
+func dispatch(t *testing.T, storage *db.Storage) *events.Event {
+	t.Helper()
+
+	event, err := events.NewUser(events.UserRegistered, events.User{ID: 1, Email: "user@example.com"})
+	require.NoError(t, err)
+
+	require.NoError(t, NewDispatcher(storage).Publish(context.Background(), event))
+
+	return event
+}
+
+func deliveries(t *testing.T, storage *db.Storage, webhookID uint64) []*model.WebhookDelivery {
+	t.Helper()
+
+	list, err := storage.Webhooks.ListDeliveries(context.Background(), webhookID, 10)
+	require.NoError(t, err)
+
+	return list
+}
+
+func TestSender_success(t *testing.T) {
+	sender, storage := newSender(t)
+	ctx := context.Background()
+
+	var received atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		assert.Equal(t, string(events.UserRegistered), r.Header.Get(HeaderEvent))
+		assert.NotEmpty(t, r.Header.Get(HeaderID))
+		assert.NoError(t, signature.Verify("secret", r.Header.Get(HeaderSignature), body, time.Now(), time.Minute))
+
+		received.Add(1)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	webhook := subscribe(t, storage, server.URL)
+	event := dispatch(t, storage)
+
+	n, err := sender.send(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.EqualValues(t, 1, received.Load())
+
+	list := deliveries(t, storage, webhook.ID)
+	require.Len(t, list, 1)
+	assert.Equal(t, event.ID, list[0].EventID)
+	assert.Equal(t, model.DeliverySucceeded, list[0].Status)
+	assert.Equal(t, 200, list[0].ResponseCode)
+	assert.Equal(t, "ok", list[0].ResponseBody)
+	assert.NotNil(t, list[0].DeliveredAt)
+
+	// Отправленная доставка не отправляется повторно
+	n, err = sender.send(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestSender_failure(t *testing.T) {
+	sender, storage := newSender(t)
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	webhook := subscribe(t, storage, server.URL)
+	dispatch(t, storage)
+
+	_, err := sender.send(ctx)
+	require.NoError(t, err)
+
+	list := deliveries(t, storage, webhook.ID)
+	require.Len(t, list, 1)
+	assert.Equal(t, model.DeliveryPending, list[0].Status)
+	assert.Equal(t, 1, list[0].Attempts)
+	assert.Equal(t, http.StatusBadGateway, list[0].ResponseCode)
+	assert.Equal(t, "unexpected status code 502", list[0].Error)
+	assert.True(t, list[0].NextAttemptAt.After(time.Now()), "retry is delayed")
+
+	// После задержки доставка отправляется повторно и исчерпывает попытки
+	time.Sleep(sender.cfg.Interval)
+
+	_, err = sender.send(ctx)
+	require.NoError(t, err)
+
+	list = deliveries(t, storage, webhook.ID)
+	assert.Equal(t, model.DeliveryFailed, list[0].Status)
+	assert.Equal(t, 2, list[0].Attempts)
+
+	got, err := storage.Webhooks.Get(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Failures)
+	assert.True(t, got.Enabled)
+}
+
+func TestSender_disable(t *testing.T) {
+	sender, storage := newSender(t)
+	ctx := context.Background()
+
+	// Подписчик недоступен
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	webhook := subscribe(t, storage, server.URL)
+
+	for i := 0; i < sender.cfg.DisableAfter; i++ {
+		event, err := events.NewUser(events.UserSignedIn, events.User{ID: uint64(i + 1)})
+		require.NoError(t, err)
+		require.NoError(t, NewDispatcher(storage).Publish(ctx, event))
+	}
+
+	_, err := sender.send(ctx)
+	require.NoError(t, err)
+
+	got, err := storage.Webhooks.Get(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Enabled, "disabled after consecutive failures")
+	assert.NotNil(t, got.DisabledAt)
+
+	for _, delivery := range deliveries(t, storage, webhook.ID) {
+		assert.NotEmpty(t, delivery.Error)
+		assert.Zero(t, delivery.ResponseCode)
+	}
+}
+
+func TestSender_claim(t *testing.T) {
+	sender, storage := newSender(t)
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		close(started)
+		<-release
+	}))
+	defer server.Close()
+
+	webhook := subscribe(t, storage, server.URL)
+	dispatch(t, storage)
+
+	done := make(chan error)
+	go func() {
+		_, err := sender.send(ctx)
+		done <- err
+	}()
+
+	<-started
+
+	// Отправляемая доставка занята, а транзакция не удерживается во время запроса
+	n, err := sender.send(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	close(release)
+	require.NoError(t, <-done)
+
+	list := deliveries(t, storage, webhook.ID)
+	require.Len(t, list, 1)
+	assert.Equal(t, model.DeliverySucceeded, list[0].Status)
+	assert.Equal(t, 1, list[0].Attempts)
+}
+
+func TestSender_Run(t *testing.T) {
+	sender, storage := newSender(t)
+
+	var received atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		received.Add(1)
+	}))
+	defer server.Close()
+
+	subscribe(t, storage, server.URL)
+	dispatch(t, storage)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sender.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool { return received.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
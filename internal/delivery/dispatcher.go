@@ -0,0 +1,29 @@
+// Package delivery доставляет доменные события подписчикам вебхуков.
+package delivery
+
+import (
+	"context"
+
+	"service-template/internal/db"
+	"service-template/internal/events"
+)
+
+// Dispatcher ставит доставки событий подписчикам в очередь.
+// Реализует relay.Publisher, поэтому получает события из outbox. Relay захватывает события
+// в короткой транзакции и публикует их вне ее, поэтому событие может прийти повторно.
+// Повтор не создает лишних доставок: Enqueue пропускает уже существующую пару вебхук-событие.
+type Dispatcher struct {
+	storage *db.Storage
+}
+
+func NewDispatcher(storage *db.Storage) *Dispatcher {
+	return &Dispatcher{
+		storage: storage,
+	}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, event *events.Event) error {
+	_, err := d.storage.Webhooks.Enqueue(ctx, event)
+
+	return err
+}
@@ -0,0 +1,167 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"service-template/internal/config/webhooks"
+	"service-template/internal/db"
+	"service-template/internal/model"
+	"service-template/pkg/runner"
+	"service-template/pkg/signature"
+
+	"github.com/rs/zerolog"
+)
+
+// Заголовки запроса доставки вебхука.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-ID"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxResponseBody количество байт ответа подписчика, сохраняемых в журнале доставок.
+const maxResponseBody = 1024
+
+// Sender отправляет доставки вебхуков с повторами по экспоненциальной задержке.
+type Sender struct {
+	cfg     *webhooks.Config
+	log     *zerolog.Logger
+	storage *db.Storage
+	client  *http.Client
+}
+
+func NewSender(cfg *webhooks.Config, log *zerolog.Logger, storage *db.Storage) *Sender {
+	return &Sender{
+		cfg:     cfg,
+		log:     log,
+		storage: storage,
+		client:  &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Run отправляет доставки до отмены контекста.
+func (s *Sender) Run(ctx context.Context) {
+	runner.Poll(ctx, s.cfg.Interval, func(ctx context.Context) (bool, error) {
+		n, err := s.send(ctx)
+		return n >= s.cfg.Batch, err
+	}, func(err error) {
+		s.log.Error().Err(err).Msg("webhooks sender")
+	})
+}
+
+// send отправляет одну пачку доставок и возвращает количество обработанных.
+// Доставки занимаются в короткой транзакции, а отправляются вне ее, чтобы повтор транзакции
+// при конфликте не отправлял запросы заново и транзакция не ждала ответа подписчика.
+func (s *Sender) send(ctx context.Context) (int, error) {
+	var list []*model.WebhookDelivery
+
+	// Доставки пачки отправляются по очереди, каждая не дольше Timeout
+	until := time.Now().Add(time.Duration(s.cfg.Batch) * s.cfg.Timeout)
+
+	err := s.storage.WithinTx(ctx, func(ctx context.Context) (err error) {
+		list, err = s.storage.Webhooks.ClaimDeliveries(ctx, s.cfg.Batch, until)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range list {
+		if err = s.deliver(ctx, delivery); err != nil {
+			return len(list), err
+		}
+	}
+
+	return len(list), nil
+}
+
+func (s *Sender) deliver(ctx context.Context, delivery *model.WebhookDelivery) error {
+	log := s.log.With().
+		Uint64("webhook_id", delivery.WebhookID).
+		Uint64("delivery_id", delivery.ID).
+		Str("event_id", delivery.EventID).
+		Logger()
+
+	delivery.Attempts++
+	delivery.ResponseCode, delivery.ResponseBody, delivery.Error = s.post(ctx, delivery)
+
+	if delivery.Error == "" && delivery.ResponseCode >= 200 && delivery.ResponseCode < 300 {
+		now := time.Now()
+		delivery.Status = model.DeliverySucceeded
+		delivery.DeliveredAt = &now
+
+		return s.storage.WithinTx(ctx, func(ctx context.Context) error {
+			if err := s.storage.Webhooks.SaveDelivery(ctx, delivery); err != nil {
+				return err
+			}
+
+			return s.storage.Webhooks.RecordSuccess(ctx, delivery.WebhookID)
+		})
+	}
+
+	if delivery.Error == "" {
+		delivery.Error = fmt.Sprintf("unexpected status code %d", delivery.ResponseCode)
+	}
+
+	if delivery.Attempts >= s.cfg.MaxAttempts {
+		delivery.Status = model.DeliveryFailed
+	} else {
+		delivery.NextAttemptAt = time.Now().Add(runner.Backoff(s.cfg.Interval, s.cfg.MaxBackoff, delivery.Attempts))
+	}
+
+	log.Warn().
+		Int("attempts", delivery.Attempts).
+		Str("status", delivery.Status).
+		Str("error", delivery.Error).
+		Msg("webhook delivery failed")
+
+	var disabled bool
+
+	err := s.storage.WithinTx(ctx, func(ctx context.Context) (err error) {
+		if err = s.storage.Webhooks.SaveDelivery(ctx, delivery); err != nil {
+			return err
+		}
+
+		disabled, err = s.storage.Webhooks.RecordFailure(ctx, delivery.WebhookID, s.cfg.DisableAfter)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if disabled {
+		log.Warn().Msg("webhook disabled after consecutive failures")
+	}
+
+	return nil
+}
+
+// post отправляет подписанный запрос и возвращает код и начало тела ответа или текст ошибки.
+func (s *Sender) post(ctx context.Context, delivery *model.WebhookDelivery) (int, string, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err.Error()
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	// Идентификатор события позволяет подписчику отбросить повторные доставки
+	req.Header.Set(HeaderID, delivery.EventID)
+	req.Header.Set(HeaderSignature, signature.Sign(delivery.Webhook.Secret, time.Now(), delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err.Error()
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+
+	return resp.StatusCode, string(body), ""
+}
@@ -0,0 +1,102 @@
+// Package dev запускает сервис для локальной разработки без Postgres и Redis.
+package dev
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"service-template/internal/config"
+	"service-template/internal/daemon"
+
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+)
+
+// ConfigFile файл конфигурации, если он не указан флагом --config.
+const ConfigFile = "dev.yaml"
+
+// configTemplate конфигурация для локальной разработки: SQLite вместо Postgres,
+// встроенный Redis и брокер в памяти процесса. Параметр — секрет токенов.
+const configTemplate = `# Конфигурация для локальной разработки, создана командой dev.
+server:
+  port: "8080"
+  auth:
+    token_secret: %s
+    access_expire: 1h
+    refresh_expire: 720h
+logger:
+  console: true
+sqlite:
+  enabled: true
+  path: dev.db
+redis:
+  mode: memory
+broker:
+  driver: memory
+startup:
+  migrate: true
+`
+
+// Command возвращает команду запуска сервиса для локальной разработки. Если файла
+// конфигурации нет, он создается. При запуске применяются миграции и создаются
+// демонстрационные пользователи.
+func Command(log *zerolog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "dev",
+		Usage: "run the service locally on SQLite and in-memory Redis",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "no-seed",
+				Usage: "do not create demo users",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			path := c.String("config")
+			if path == "" {
+				path = ConfigFile
+			}
+
+			if err := generate(path); err != nil {
+				return err
+			}
+
+			cfg, err := config.New(path)
+			if err != nil {
+				return err
+			}
+
+			if err = cfg.Validate(); err != nil {
+				return err
+			}
+
+			dmn := daemon.New(log, cfg)
+			if !c.Bool("no-seed") {
+				dmn.Seed(seed(log))
+			}
+
+			return dmn.Run()
+		},
+	}
+}
+
+// generate создает файл конфигурации со случайным секретом токенов, если его еще нет.
+func generate(path string) error {
+	if _, err := os.Stat(path); err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(configTemplate, hex.EncodeToString(secret))), 0o600); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	return nil
+}
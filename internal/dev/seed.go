@@ -0,0 +1,87 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+
+	"service-template/internal/daemon"
+	"service-template/internal/db"
+	"service-template/internal/model"
+	"service-template/pkg/password"
+
+	"github.com/rs/zerolog"
+)
+
+// DemoPassword пароль демонстрационных пользователей.
+const DemoPassword = "demo-password"
+
+// demoUsers демонстрационные пользователи с профилями.
+var demoUsers = []model.User{
+	{
+		Email: "admin@example.com",
+		Phone: "+70000000001",
+		Roles: []string{model.RoleAdmin},
+		Profile: &model.Profile{
+			Name:    "Admin",
+			Surname: "Demo",
+		},
+	},
+	{
+		Email: "user@example.com",
+		Phone: "+70000000002",
+		Roles: []string{},
+		Profile: &model.Profile{
+			Name:    "User",
+			Surname: "Demo",
+		},
+	},
+}
+
+// seed создает демонстрационных пользователей, которых еще нет в хранилище.
+func seed(log *zerolog.Logger) daemon.SeedFunc {
+	return func(ctx context.Context, storage *db.Storage) error {
+		hash, err := password.Hash(DemoPassword)
+		if err != nil {
+			return err
+		}
+
+		for _, demo := range demoUsers {
+			user := demo
+			user.Password = hash
+
+			exists, err := storage.Users.Exists(ctx, &model.User{Email: user.Email})
+			if err != nil {
+				return fmt.Errorf("exists user: %w", err)
+			}
+
+			if exists {
+				continue
+			}
+
+			err = storage.WithinTx(ctx, func(ctx context.Context) error {
+				// Create заполняет ID, поэтому при повторе транзакции создается копия
+				record := user
+				created, err := storage.Users.Create(ctx, &record)
+				if err != nil {
+					return fmt.Errorf("user create: %w", err)
+				}
+
+				profile := *demo.Profile
+				profile.UserID = created.ID
+
+				if _, err = storage.Profiles.Create(ctx, &profile); err != nil {
+					return fmt.Errorf("profile create: %w", err)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			log.Info().Str("email", user.Email).Str("password", DemoPassword).Msg("demo user created")
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Type тип доменного события.
+type Type string
+
+const (
+	UserRegistered  Type = "user.registered"
+	UserSignedIn    Type = "user.signed_in"
+	UserUpdated     Type = "user.updated"
+	UserBlocked     Type = "user.blocked"
+	UserUnblocked   Type = "user.unblocked"
+	PasswordChanged Type = "user.password_changed"
+	ProfileUpdated  Type = "user.profile_updated"
+)
+
+// AggregateUser тип агрегата пользователя.
+const AggregateUser = "user"
+
+// Event доменное событие.
+type Event struct {
+	ID            string          `json:"id"`
+	Type          Type            `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// User полезные данные событий пользователя.
+type User struct {
+	ID    uint64 `json:"id"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// New создает событие для агрегата с данными payload.
+func New(typ Type, aggregateType string, aggregateID string, payload interface{}) (*Event, error) {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		ID:            hex.EncodeToString(id),
+		Type:          typ,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       buf,
+		OccurredAt:    time.Now().UTC(),
+	}, nil
+}
+
+// NewUser создает событие пользователя.
+func NewUser(typ Type, user User) (*Event, error) {
+	return New(typ, AggregateUser, strconv.FormatUint(user.ID, 10), user)
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"service-template/pkg/flags"
+
+	"github.com/uptrace/bun"
+)
+
+// FeatureFlag флаг функциональности.
+type FeatureFlag struct {
+	bun.BaseModel `bun:"table:feature_flags,alias:ff"`
+	ID            uint64       `bun:"id,pk,autoincrement"`
+	Key           string       `bun:"key,notnull,unique"`
+	Description   string       `bun:"description,nullzero"`
+	Enabled       bool         `bun:"enabled,notnull"`
+	Rules         []flags.Rule `bun:"rules,type:jsonb,notnull"`
+	CreatedAt     *time.Time   `bun:"created_at,notnull,default:current_timestamp"`
+	UpdatedAt     *time.Time   `bun:"updated_at,nullzero"`
+	Version       uint64       `bun:"version,notnull,default:1"`
+}
+
+// Flag возвращает флаг для вычисления.
+func (f *FeatureFlag) Flag() *flags.Flag {
+	return &flags.Flag{
+		Key:     f.Key,
+		Enabled: f.Enabled,
+		Rules:   f.Rules,
+	}
+}
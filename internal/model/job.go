@@ -0,0 +1,35 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Статусы фоновой задачи.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	// JobDead задача исчерпала попытки или завершилась неисправимой ошибкой.
+	JobDead = "dead"
+)
+
+// Job фоновая задача.
+type Job struct {
+	bun.BaseModel `bun:"table:jobs,alias:j"`
+	ID            uint64          `bun:"id,pk,autoincrement"`
+	Kind          string          `bun:"kind,notnull"`
+	Payload       json.RawMessage `bun:"payload,type:jsonb,notnull"`
+	Status        string          `bun:"status,notnull,default:'pending'"`
+	UniqueKey     string          `bun:"unique_key,nullzero"`
+	Attempts      int             `bun:"attempts,notnull,default:0"`
+	MaxAttempts   int             `bun:"max_attempts,notnull,nullzero"`
+	RunAt         time.Time       `bun:"run_at,notnull"`
+	LockedAt      *time.Time      `bun:"locked_at,nullzero"`
+	LockedBy      string          `bun:"locked_by,nullzero"`
+	LastError     string          `bun:"last_error,nullzero"`
+	CreatedAt     *time.Time      `bun:"created_at,notnull,default:current_timestamp"`
+	FinishedAt    *time.Time      `bun:"finished_at,nullzero"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OutboxEvent доменное событие, ожидающее публикации.
+type OutboxEvent struct {
+	bun.BaseModel `bun:"table:outbox,alias:o"`
+	ID            uint64          `bun:"id,pk,autoincrement"`
+	EventID       string          `bun:"event_id,notnull,unique"`
+	Type          string          `bun:"type,notnull"`
+	AggregateType string          `bun:"aggregate_type,notnull"`
+	AggregateID   string          `bun:"aggregate_id,notnull"`
+	Payload       json.RawMessage `bun:"payload,type:jsonb,notnull"`
+	OccurredAt    time.Time       `bun:"occurred_at,notnull"`
+	PublishedAt   *time.Time      `bun:"published_at,nullzero"`
+	Attempts      int             `bun:"attempts,notnull,default:0"`
+	NextAttemptAt time.Time       `bun:"next_attempt_at,notnull,default:current_timestamp"`
+	LastError     string          `bun:"last_error,nullzero"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Статусы запуска периодической задачи.
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// SchedulerRun запуск периодической задачи.
+type SchedulerRun struct {
+	bun.BaseModel `bun:"table:scheduler_runs,alias:sr"`
+	ID            uint64     `bun:"id,pk,autoincrement"`
+	Task          string     `bun:"task,notnull"`
+	ScheduledAt   time.Time  `bun:"scheduled_at,notnull"`
+	Manual        bool       `bun:"manual,notnull"`
+	Instance      string     `bun:"instance,notnull"`
+	Status        string     `bun:"status,notnull"`
+	Error         string     `bun:"error,nullzero"`
+	StartedAt     time.Time  `bun:"started_at,notnull"`
+	FinishedAt    *time.Time `bun:"finished_at,nullzero"`
+}
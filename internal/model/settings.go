@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// UserSettings Пользовательские настройки (локаль, тема, уведомления и т.д.).
+type UserSettings struct {
+	bun.BaseModel `bun:"table:user_settings"`
+	UserID        uint64                 `bun:"user_id,pk"`
+	Settings      map[string]interface{} `bun:"settings,type:jsonb,notnull"`
+	UpdatedAt     *time.Time             `bun:"updated_at,notnull,default:current_timestamp"`
+	Version       uint64                 `bun:"version,notnull,default:1"`
+}
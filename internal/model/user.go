@@ -1,18 +1,25 @@
 package model
 
 import (
+	"context"
 	"time"
 
 	"github.com/uptrace/bun"
 )
 
+// RoleAdmin роль администратора.
+const RoleAdmin = "admin"
+
 // User Структура данных с информацией о пользователе
 type User struct {
 	bun.BaseModel `bun:"table:users"`
 	ID            uint64     `bun:"id,pk,autoincrement"`
-	Email         string     `bun:"email,unique"`
-	Phone         string     `bun:"phone,unique"`
+	Email         string     `bun:"email,unique,nullzero"`
+	Phone         string     `bun:"phone,unique,nullzero"`
 	Password      string     `bun:"password,notnull"`
+	PasswordReset bool       `bun:"password_reset,notnull,default:false"`
+	Roles         []string   `bun:"roles,array"`
+	Version       uint64     `bun:"version,notnull,default:1"`
 	CreatedAt     *time.Time `bun:"created_at,notnull,default:current_timestamp"`
 	UpdatedAt     *time.Time `bun:"updated_at,nullzero"`
 	DeletedAt     *time.Time `bun:"deleted_at,soft_delete,nullzero"`
@@ -21,6 +28,21 @@ type User struct {
 	//Roles         []Role     `bun:"m2m:auth_user_roles,join:User=Role"`
 }
 
+var _ bun.BeforeAppendModelHook = (*User)(nil)
+
+// BeforeAppendModel заменяет пустой список ролей на пустой массив: bun записывает
+// nil-срез как NULL, а колонка roles не допускает NULL.
+func (u *User) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery, *bun.UpdateQuery:
+		if u.Roles == nil {
+			u.Roles = []string{}
+		}
+	}
+
+	return nil
+}
+
 type Profile struct {
 	bun.BaseModel `bun:"table:profiles"`
 	ID            uint64     `bun:"id,pk,autoincrement"`
@@ -28,9 +50,10 @@ type Profile struct {
 	Name          string     `bun:"name"`
 	Surname       string     `bun:"surname"`
 	Patronymic    string     `bun:"patronymic"`
-	Sex           bool       `bun:"sex,nullzero,notnull,default:true"`
+	Sex           bool       `bun:"sex,notnull"`
 	Birthday      *time.Time `bun:"birthday,nullzero"`
 	Country       string     `bun:"country"`
 	City          string     `bun:"city"`
 	Address       string     `bun:"address"`
+	Version       uint64     `bun:"version,notnull,default:1"`
 }
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Статусы доставки вебхука.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookAllEvents тип события, на который подписываются для получения всех событий.
+const WebhookAllEvents = "*"
+
+// Webhook Подписка внешней системы на доменные события.
+type Webhook struct {
+	bun.BaseModel `bun:"table:webhooks,alias:w"`
+	ID            uint64     `bun:"id,pk,autoincrement"`
+	URL           string     `bun:"url,notnull"`
+	EventTypes    []string   `bun:"event_types,array"`
+	Secret        string     `bun:"secret,notnull"`
+	Enabled       bool       `bun:"enabled,notnull"`
+	Failures      int        `bun:"failures,notnull,default:0"`
+	DisabledAt    *time.Time `bun:"disabled_at,nullzero"`
+	CreatedAt     *time.Time `bun:"created_at,notnull,default:current_timestamp"`
+	UpdatedAt     *time.Time `bun:"updated_at,nullzero"`
+}
+
+// WebhookDelivery Доставка события подписчику.
+type WebhookDelivery struct {
+	bun.BaseModel `bun:"table:webhook_deliveries,alias:d"`
+	ID            uint64          `bun:"id,pk,autoincrement"`
+	WebhookID     uint64          `bun:"webhook_id,notnull"`
+	EventID       string          `bun:"event_id,notnull"`
+	EventType     string          `bun:"event_type,notnull"`
+	Payload       json.RawMessage `bun:"payload,type:jsonb,notnull"`
+	Status        string          `bun:"status,notnull,default:'pending'"`
+	Attempts      int             `bun:"attempts,notnull,default:0"`
+	NextAttemptAt time.Time       `bun:"next_attempt_at,notnull,default:current_timestamp"`
+	ResponseCode  int             `bun:"response_code,nullzero"`
+	ResponseBody  string          `bun:"response_body,nullzero"`
+	Error         string          `bun:"error,nullzero"`
+	CreatedAt     *time.Time      `bun:"created_at,notnull,default:current_timestamp"`
+	DeliveredAt   *time.Time      `bun:"delivered_at,nullzero"`
+	Webhook       *Webhook        `bun:"rel:belongs-to,join:webhook_id=id"`
+}
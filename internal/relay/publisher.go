@@ -0,0 +1,74 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+
+	"service-template/internal/config/outbox"
+	"service-template/internal/events"
+	"service-template/pkg/broker"
+
+	"github.com/rs/zerolog"
+)
+
+// Publisher публикует события во внешний брокер сообщений.
+type Publisher interface {
+	Publish(ctx context.Context, event *events.Event) error
+}
+
+// NewPublisher создает издателя, указанного в конфигурации.
+func NewPublisher(cfg *outbox.Config, b broker.Publisher, log *zerolog.Logger) (Publisher, error) {
+	switch cfg.Publisher {
+	case outbox.PublisherLog:
+		return &logPublisher{log: log}, nil
+	case outbox.PublisherBroker:
+		return &brokerPublisher{broker: b, topic: cfg.Topic}, nil
+	}
+
+	return nil, fmt.Errorf("unknown outbox publisher %q", cfg.Publisher)
+}
+
+// logPublisher пишет события в лог. Используется для разработки.
+type logPublisher struct {
+	log *zerolog.Logger
+}
+
+func (p *logPublisher) Publish(_ context.Context, event *events.Event) error {
+	p.log.Info().
+		Str("event_id", event.ID).
+		Str("type", string(event.Type)).
+		Str("aggregate", event.AggregateType+":"+event.AggregateID).
+		RawJSON("payload", event.Payload).
+		Msg("event published")
+
+	return nil
+}
+
+// brokerPublisher публикует события в топик брокера сообщений.
+type brokerPublisher struct {
+	broker broker.Publisher
+	topic  string
+}
+
+func (p *brokerPublisher) Publish(ctx context.Context, event *events.Event) error {
+	return broker.PublishJSON(ctx, p.broker, p.topic, event)
+}
+
+// multiPublisher публикует событие последовательно во все издатели.
+type multiPublisher []Publisher
+
+// Multi объединяет издателей. Публикация считается успешной, только если успешны все.
+// При повторе событие может быть опубликовано в часть издателей повторно.
+func Multi(publishers ...Publisher) Publisher {
+	return multiPublisher(publishers)
+}
+
+func (m multiPublisher) Publish(ctx context.Context, event *events.Event) error {
+	for _, publisher := range m {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
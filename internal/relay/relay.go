@@ -0,0 +1,96 @@
+package relay
+
+import (
+	"context"
+	"time"
+
+	"service-template/internal/config/outbox"
+	"service-template/internal/db"
+	"service-template/internal/events"
+	"service-template/internal/model"
+	"service-template/pkg/runner"
+
+	"github.com/rs/zerolog"
+)
+
+// Relay периодически публикует события из таблицы outbox.
+// Событие отмечается опубликованным только после успешной публикации,
+// поэтому доставка выполняется как минимум один раз.
+type Relay struct {
+	cfg       *outbox.Config
+	log       *zerolog.Logger
+	storage   *db.Storage
+	publisher Publisher
+}
+
+func NewRelay(cfg *outbox.Config, log *zerolog.Logger, storage *db.Storage, publisher Publisher) *Relay {
+	return &Relay{
+		cfg:       cfg,
+		log:       log,
+		storage:   storage,
+		publisher: publisher,
+	}
+}
+
+// Run публикует события до отмены контекста.
+func (r *Relay) Run(ctx context.Context) {
+	// Публикуем пачки, пока очередь не опустеет
+	runner.Poll(ctx, r.cfg.Interval, func(ctx context.Context) (bool, error) {
+		n, err := r.relay(ctx)
+		return n >= r.cfg.Batch, err
+	}, func(err error) {
+		r.log.Error().Err(err).Msg("outbox relay")
+	})
+}
+
+// relay публикует одну пачку событий и возвращает количество обработанных.
+// События занимаются в короткой транзакции, а публикуются вне ее, чтобы повтор транзакции
+// при конфликте не публиковал их заново и транзакция не ждала брокер.
+func (r *Relay) relay(ctx context.Context) (int, error) {
+	var rows []*model.OutboxEvent
+
+	err := r.storage.WithinTx(ctx, func(ctx context.Context) (err error) {
+		rows, err = r.storage.Outbox.Claim(ctx, r.cfg.Batch, time.Now().Add(r.cfg.ClaimTimeout))
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		if err = r.publish(ctx, row); err != nil {
+			return len(rows), err
+		}
+	}
+
+	return len(rows), nil
+}
+
+// publish публикует событие и сохраняет результат публикации.
+func (r *Relay) publish(ctx context.Context, row *model.OutboxEvent) error {
+	err := r.publisher.Publish(ctx, toEvent(row))
+	if err == nil {
+		return r.storage.Outbox.MarkPublished(ctx, row.ID)
+	}
+
+	next := time.Now().Add(runner.Backoff(r.cfg.Interval, r.cfg.MaxBackoff, row.Attempts+1))
+
+	r.log.Warn().Err(err).
+		Str("event_id", row.EventID).
+		Int("attempts", row.Attempts+1).
+		Time("next_attempt_at", next).
+		Msg("event publish failed")
+
+	return r.storage.Outbox.MarkFailed(ctx, row.ID, next, err.Error())
+}
+
+func toEvent(row *model.OutboxEvent) *events.Event {
+	return &events.Event{
+		ID:            row.EventID,
+		Type:          events.Type(row.Type),
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		Payload:       row.Payload,
+		OccurredAt:    row.OccurredAt,
+	}
+}
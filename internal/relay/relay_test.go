@@ -0,0 +1,204 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"service-template/internal/config"
+	"service-template/internal/config/outbox"
+	"service-template/internal/db"
+	"service-template/internal/db/dbtest"
+	"service-template/internal/events"
+	"service-template/pkg/txmanager"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder запоминает опубликованные события и возвращает ошибку для событий из fail.
+type recorder struct {
+	mu        sync.Mutex
+	published []string
+	fail      map[string]bool
+}
+
+func (r *recorder) Publish(_ context.Context, event *events.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fail[event.ID] {
+		return errors.New("broker unavailable")
+	}
+
+	r.published = append(r.published, event.ID)
+
+	return nil
+}
+
+func (r *recorder) events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.published...)
+}
+
+func newRelay(t *testing.T, publisher Publisher) (*Relay, *db.Storage) {
+	t.Helper()
+
+	log := zerolog.Nop()
+
+	cfg := outbox.NewConfig()
+	cfg.Enabled = true
+	cfg.Interval = 10 * time.Millisecond
+	cfg.Batch = 2
+
+	storage := db.NewSQLStorage(&config.Config{Outbox: cfg, Tx: txmanager.NewConfig()}, &log, dbtest.SQLite(t))
+
+	return NewRelay(cfg, &log, storage, publisher), storage
+}
+
+func newEvent(t *testing.T, typ events.Type, userID uint64) *events.Event {
+	event, err := events.NewUser(typ, events.User{ID: userID})
+	require.NoError(t, err)
+
+	event.OccurredAt = event.OccurredAt.Add(-time.Second)
+
+	return event
+}
+
+func TestRelay_relay(t *testing.T) {
+	publisher := &recorder{fail: map[string]bool{}}
+	relay, storage := newRelay(t, publisher)
+	ctx := context.Background()
+
+	first := newEvent(t, events.UserRegistered, 1)
+	second := newEvent(t, events.UserSignedIn, 1)
+	failed := newEvent(t, events.UserRegistered, 2)
+	require.NoError(t, storage.Outbox.Add(ctx, first, second, failed))
+
+	publisher.fail[failed.ID] = true
+
+	n, err := relay.relay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{first.ID}, publisher.events())
+
+	// Неудачная публикация откладывается, следующее событие агрегата публикуется после предыдущего
+	rows, err := storage.Outbox.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, second.ID, rows[0].EventID)
+
+	n, err = relay.relay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{first.ID, second.ID}, publisher.events())
+
+	n, err = relay.relay(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "failed event waits for backoff")
+}
+
+func TestRelay_Run(t *testing.T) {
+	publisher := &recorder{}
+	relay, storage := newRelay(t, publisher)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var added []string
+	for i := uint64(1); i <= 5; i++ {
+		event := newEvent(t, events.UserRegistered, i)
+		require.NoError(t, storage.Outbox.Add(ctx, event))
+		added = append(added, event.ID)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+
+	// Пачки публикуются подряд, пока очередь не опустеет
+	require.Eventually(t, func() bool {
+		return len(publisher.events()) == len(added)
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, added, publisher.events())
+
+	cancel()
+	<-done
+}
+
+func TestRelay_backoff(t *testing.T) {
+	publisher := &recorder{fail: map[string]bool{}}
+	relay, storage := newRelay(t, publisher)
+	ctx := context.Background()
+
+	event := newEvent(t, events.UserRegistered, 1)
+	require.NoError(t, storage.Outbox.Add(ctx, event))
+
+	publisher.fail[event.ID] = true
+
+	_, err := relay.relay(ctx)
+	require.NoError(t, err)
+
+	var row struct {
+		Attempts      int
+		LastError     string
+		NextAttemptAt time.Time
+	}
+	err = storage.DB().NewSelect().Table("outbox").
+		Column("attempts", "last_error", "next_attempt_at").
+		Where("event_id = ?", event.ID).
+		Scan(ctx, &row.Attempts, &row.LastError, &row.NextAttemptAt)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, row.Attempts)
+	assert.Equal(t, "broker unavailable", row.LastError)
+	assert.WithinDuration(t, time.Now().Add(relay.cfg.Interval), row.NextAttemptAt, time.Second)
+}
+
+// blocking публикует события, ожидая release.
+type blocking struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blocking) Publish(context.Context, *events.Event) error {
+	close(b.started)
+	<-b.release
+
+	return nil
+}
+
+func TestRelay_claim(t *testing.T) {
+	publisher := &blocking{started: make(chan struct{}), release: make(chan struct{})}
+	relay, storage := newRelay(t, publisher)
+	ctx := context.Background()
+
+	require.NoError(t, storage.Outbox.Add(ctx, newEvent(t, events.UserRegistered, 1)))
+
+	done := make(chan error)
+	go func() {
+		_, err := relay.relay(ctx)
+		done <- err
+	}()
+
+	<-publisher.started
+
+	// Публикуемое событие занято и не возвращается, а транзакция не удерживается во время публикации
+	n, err := relay.relay(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	close(publisher.release)
+	require.NoError(t, <-done)
+
+	published, err := storage.DB().NewSelect().Table("outbox").Where("published_at IS NOT NULL").Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+}
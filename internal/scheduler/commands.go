@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"service-template/internal/config"
+	"service-template/internal/db"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// TasksFunc возвращает периодические задачи сервиса.
+type TasksFunc func(cfg *config.Config, log *zerolog.Logger, storage *db.Storage) []Task
+
+// Commands возвращает команды просмотра расписания и ручного запуска задач.
+func Commands(tasks TasksFunc) *cli.Command {
+	var cfg *config.Config
+
+	// open создает хранилище и планировщик с зарегистрированными задачами
+	open := func(ctx context.Context) (*Scheduler, *db.Storage, error) {
+		storage, err := db.NewStorage(ctx, cfg, &log.Logger)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		s := New(cfg.Scheduler, &log.Logger, storage)
+		if err = s.Register(tasks(cfg, &log.Logger, storage)...); err != nil {
+			storage.Close()
+			return nil, nil, err
+		}
+
+		return s, storage, nil
+	}
+
+	return &cli.Command{
+		Name:  "scheduler",
+		Usage: "periodic tasks management",
+		Before: func(c *cli.Context) error {
+			var err error
+			if cfg, err = config.New(c.String("config")); err != nil {
+				return err
+			}
+
+			return cfg.Validate()
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "print tasks schedule and last runs",
+				Action: func(c *cli.Context) error {
+					s, storage, err := open(c.Context)
+					if err != nil {
+						return err
+					}
+					defer storage.Close()
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "TASK\tSCHEDULE\tNEXT\tLAST RUN\tSTATUS")
+
+					for _, task := range s.Tasks() {
+						last, status := "-", "-"
+
+						run, err := storage.Scheduler.Last(c.Context, task.Name)
+						if err != nil {
+							return err
+						}
+
+						if run != nil {
+							last, status = run.ScheduledAt.Format(time.RFC3339), run.Status
+						}
+
+						fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+							task.Name, task.Schedule, task.Next.Format(time.RFC3339), last, status)
+					}
+
+					return w.Flush()
+				},
+			},
+			{
+				Name:      "run",
+				Usage:     "run task now",
+				ArgsUsage: "TASK",
+				Action: func(c *cli.Context) error {
+					name := c.Args().First()
+					if name == "" {
+						return fmt.Errorf("task name is required")
+					}
+
+					s, storage, err := open(c.Context)
+					if err != nil {
+						return err
+					}
+					defer storage.Close()
+
+					return s.Trigger(c.Context, name)
+				},
+			},
+			{
+				Name:      "history",
+				Usage:     "print recent task runs",
+				ArgsUsage: "[TASK]",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "maximum number of runs",
+						Value: 50,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					storage, err := db.NewStorage(c.Context, cfg, &log.Logger)
+					if err != nil {
+						return err
+					}
+					defer storage.Close()
+
+					list, err := storage.Scheduler.List(c.Context, c.Args().First(), c.Int("limit"))
+					if err != nil {
+						return err
+					}
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "ID\tTASK\tSCHEDULED AT\tMANUAL\tINSTANCE\tSTATUS\tDURATION\tERROR")
+
+					for _, run := range list {
+						duration := "-"
+						if run.FinishedAt != nil {
+							duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond).String()
+						}
+
+						fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\t%s\t%s\t%s\n",
+							run.ID, run.Task, run.ScheduledAt.Format(time.RFC3339), run.Manual,
+							run.Instance, run.Status, duration, run.Error)
+					}
+
+					return w.Flush()
+				},
+			},
+		},
+	}
+}
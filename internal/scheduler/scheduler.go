@@ -0,0 +1,309 @@
+// Package scheduler запускает периодические задачи по cron расписанию.
+// Каждый запуск выполняется одним экземпляром сервиса: запуски сериализуются
+// блокировкой, а история запусков в Postgres не дает выполнить один запуск дважды.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	cfgscheduler "service-template/internal/config/scheduler"
+	"service-template/internal/db"
+	"service-template/internal/model"
+	"service-template/pkg/lock"
+	"service-template/pkg/runner"
+
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog"
+)
+
+// Политики пропущенных запусков, например, пока все экземпляры сервиса были остановлены.
+const (
+	// MissedSkip пропущенные запуски не выполняются.
+	MissedSkip = "skip"
+	// MissedRunOnce при старте выполняется один последний пропущенный запуск.
+	MissedRunOnce = "run_once"
+)
+
+var (
+	ErrUnknownTask = errors.New("unknown task")
+	ErrLocked      = errors.New("task is running on another instance")
+)
+
+// Task периодическая задача.
+type Task struct {
+	Name string
+	// Schedule cron выражение из пяти полей или дескриптор вида @daily, @every 1h.
+	Schedule string
+	// Missed политика пропущенных запусков, по умолчанию MissedSkip.
+	Missed string
+	// Timeout максимальное время выполнения, по умолчанию из конфигурации.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Info расписание задачи.
+type Info struct {
+	Name     string
+	Schedule string
+	Next     time.Time
+}
+
+type entry struct {
+	task     Task
+	schedule cron.Schedule
+	next     time.Time
+}
+
+// Scheduler запускает зарегистрированные задачи по расписанию.
+type Scheduler struct {
+	cfg     *cfgscheduler.Config
+	log     *zerolog.Logger
+	storage *db.Storage
+	locker  lock.Locker
+	entries []*entry
+	runner  *runner.Runner
+}
+
+func New(cfg *cfgscheduler.Config, log *zerolog.Logger, storage *db.Storage) *Scheduler {
+	var locker lock.Locker
+	if cfg.Locker == cfgscheduler.LockerPostgres {
+		locker = lock.NewPostgres(storage.DB().DB, cfg.LockTTL/3)
+	} else {
+		locker = lock.NewRedis(storage.Redis(), cfg.LockTTL)
+	}
+
+	return &Scheduler{
+		cfg:     cfg,
+		log:     log,
+		storage: storage,
+		locker:  locker,
+		runner:  runner.New(0),
+	}
+}
+
+// Register регистрирует задачи. Расписание из конфигурации имеет приоритет
+// над расписанием в коде, задачи с расписанием "-" пропускаются. Вызывается до Run.
+func (s *Scheduler) Register(tasks ...Task) error {
+	for _, task := range tasks {
+		if spec, ok := s.cfg.Schedules[task.Name]; ok {
+			task.Schedule = spec
+		}
+
+		if task.Schedule == cfgscheduler.Disabled {
+			continue
+		}
+
+		schedule, err := cron.ParseStandard(task.Schedule)
+		if err != nil {
+			return fmt.Errorf("task %s schedule %q: %w", task.Name, task.Schedule, err)
+		}
+
+		if task.Missed == "" {
+			task.Missed = MissedSkip
+		}
+
+		if task.Timeout <= 0 {
+			task.Timeout = s.cfg.Timeout
+		}
+
+		s.entries = append(s.entries, &entry{task: task, schedule: schedule})
+	}
+
+	return nil
+}
+
+// Tasks возвращает расписание зарегистрированных задач.
+func (s *Scheduler) Tasks() []Info {
+	now := time.Now()
+
+	list := make([]Info, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, Info{Name: e.task.Name, Schedule: e.task.Schedule, Next: e.schedule.Next(now)})
+	}
+
+	return list
+}
+
+// Run запускает задачи по расписанию до вызова Close или отмены контекста.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ctx, finish := s.runner.Start(ctx)
+	defer finish()
+
+	for _, e := range s.entries {
+		if err := s.plan(ctx, e); err != nil {
+			return fmt.Errorf("plan task %s: %w", e.task.Name, err)
+		}
+
+		s.log.Info().Str("task", e.task.Name).Str("schedule", e.task.Schedule).Time("next", e.next).Msg("task scheduled")
+	}
+
+	if len(s.entries) == 0 {
+		<-ctx.Done()
+		return nil
+	}
+
+	for {
+		sort.Slice(s.entries, func(i, j int) bool {
+			return s.entries[i].next.Before(s.entries[j].next)
+		})
+
+		timer := time.NewTimer(time.Until(s.entries[0].next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case now := <-timer.C:
+			for _, e := range s.entries {
+				if e.next.After(now) {
+					break
+				}
+
+				scheduledAt := e.next
+				e.next = e.schedule.Next(now)
+
+				task := e.task
+
+				s.runner.Go(func() {
+					if err := s.execute(task, scheduledAt, false); err != nil && !errors.Is(err, ErrLocked) {
+						s.log.Error().Err(err).Str("task", task.Name).Msg("scheduler task")
+					}
+				})
+			}
+		}
+	}
+}
+
+// Close прекращает запуск задач и ожидает завершения выполняемых,
+// но не дольше, чем до отмены ctx.
+func (s *Scheduler) Close(ctx context.Context) error {
+	if err := s.runner.Close(ctx); err != nil {
+		return fmt.Errorf("scheduler drain: %w", err)
+	}
+
+	return nil
+}
+
+// Trigger выполняет задачу вне расписания и ожидает ее завершения.
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	for _, e := range s.entries {
+		if e.task.Name == name {
+			return s.execute(e.task, time.Now(), true)
+		}
+	}
+
+	return ErrUnknownTask
+}
+
+// plan вычисляет время первого запуска задачи с учетом политики пропущенных запусков.
+func (s *Scheduler) plan(ctx context.Context, e *entry) error {
+	now := time.Now()
+	e.next = e.schedule.Next(now)
+
+	if e.task.Missed != MissedRunOnce {
+		return nil
+	}
+
+	last, err := s.storage.Scheduler.Last(ctx, e.task.Name)
+	if err != nil || last == nil {
+		return err
+	}
+
+	// Последний запуск по расписанию, который должен был произойти после last
+	missed := time.Time{}
+	for t := e.schedule.Next(last.ScheduledAt); t.Before(now); t = e.schedule.Next(t) {
+		missed = t
+	}
+
+	if !missed.IsZero() {
+		e.next = missed
+	}
+
+	return nil
+}
+
+// execute выполняет запуск задачи, если он не выполняется и не был выполнен другим экземпляром.
+func (s *Scheduler) execute(task Task, scheduledAt time.Time, manual bool) error {
+	ctx := context.Background()
+	log := s.log.With().Str("task", task.Name).Time("scheduled_at", scheduledAt).Logger()
+
+	l, err := s.locker.TryLock(ctx, "scheduler:"+task.Name)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		log.Debug().Msg("task is running on another instance")
+		return ErrLocked
+	}
+
+	if err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+
+	defer func() {
+		if err := l.Unlock(ctx); err != nil {
+			log.Error().Err(err).Msg("scheduler unlock")
+		}
+	}()
+
+	run := &model.SchedulerRun{
+		Task:        task.Name,
+		ScheduledAt: scheduledAt,
+		Manual:      manual,
+		Instance:    s.cfg.Instance,
+	}
+
+	ok, err := s.storage.Scheduler.Start(ctx, run)
+	if err != nil {
+		return fmt.Errorf("run start: %w", err)
+	}
+
+	if !ok {
+		log.Debug().Msg("task already executed by another instance")
+		return nil
+	}
+
+	log.Info().Bool("manual", manual).Msg("task started")
+
+	runCtx, cancel := context.WithTimeout(ctx, task.Timeout)
+	defer cancel()
+
+	// При потере блокировки задача может быть запущена другим экземпляром, прерываем ее
+	go func() {
+		select {
+		case <-l.Lost():
+			log.Warn().Msg("scheduler lock lost")
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	run.Status = model.RunSucceeded
+	if err = call(runCtx, task); err != nil {
+		run.Status = model.RunFailed
+		run.Error = err.Error()
+	}
+
+	if finishErr := s.storage.Scheduler.Finish(ctx, run); finishErr != nil {
+		log.Error().Err(finishErr).Msg("scheduler run finish")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	log.Info().Dur("duration", time.Since(run.StartedAt)).Msg("task finished")
+
+	return nil
+}
+
+func call(ctx context.Context, task Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return task.Run(ctx)
+}
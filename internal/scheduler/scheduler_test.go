@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-template/internal/config"
+	"service-template/internal/config/outbox"
+	cfgscheduler "service-template/internal/config/scheduler"
+	"service-template/internal/db"
+	"service-template/internal/db/dbtest"
+	"service-template/internal/model"
+	"service-template/pkg/lock"
+	"service-template/pkg/txmanager"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScheduler(t *testing.T, schedules map[string]string) (*Scheduler, *db.Storage) {
+	t.Helper()
+
+	log := zerolog.Nop()
+
+	cfg := cfgscheduler.NewConfig()
+	cfg.Instance = "instance-1"
+	cfg.Schedules = schedules
+
+	storage := db.NewSQLStorage(&config.Config{Outbox: outbox.NewConfig(), Tx: txmanager.NewConfig()}, &log, dbtest.SQLite(t))
+
+	s := New(cfg, &log, storage)
+	s.locker = lock.NewRedis(redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}), cfg.LockTTL)
+
+	return s, storage
+}
+
+func runs(t *testing.T, storage *db.Storage, task string) []*model.SchedulerRun {
+	t.Helper()
+
+	list, err := storage.Scheduler.List(context.Background(), task, 10)
+	require.NoError(t, err)
+
+	return list
+}
+
+func TestScheduler_Register(t *testing.T) {
+	s, _ := newScheduler(t, map[string]string{"override": "@hourly", "disabled": cfgscheduler.Disabled})
+	noop := func(context.Context) error { return nil }
+
+	require.NoError(t, s.Register(
+		Task{Name: "daily", Schedule: "0 3 * * *", Run: noop},
+		Task{Name: "override", Schedule: "0 3 * * *", Run: noop},
+		Task{Name: "disabled", Schedule: "0 3 * * *", Run: noop},
+	))
+
+	tasks := s.Tasks()
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "0 3 * * *", tasks[0].Schedule)
+	assert.Equal(t, "@hourly", tasks[1].Schedule, "schedule from config")
+	assert.Equal(t, MissedSkip, s.entries[0].task.Missed)
+	assert.Equal(t, s.cfg.Timeout, s.entries[0].task.Timeout)
+
+	assert.Error(t, s.Register(Task{Name: "invalid", Schedule: "every day", Run: noop}))
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	s, storage := newScheduler(t, nil)
+
+	require.NoError(t, s.Register(
+		Task{Name: "ok", Schedule: "@daily", Run: func(context.Context) error { return nil }},
+		Task{Name: "failed", Schedule: "@daily", Run: func(context.Context) error { return errors.New("failed") }},
+		Task{Name: "panic", Schedule: "@daily", Run: func(context.Context) error { panic("boom") }},
+	))
+
+	require.NoError(t, s.Trigger(context.Background(), "ok"))
+	assert.EqualError(t, s.Trigger(context.Background(), "failed"), "failed")
+	assert.EqualError(t, s.Trigger(context.Background(), "panic"), "panic: boom")
+	assert.ErrorIs(t, s.Trigger(context.Background(), "missing"), ErrUnknownTask)
+
+	list := runs(t, storage, "ok")
+	require.Len(t, list, 1)
+	assert.Equal(t, model.RunSucceeded, list[0].Status)
+	assert.True(t, list[0].Manual)
+	assert.Equal(t, "instance-1", list[0].Instance)
+	assert.NotNil(t, list[0].FinishedAt)
+
+	list = runs(t, storage, "failed")
+	require.Len(t, list, 1)
+	assert.Equal(t, model.RunFailed, list[0].Status)
+	assert.Equal(t, "failed", list[0].Error)
+}
+
+func TestScheduler_execute(t *testing.T) {
+	s, storage := newScheduler(t, nil)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	task := Task{Name: "task", Timeout: time.Second, Run: func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}}
+
+	// Задача выполняется другим экземпляром
+	l, err := s.locker.TryLock(ctx, "scheduler:task")
+	require.NoError(t, err)
+
+	scheduledAt := time.Now().Truncate(time.Second)
+	assert.ErrorIs(t, s.execute(task, scheduledAt, false), ErrLocked)
+	require.NoError(t, l.Unlock(ctx))
+
+	// Запуск на одно и то же время выполняется один раз
+	require.NoError(t, s.execute(task, scheduledAt, false))
+	require.NoError(t, s.execute(task, scheduledAt, false))
+
+	assert.EqualValues(t, 1, calls.Load())
+	assert.Len(t, runs(t, storage, "task"), 1)
+}
+
+func TestScheduler_plan(t *testing.T) {
+	s, storage := newScheduler(t, nil)
+	ctx := context.Background()
+	noop := func(context.Context) error { return nil }
+
+	require.NoError(t, s.Register(
+		Task{Name: "skip", Schedule: "@daily", Run: noop},
+		Task{Name: "run_once", Schedule: "@daily", Missed: MissedRunOnce, Run: noop},
+		Task{Name: "new", Schedule: "@daily", Missed: MissedRunOnce, Run: noop},
+	))
+
+	last := time.Now().AddDate(0, 0, -3).Truncate(24 * time.Hour)
+	for _, name := range []string{"skip", "run_once"} {
+		_, err := storage.Scheduler.Start(ctx, &model.SchedulerRun{Task: name, ScheduledAt: last, Instance: "instance-1"})
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	for _, e := range s.entries {
+		require.NoError(t, s.plan(ctx, e))
+	}
+
+	assert.True(t, s.entries[0].next.After(now), "missed runs are skipped")
+	assert.True(t, s.entries[1].next.Before(now), "last missed run is planned immediately")
+	assert.True(t, s.entries[1].next.After(last.AddDate(0, 0, 1)), "only the last missed run")
+	assert.True(t, s.entries[2].next.After(now), "task without history")
+}
+
+func TestScheduler_Run(t *testing.T) {
+	s, storage := newScheduler(t, nil)
+
+	done := make(chan struct{}, 1)
+	require.NoError(t, s.Register(Task{Name: "task", Schedule: "@every 1s", Run: func(context.Context) error {
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+
+		return nil
+	}}))
+
+	go s.Run(context.Background())
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("task not executed")
+	}
+
+	require.NoError(t, s.Close(context.Background()))
+
+	list := runs(t, storage, "task")
+	require.Len(t, list, 1)
+	assert.False(t, list[0].Manual)
+}
@@ -0,0 +1,222 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader последовательно читает записи. В конце файла возвращает io.EOF.
+type Reader interface {
+	Read() (*Record, error)
+}
+
+// Writer последовательно записывает записи.
+type Writer interface {
+	Write(record *Record) error
+	Flush() error
+}
+
+// RecordError ошибка разбора отдельной записи. Чтение можно продолжить.
+type RecordError struct {
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+var csvHeader = []string{"email", "phone", "password", "roles", "created_at", "blocked_at", "password_reset"}
+
+// csvRolesSeparator разделитель ролей внутри колонки CSV.
+const csvRolesSeparator = ";"
+
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+		return &jsonlReader{scanner: scanner}, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+
+		return &csvWriter{writer: writer}, writer.Write(csvHeader)
+	case FormatJSONL:
+		buf := bufio.NewWriter(w)
+
+		return &jsonlWriter{buf: buf, encoder: json.NewEncoder(buf)}, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Read() (*Record, error) {
+	row, err := r.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &RecordError{Err: err}
+		}
+
+		return nil, err
+	}
+
+	get := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+
+		return ""
+	}
+
+	record := Record{
+		Email:    get("email"),
+		Phone:    get("phone"),
+		Password: get("password"),
+	}
+
+	if roles := get("roles"); roles != "" {
+		record.Roles = strings.Split(roles, csvRolesSeparator)
+	}
+
+	if record.CreatedAt, err = parseTime(get("created_at")); err != nil {
+		return nil, &RecordError{Err: fmt.Errorf("created_at: %w", err)}
+	}
+
+	if record.BlockedAt, err = parseTime(get("blocked_at")); err != nil {
+		return nil, &RecordError{Err: fmt.Errorf("blocked_at: %w", err)}
+	}
+
+	if reset := get("password_reset"); reset != "" {
+		if record.PasswordReset, err = strconv.ParseBool(reset); err != nil {
+			return nil, &RecordError{Err: fmt.Errorf("password_reset: %w", err)}
+		}
+	}
+
+	return &record, nil
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(record *Record) error {
+	return w.writer.Write([]string{
+		record.Email,
+		record.Phone,
+		record.Password,
+		strings.Join(record.Roles, csvRolesSeparator),
+		formatTime(record.CreatedAt),
+		formatTime(record.BlockedAt),
+		strconv.FormatBool(record.PasswordReset),
+	})
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *jsonlReader) Read() (*Record, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		record := Record{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, &RecordError{Err: err}
+		}
+
+		return &record, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+type jsonlWriter struct {
+	buf     *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (w *jsonlWriter) Write(record *Record) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonlWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
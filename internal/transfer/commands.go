@@ -0,0 +1,174 @@
+package transfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"service-template/internal/config"
+	storage "service-template/internal/db"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// UsersCommands возвращает команды импорта и экспорта пользователей.
+func UsersCommands() *cli.Command {
+	var cfg *config.Config
+
+	formatFlag := &cli.StringFlag{
+		Name:  "format",
+		Usage: "file format: csv or jsonl (detected by file extension, jsonl if there is none)",
+	}
+
+	batchFlag := &cli.IntFlag{
+		Name:  "batch",
+		Usage: "number of users per batch",
+		Value: 500,
+	}
+
+	return &cli.Command{
+		Name:  "users",
+		Usage: "users import and export",
+		Before: func(c *cli.Context) error {
+			var err error
+			if cfg, err = config.New(c.String("config")); err != nil {
+				return err
+			}
+
+			return cfg.Validate()
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:      "import",
+				Usage:     "import users from CSV or JSON Lines file",
+				ArgsUsage: "FILE",
+				Flags: []cli.Flag{
+					formatFlag,
+					batchFlag,
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "validate the file and print a report without writing to the database",
+					},
+					&cli.BoolFlag{
+						Name:  "reset-passwords",
+						Usage: "ignore password hashes and require users to reset their passwords",
+					},
+					&cli.BoolFlag{
+						Name:  "resume",
+						Usage: "continue a failed import from the last committed batch",
+					},
+					&cli.PathFlag{
+						Name:  "state",
+						Usage: "import state `FILE` (default: FILE.state)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					path := c.Args().First()
+					if path == "" {
+						return fmt.Errorf("import file is required")
+					}
+
+					format, err := ParseFormat(c.String("format"), path)
+					if err != nil {
+						return err
+					}
+
+					file, err := os.Open(path)
+					if err != nil {
+						return err
+					}
+					defer file.Close()
+
+					reader, err := NewReader(file, format)
+					if err != nil {
+						return err
+					}
+
+					db, err := storage.Open(c.Context, cfg, &log.Logger)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					stateFile := c.Path("state")
+					if stateFile == "" {
+						stateFile = path + ".state"
+					}
+
+					report, err := NewImporter(db, &log.Logger).Import(c.Context, reader, ImportOptions{
+						Batch:          c.Int("batch"),
+						DryRun:         c.Bool("dry-run"),
+						ResetPasswords: c.Bool("reset-passwords"),
+						StateFile:      stateFile,
+						Resume:         c.Bool("resume"),
+					})
+
+					if report != nil {
+						encoder := json.NewEncoder(os.Stdout)
+						encoder.SetIndent("", "  ")
+						if encodeErr := encoder.Encode(report); encodeErr != nil {
+							return encodeErr
+						}
+					}
+
+					if err != nil {
+						return fmt.Errorf("import failed, run with --resume to continue: %w", err)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:      "export",
+				Usage:     "export users to CSV or JSON Lines file",
+				ArgsUsage: "[FILE]",
+				Flags: []cli.Flag{
+					formatFlag,
+					batchFlag,
+				},
+				Action: func(c *cli.Context) error {
+					path := c.Args().First()
+
+					// Формат проверяется до создания файла, чтобы не оставить пустой файл
+					format, err := ParseFormat(c.String("format"), path)
+					if err != nil {
+						return err
+					}
+
+					var out io.Writer = os.Stdout
+					if path != "" && path != "-" {
+						file, err := os.Create(path)
+						if err != nil {
+							return err
+						}
+						defer file.Close()
+
+						out = file
+					}
+
+					writer, err := NewWriter(out, format)
+					if err != nil {
+						return err
+					}
+
+					db, err := storage.Open(c.Context, cfg, &log.Logger)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					count, err := Export(c.Context, db, writer, c.Int("batch"))
+					if err != nil {
+						return err
+					}
+
+					log.Info().Msgf("exported %d users", count)
+
+					return nil
+				},
+			},
+		},
+	}
+}
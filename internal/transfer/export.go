@@ -0,0 +1,45 @@
+package transfer
+
+import (
+	"context"
+
+	"service-template/internal/model"
+
+	"github.com/uptrace/bun"
+)
+
+// Export выгружает всех неудаленных пользователей, читая их из БД пачками.
+func Export(ctx context.Context, db *bun.DB, writer Writer, batch int) (int, error) {
+	if batch <= 0 {
+		batch = 500
+	}
+
+	var lastID uint64
+	count := 0
+
+	for {
+		var users []*model.User
+		if err := db.NewSelect().Model(&users).
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(batch).
+			Scan(ctx); err != nil {
+			return count, err
+		}
+
+		for _, user := range users {
+			if err := writer.Write(NewRecord(user)); err != nil {
+				return count, err
+			}
+
+			lastID = user.ID
+			count++
+		}
+
+		if len(users) < batch {
+			break
+		}
+	}
+
+	return count, writer.Flush()
+}
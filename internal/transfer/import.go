@@ -0,0 +1,298 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"service-template/internal/model"
+
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+// ImportOptions параметры импорта пользователей.
+type ImportOptions struct {
+	// Batch количество записей, вставляемых в одной транзакции.
+	Batch int
+	// DryRun только проверяет записи, ничего не записывая в БД.
+	DryRun bool
+	// ResetPasswords не переносит хеши паролей, пользователи должны будут сбросить пароль.
+	ResetPasswords bool
+	// StateFile файл, в котором сохраняется номер последней загруженной записи.
+	StateFile string
+	// Resume продолжает импорт с записи, сохраненной в StateFile.
+	Resume bool
+}
+
+// Report результат импорта.
+type Report struct {
+	Total    int           `json:"total"`
+	Imported int           `json:"imported"`
+	Skipped  int           `json:"skipped"`
+	Invalid  int           `json:"invalid"`
+	Resumed  int           `json:"resumed,omitempty"`
+	Errors   []RecordIssue `json:"errors,omitempty"`
+}
+
+// RecordIssue описание ошибки в записи с указанным порядковым номером.
+type RecordIssue struct {
+	Record int    `json:"record"`
+	Error  string `json:"error"`
+}
+
+// state сохраненное состояние импорта для продолжения после сбоя.
+type state struct {
+	Record int `json:"record"`
+}
+
+type Importer struct {
+	db  *bun.DB
+	log *zerolog.Logger
+}
+
+func NewImporter(db *bun.DB, log *zerolog.Logger) *Importer {
+	return &Importer{
+		db:  db,
+		log: log,
+	}
+}
+
+// Import загружает пользователей пачками, каждая пачка вставляется в отдельной транзакции.
+// Пользователи, чей email или телефон уже заняты, пропускаются.
+func (i *Importer) Import(ctx context.Context, reader Reader, opts ImportOptions) (*Report, error) {
+	report := Report{}
+
+	if opts.Batch <= 0 {
+		opts.Batch = 500
+	}
+
+	if opts.Resume && opts.StateFile != "" {
+		st, err := loadState(opts.StateFile)
+		if err != nil {
+			return nil, fmt.Errorf("load state: %w", err)
+		}
+
+		report.Resumed = st.Record
+	}
+
+	// Дубликаты внутри файла отсекаем до обращения к БД
+	seen := map[string]int{}
+	batch := make([]*model.User, 0, opts.Batch)
+	record := 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		inserted, err := i.insert(ctx, batch, opts.DryRun)
+		if err != nil {
+			return fmt.Errorf("records up to %d: %w", record, err)
+		}
+
+		report.Imported += inserted
+		report.Skipped += len(batch) - inserted
+		batch = batch[:0]
+
+		if !opts.DryRun && opts.StateFile != "" {
+			if err = saveState(opts.StateFile, state{Record: record}); err != nil {
+				return fmt.Errorf("save state: %w", err)
+			}
+		}
+
+		i.log.Info().Int("record", record).Int("imported", report.Imported).Msg("batch imported")
+
+		return nil
+	}
+
+	for {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		record++
+
+		var recordErr *RecordError
+		if err != nil && !errors.As(err, &recordErr) {
+			return &report, err
+		}
+
+		// Записи, загруженные до сбоя, пропускаем
+		if record <= report.Resumed {
+			continue
+		}
+
+		report.Total++
+
+		if err != nil {
+			report.addIssue(record, err)
+			continue
+		}
+
+		if err = rec.Validate(opts.ResetPasswords); err != nil {
+			report.addIssue(record, err)
+			continue
+		}
+
+		if dup := duplicate(seen, rec, record); dup != 0 {
+			report.addIssue(record, fmt.Errorf("duplicate of record %d", dup))
+			continue
+		}
+
+		user, err := rec.ToModel(opts.ResetPasswords)
+		if err != nil {
+			return &report, err
+		}
+
+		if batch = append(batch, user); len(batch) >= opts.Batch {
+			if err = flush(); err != nil {
+				return &report, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return &report, err
+	}
+
+	// Импорт завершен, состояние больше не нужно
+	if !opts.DryRun && opts.StateFile != "" {
+		if err := os.Remove(opts.StateFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return &report, err
+		}
+	}
+
+	return &report, nil
+}
+
+// insert вставляет пачку пользователей в транзакции и возвращает количество вставленных.
+// В режиме dry-run только подсчитывает пользователей, которых нет в БД.
+func (i *Importer) insert(ctx context.Context, batch []*model.User, dryRun bool) (int, error) {
+	if dryRun {
+		emails, phones := make([]string, 0, len(batch)), make([]string, 0, len(batch))
+		for _, user := range batch {
+			if user.Email != "" {
+				emails = append(emails, user.Email)
+			}
+
+			if user.Phone != "" {
+				phones = append(phones, user.Phone)
+			}
+		}
+
+		var existing []model.User
+		err := i.db.NewSelect().Model(&existing).Column("email", "phone").
+			WhereAllWithDeleted().
+			WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				if len(emails) > 0 {
+					q = q.WhereOr("email IN (?)", bun.In(emails))
+				}
+
+				if len(phones) > 0 {
+					q = q.WhereOr("phone IN (?)", bun.In(phones))
+				}
+
+				return q
+			}).
+			Scan(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		taken := map[string]bool{}
+		for _, user := range existing {
+			taken[user.Email] = user.Email != ""
+			taken[user.Phone] = user.Phone != ""
+		}
+
+		inserted := 0
+		for _, user := range batch {
+			if !taken[user.Email] && !taken[user.Phone] {
+				inserted++
+			}
+		}
+
+		return inserted, nil
+	}
+
+	var inserted int64
+
+	err := i.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewInsert().Model(&batch).
+			ExcludeColumn("id", "version", "updated_at", "deleted_at").
+			On("CONFLICT DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		inserted, err = res.RowsAffected()
+
+		return err
+	})
+
+	return int(inserted), err
+}
+
+func (r *Report) addIssue(record int, err error) {
+	r.Invalid++
+	r.Errors = append(r.Errors, RecordIssue{Record: record, Error: err.Error()})
+}
+
+func duplicate(seen map[string]int, rec *Record, record int) int {
+	keys := make([]string, 0, 2)
+	if rec.Email != "" {
+		keys = append(keys, "email:"+rec.Email)
+	}
+
+	if rec.Phone != "" {
+		keys = append(keys, "phone:"+rec.Phone)
+	}
+
+	for _, key := range keys {
+		if dup, ok := seen[key]; ok {
+			return dup
+		}
+	}
+
+	for _, key := range keys {
+		seen[key] = record
+	}
+
+	return 0
+}
+
+func loadState(path string) (state, error) {
+	st := state{}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return st, nil
+		}
+
+		return st, err
+	}
+
+	return st, json.Unmarshal(buf, &st)
+}
+
+func saveState(path string, st state) error {
+	buf, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы не оставить его поврежденным
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, buf, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
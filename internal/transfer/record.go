@@ -0,0 +1,129 @@
+package transfer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"service-template/internal/config/valid"
+	"service-template/internal/model"
+	"service-template/pkg/password"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// Format формат файла импорта и экспорта.
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+var ErrUnknownFormat = errors.New("unknown file format")
+
+// ParseFormat определяет формат по имени или расширению файла.
+// Если не указано ни то, ни другое (например, при выводе в stdout), используется JSON Lines.
+func ParseFormat(format, path string) (Format, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	if format == "" {
+		return FormatJSONL, nil
+	}
+
+	switch strings.ToLower(format) {
+	case "csv":
+		return FormatCSV, nil
+	case "jsonl", "ndjson":
+		return FormatJSONL, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Record пользователь в файле импорта и экспорта.
+// Пароль передается только в виде хеша bcrypt или argon2id.
+type Record struct {
+	Email     string     `json:"email,omitempty"`
+	Phone     string     `json:"phone,omitempty"`
+	Password  string     `json:"password,omitempty"`
+	Roles     []string   `json:"roles,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	BlockedAt *time.Time `json:"blocked_at,omitempty"`
+	// PasswordReset пользователь должен сменить пароль перед входом
+	PasswordReset bool `json:"password_reset,omitempty"`
+}
+
+// NewRecord создает запись из модели пользователя.
+func NewRecord(user *model.User) *Record {
+	return &Record{
+		Email:         user.Email,
+		Phone:         user.Phone,
+		Password:      user.Password,
+		Roles:         user.Roles,
+		CreatedAt:     user.CreatedAt,
+		BlockedAt:     user.BlockedAt,
+		PasswordReset: user.PasswordReset,
+	}
+}
+
+// Validate проверяет запись. Если reset установлен, хеш пароля не обязателен.
+func (r Record) Validate(reset bool) error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Phone, validation.Required.When(r.Email == "").Error("either phone or email is required"), is.Digit),
+		validation.Field(&r.Email, validation.Required.When(r.Phone == "").Error("either phone or email is required"), is.EmailFormat),
+		validation.Field(&r.Password,
+			validation.When(!reset, validation.Required),
+			validation.When(!reset && r.Password != "", validation.By(func(interface{}) error {
+				if !password.IsHash(r.Password) {
+					return errors.New("must be a bcrypt or argon2id hash")
+				}
+
+				return nil
+			})),
+		),
+		validation.Field(&r.Roles, validation.Each(validation.Required, validation.Match(valid.Key))),
+	)
+}
+
+// ToModel создает модель пользователя. Если reset установлен, пароль заменяется
+// случайным и пользователь должен будет его сбросить. Требование сброса из записи сохраняется.
+func (r Record) ToModel(reset bool) (*model.User, error) {
+	user := model.User{
+		Email:         r.Email,
+		Phone:         r.Phone,
+		Password:      r.Password,
+		Roles:         r.Roles,
+		CreatedAt:     r.CreatedAt,
+		BlockedAt:     r.BlockedAt,
+		PasswordReset: r.PasswordReset,
+	}
+
+	if user.Roles == nil {
+		user.Roles = []string{}
+	}
+
+	if reset || r.Password == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+
+		hash, err := password.Hash(hex.EncodeToString(secret))
+		if err != nil {
+			return nil, err
+		}
+
+		user.Password = hash
+		user.PasswordReset = true
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,210 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"service-template/internal/db/dbtest"
+	"service-template/internal/model"
+	"service-template/pkg/password"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		format, path string
+		want         Format
+		err          bool
+	}{
+		{path: "users.csv", want: FormatCSV},
+		{path: "users.JSONL", want: FormatJSONL},
+		{path: "users.ndjson", want: FormatJSONL},
+		{format: "csv", path: "users.jsonl", want: FormatCSV},
+		{path: "", want: FormatJSONL},
+		{path: "users", want: FormatJSONL},
+		{path: "users.xml", err: true},
+		{format: "xml", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.format, tt.path)
+		if tt.err {
+			assert.ErrorIs(t, err, ErrUnknownFormat, "%q %q", tt.format, tt.path)
+			continue
+		}
+
+		require.NoError(t, err, "%q %q", tt.format, tt.path)
+		assert.Equal(t, tt.want, got, "%q %q", tt.format, tt.path)
+	}
+}
+
+func TestCodec(t *testing.T) {
+	hash, err := password.Hash("Password1!")
+	require.NoError(t, err)
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []*Record{
+		{Email: "a@example.com", Password: hash, Roles: []string{"admin", "user"}, CreatedAt: &created},
+		{Phone: "79990000000", Password: hash, BlockedAt: &created, PasswordReset: true},
+	}
+
+	for _, format := range []Format{FormatCSV, FormatJSONL} {
+		t.Run(string(format), func(t *testing.T) {
+			buf := bytes.Buffer{}
+
+			writer, err := NewWriter(&buf, format)
+			require.NoError(t, err)
+
+			for _, record := range records {
+				require.NoError(t, writer.Write(record))
+			}
+			require.NoError(t, writer.Flush())
+
+			reader, err := NewReader(&buf, format)
+			require.NoError(t, err)
+
+			for _, want := range records {
+				got, err := reader.Read()
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+
+			_, err = reader.Read()
+			assert.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestCSVReader_recordError(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("email,created_at,password_reset\n"+
+		"a@example.com,yesterday,\n"+
+		"b@example.com,,maybe\n"+
+		"c@example.com,,\n"), FormatCSV)
+	require.NoError(t, err)
+
+	var recordErr *RecordError
+
+	_, err = reader.Read()
+	assert.True(t, errors.As(err, &recordErr), "created_at: %v", err)
+
+	_, err = reader.Read()
+	assert.True(t, errors.As(err, &recordErr), "password_reset: %v", err)
+
+	record, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "c@example.com", record.Email)
+}
+
+func TestRecord(t *testing.T) {
+	hash, err := password.Hash("Password1!")
+	require.NoError(t, err)
+
+	assert.NoError(t, Record{Email: "a@example.com", Password: hash}.Validate(false))
+	assert.Error(t, Record{Email: "a@example.com", Password: "Password1!"}.Validate(false), "plain password")
+	assert.Error(t, Record{Email: "a@example.com"}.Validate(false), "no password")
+	assert.NoError(t, Record{Email: "a@example.com"}.Validate(true))
+	assert.Error(t, Record{Password: hash}.Validate(false), "no email and phone")
+
+	user, err := Record{Email: "a@example.com", Password: hash}.ToModel(false)
+	require.NoError(t, err)
+	assert.Equal(t, hash, user.Password)
+	assert.False(t, user.PasswordReset)
+	assert.Equal(t, []string{}, user.Roles)
+
+	user, err = Record{Email: "a@example.com", Password: hash, PasswordReset: true}.ToModel(false)
+	require.NoError(t, err)
+	assert.Equal(t, hash, user.Password, "hash is kept")
+	assert.True(t, user.PasswordReset, "reset flag is kept")
+
+	user, err = Record{Email: "a@example.com", Password: hash}.ToModel(true)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, user.Password)
+	assert.True(t, user.PasswordReset)
+}
+
+func TestImportExport(t *testing.T) {
+	db := dbtest.SQLite(t)
+	ctx := context.Background()
+	log := zerolog.Nop()
+
+	hash, err := password.Hash("Password1!")
+	require.NoError(t, err)
+
+	_, err = db.NewInsert().Model(&model.User{Email: "taken@example.com", Password: hash, Roles: []string{}}).Exec(ctx)
+	require.NoError(t, err)
+
+	input := strings.Join([]string{
+		`{"email":"a@example.com","password":"` + hash + `","roles":["admin"]}`,
+		`{"email":"b@example.com","password":"` + hash + `","password_reset":true}`,
+		`{"email":"taken@example.com","password":"` + hash + `"}`,
+		`{"email":"a@example.com","password":"` + hash + `"}`,
+		`{"email":"c@example.com","password":"plain"}`,
+		`not json`,
+	}, "\n")
+
+	reader := func() Reader {
+		r, err := NewReader(strings.NewReader(input), FormatJSONL)
+		require.NoError(t, err)
+
+		return r
+	}
+
+	importer := NewImporter(db, &log)
+
+	report, err := importer.Import(ctx, reader(), ImportOptions{Batch: 2, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Total)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 3, report.Invalid)
+
+	count, err := db.NewSelect().Model((*model.User)(nil)).Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "dry run writes nothing")
+
+	stateFile := filepath.Join(t.TempDir(), "import.state")
+
+	report, err = importer.Import(ctx, reader(), ImportOptions{Batch: 2, StateFile: stateFile})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 1, report.Skipped)
+	assert.NoFileExists(t, stateFile, "state is removed after a complete import")
+
+	buf := bytes.Buffer{}
+	writer, err := NewWriter(&buf, FormatJSONL)
+	require.NoError(t, err)
+
+	count, err = Export(ctx, db, writer, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	exported := map[string]*Record{}
+	out, err := NewReader(&buf, FormatJSONL)
+	require.NoError(t, err)
+
+	for {
+		record, err := out.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		exported[record.Email] = record
+	}
+
+	require.Contains(t, exported, "a@example.com")
+	require.Contains(t, exported, "b@example.com")
+	assert.Equal(t, []string{"admin"}, exported["a@example.com"].Roles)
+	assert.Equal(t, hash, exported["a@example.com"].Password)
+	assert.False(t, exported["a@example.com"].PasswordReset)
+	assert.True(t, exported["b@example.com"].PasswordReset, "reset flag survives export")
+}
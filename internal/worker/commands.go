@@ -0,0 +1,159 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"service-template/internal/config"
+	storage "service-template/internal/db"
+	"service-template/internal/db/jobs"
+	"service-template/internal/model"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// JobsCommands возвращает команды управления фоновыми задачами.
+func JobsCommands() *cli.Command {
+	var cfg *config.Config
+
+	return &cli.Command{
+		Name:  "jobs",
+		Usage: "background jobs management",
+		Before: func(c *cli.Context) error {
+			var err error
+			if cfg, err = config.New(c.String("config")); err != nil {
+				return err
+			}
+
+			return cfg.Validate()
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "print recent jobs",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "status",
+						Usage: "filter by status: pending, running, succeeded or dead",
+					},
+					&cli.StringFlag{
+						Name:  "kind",
+						Usage: "filter by job kind",
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "maximum number of jobs",
+						Value: 50,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					db, err := storage.Open(c.Context, cfg, &log.Logger)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					list, err := jobs.NewStorage(db).List(c.Context, c.String("status"), c.String("kind"), c.Int("limit"))
+					if err != nil {
+						return err
+					}
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "ID\tKIND\tSTATUS\tATTEMPTS\tRUN AT\tLAST ERROR")
+
+					for _, job := range list {
+						fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%s\t%s\n",
+							job.ID, job.Kind, job.Status, job.Attempts, job.MaxAttempts,
+							job.RunAt.Format(time.RFC3339), job.LastError)
+					}
+
+					return w.Flush()
+				},
+			},
+			{
+				Name:      "retry",
+				Usage:     "return dead jobs to the queue",
+				ArgsUsage: "[ID...]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "retry all dead jobs",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					ids := make([]uint64, 0, c.NArg())
+					for _, arg := range c.Args().Slice() {
+						id, err := strconv.ParseUint(arg, 10, 64)
+						if err != nil {
+							return fmt.Errorf("invalid job id %q", arg)
+						}
+
+						ids = append(ids, id)
+					}
+
+					if len(ids) == 0 && !c.Bool("all") {
+						return fmt.Errorf("job ids or --all flag is required")
+					}
+
+					db, err := storage.Open(c.Context, cfg, &log.Logger)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					count, err := jobs.NewStorage(db).Requeue(c.Context, ids)
+					if err != nil {
+						return err
+					}
+
+					log.Info().Msgf("%d jobs returned to the queue", count)
+
+					return nil
+				},
+			},
+			{
+				Name:  "purge",
+				Usage: "delete finished jobs",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "status",
+						Usage: "statuses of jobs to delete: succeeded or dead",
+						Value: cli.NewStringSlice(model.JobSucceeded),
+					},
+					&cli.DurationFlag{
+						Name:  "older-than",
+						Usage: "delete jobs finished earlier than `DURATION` ago",
+						Value: 7 * 24 * time.Hour,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					statuses := c.StringSlice("status")
+					for _, status := range statuses {
+						if status != model.JobSucceeded && status != model.JobDead {
+							return fmt.Errorf("only succeeded and dead jobs can be purged, got %q", status)
+						}
+					}
+
+					db, err := storage.Open(c.Context, cfg, &log.Logger)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					count, err := jobs.NewStorage(db).Purge(c.Context, statuses, time.Now().Add(-c.Duration("older-than")))
+					if err != nil {
+						return err
+					}
+
+					log.Info().Msgf("%d jobs deleted", count)
+
+					return nil
+				},
+			},
+		},
+	}
+}
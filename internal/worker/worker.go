@@ -0,0 +1,280 @@
+// Package worker выполняет фоновые задачи из очереди в Postgres.
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"service-template/internal/config/jobs"
+	"service-template/internal/db"
+	dbjobs "service-template/internal/db/jobs"
+	"service-template/internal/model"
+	"service-template/pkg/runner"
+
+	"github.com/rs/zerolog"
+)
+
+var ErrNoHandlers = errors.New("worker: no handlers registered")
+
+// Handler выполняет задачу. Если обработчик вернул ошибку, задача будет
+// выполнена повторно после задержки, пока не исчерпаны попытки.
+type Handler func(ctx context.Context, job *model.Job) error
+
+// Options параметры постановки задачи в очередь.
+type Options struct {
+	// RunAt время, раньше которого задача не выполняется.
+	RunAt time.Time
+	// MaxAttempts количество попыток, после которого задача переходит в статус dead.
+	// По умолчанию 5.
+	MaxAttempts int
+	// UniqueKey запрещает ставить задачу, пока не завершена задача того же типа с тем же ключом.
+	UniqueKey string
+}
+
+// NewJob создает задачу с данными payload в формате JSON.
+func NewJob(kind string, payload interface{}, opts Options) (*model.Job, error) {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Job{
+		Kind:        kind,
+		Payload:     buf,
+		UniqueKey:   opts.UniqueKey,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+	}, nil
+}
+
+// Pool выполняет задачи зарегистрированных типов с ограничением конкурентности.
+type Pool struct {
+	cfg      *jobs.Config
+	log      *zerolog.Logger
+	storage  *db.Storage
+	handlers map[string]Handler
+	kinds    []string
+	running  sync.Map
+	runner   *runner.Runner
+}
+
+func NewPool(cfg *jobs.Config, log *zerolog.Logger, storage *db.Storage) *Pool {
+	return &Pool{
+		cfg:      cfg,
+		log:      log,
+		storage:  storage,
+		handlers: make(map[string]Handler),
+		runner:   runner.New(cfg.Concurrency),
+	}
+}
+
+// Handle регистрирует обработчик задач типа kind. Вызывается до Run.
+func (p *Pool) Handle(kind string, handler Handler) {
+	if _, ok := p.handlers[kind]; !ok {
+		p.kinds = append(p.kinds, kind)
+	}
+
+	p.handlers[kind] = handler
+}
+
+// HandleJSON регистрирует обработчик, получающий данные задачи, декодированные из JSON.
+// Задачи, данные которых не удалось декодировать, сразу переходят в статус dead.
+func HandleJSON[T any](p *Pool, kind string, fn func(ctx context.Context, payload T) error) {
+	p.Handle(kind, func(ctx context.Context, job *model.Job) error {
+		var payload T
+
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("decode job %d: %w", job.ID, err))
+		}
+
+		return fn(ctx, payload)
+	})
+}
+
+// Run выполняет задачи до вызова Close или отмены контекста.
+func (p *Pool) Run(ctx context.Context) error {
+	ctx, finish := p.runner.Start(ctx)
+	defer finish()
+
+	if len(p.kinds) == 0 {
+		return ErrNoHandlers
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		p.lockLoop(ctx)
+	}()
+
+	p.fetchLoop(ctx)
+	wg.Wait()
+
+	return nil
+}
+
+// Close прекращает получение новых задач и ожидает завершения выполняемых,
+// но не дольше, чем до отмены ctx. Невыполненные задачи будут возвращены
+// в очередь после истечения блокировки.
+func (p *Pool) Close(ctx context.Context) error {
+	if err := p.runner.Close(ctx); err != nil {
+		return fmt.Errorf("worker drain: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Pool) fetchLoop(ctx context.Context) {
+	for ctx.Err() == nil {
+		// Ждем хотя бы один свободный слот и берем не больше свободных слотов
+		free := p.runner.Free(ctx)
+		if free == 0 {
+			return
+		}
+
+		list, err := p.storage.Jobs.Fetch(ctx, p.kinds, free, p.cfg.Worker)
+		if err != nil {
+			if ctx.Err() == nil {
+				p.log.Error().Err(err).Msg("jobs fetch")
+				runner.Sleep(ctx, p.cfg.Interval)
+			}
+
+			continue
+		}
+
+		for _, job := range list {
+			p.dispatch(job)
+		}
+
+		// Очередь пуста, ждем появления новых задач
+		if len(list) < free {
+			runner.Sleep(ctx, p.cfg.Interval)
+		}
+	}
+}
+
+// lockLoop продлевает блокировку выполняемых задач и возвращает в очередь задачи
+// обработчиков, которые перестали продлевать блокировку.
+func (p *Pool) lockLoop(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.LockTimeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var ids []uint64
+			p.running.Range(func(key, _ interface{}) bool {
+				ids = append(ids, key.(uint64))
+				return true
+			})
+
+			if err := p.storage.Jobs.Touch(ctx, ids, p.cfg.Worker); err != nil && ctx.Err() == nil {
+				p.log.Error().Err(err).Msg("jobs touch")
+			}
+
+			requeued, buried, err := p.storage.Jobs.Rescue(ctx, p.cfg.LockTimeout)
+			if err != nil && ctx.Err() == nil {
+				p.log.Error().Err(err).Msg("jobs rescue")
+			}
+
+			if requeued > 0 {
+				p.log.Warn().Int("count", requeued).Msg("jobs returned to queue after lock timeout")
+			}
+
+			if buried > 0 {
+				p.log.Error().Int("count", buried).Msg("jobs moved to dead after lock timeout")
+			}
+		}
+	}
+}
+
+// dispatch запускает выполнение задачи, дожидаясь свободного слота.
+func (p *Pool) dispatch(job *model.Job) {
+	// Блокировка задачи продлевается и пока она ждет свободного слота
+	p.running.Store(job.ID, struct{}{})
+
+	p.runner.Go(func() {
+		defer p.running.Delete(job.ID)
+
+		p.process(job)
+	})
+}
+
+func (p *Pool) process(job *model.Job) {
+	log := p.log.With().
+		Uint64("job_id", job.ID).
+		Str("kind", job.Kind).
+		Int("attempt", job.Attempts).
+		Logger()
+
+	// Выполнение не прерывается при остановке обработчика, чтобы дать ему завершиться
+	ctx := context.Background()
+
+	err := p.call(ctx, job)
+	if err == nil {
+		p.finish(log, "jobs complete", p.storage.Jobs.Complete(ctx, job.ID, p.cfg.Worker))
+
+		return
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts {
+		log.Error().Err(err).Msg("job moved to dead")
+
+		p.finish(log, "jobs bury", p.storage.Jobs.Bury(ctx, job.ID, p.cfg.Worker, err.Error()))
+
+		return
+	}
+
+	log.Warn().Err(err).Msg("job failed")
+
+	runAt := time.Now().Add(runner.Backoff(p.cfg.Backoff, p.cfg.MaxBackoff, job.Attempts))
+	p.finish(log, "jobs retry", p.storage.Jobs.Retry(ctx, job.ID, p.cfg.Worker, runAt, err.Error()))
+}
+
+// finish логирует ошибку сохранения результата выполнения задачи.
+func (p *Pool) finish(log zerolog.Logger, msg string, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, dbjobs.ErrLockLost):
+		// Задача уже возвращена в очередь и может быть выполнена повторно
+		log.Warn().Err(err).Msg(msg)
+	default:
+		log.Error().Err(err).Msg(msg)
+	}
+}
+
+func (p *Pool) call(ctx context.Context, job *model.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return p.handlers[job.Kind](ctx, job)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent помечает ошибку как неисправимую: задача сразу переходит
+// в статус dead без повторных попыток.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-template/internal/config"
+	"service-template/internal/config/jobs"
+	"service-template/internal/config/outbox"
+	"service-template/internal/db"
+	"service-template/internal/db/dbtest"
+	"service-template/internal/model"
+	"service-template/pkg/txmanager"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type payload struct {
+	Name string `json:"name"`
+}
+
+func newPool(t *testing.T) (*Pool, *db.Storage) {
+	t.Helper()
+
+	log := zerolog.Nop()
+
+	cfg := jobs.NewConfig()
+	cfg.Worker = "worker-1"
+	cfg.Concurrency = 2
+	cfg.Interval = 10 * time.Millisecond
+	cfg.Backoff = 10 * time.Millisecond
+	cfg.MaxBackoff = 20 * time.Millisecond
+
+	storage := db.NewSQLStorage(&config.Config{Outbox: outbox.NewConfig(), Tx: txmanager.NewConfig()}, &log, dbtest.SQLite(t))
+
+	return NewPool(cfg, &log, storage), storage
+}
+
+func enqueue(t *testing.T, storage *db.Storage, kind string, value interface{}, opts Options) *model.Job {
+	t.Helper()
+
+	job, err := NewJob(kind, value, opts)
+	require.NoError(t, err)
+
+	_, err = storage.Jobs.Enqueue(context.Background(), job)
+	require.NoError(t, err)
+
+	return job
+}
+
+func start(t *testing.T, pool *Pool) {
+	t.Helper()
+
+	go pool.Run(context.Background())
+
+	t.Cleanup(func() {
+		assert.NoError(t, pool.Close(context.Background()))
+	})
+}
+
+func status(t *testing.T, storage *db.Storage, id uint64) func() string {
+	return func() string {
+		list, err := storage.Jobs.List(context.Background(), "", "", 100)
+		require.NoError(t, err)
+
+		for _, job := range list {
+			if job.ID == id {
+				return job.Status
+			}
+		}
+
+		return ""
+	}
+}
+
+func TestPool_HandleJSON(t *testing.T) {
+	pool, storage := newPool(t)
+	received := make(chan payload, 1)
+
+	HandleJSON(pool, "test", func(ctx context.Context, value payload) error {
+		received <- value
+		return nil
+	})
+
+	start(t, pool)
+
+	job := enqueue(t, storage, "test", payload{Name: "test"}, Options{})
+
+	select {
+	case value := <-received:
+		assert.Equal(t, "test", value.Name)
+	case <-time.After(2 * time.Second):
+		t.Fatal("job not processed")
+	}
+
+	assert.Eventually(t, func() bool {
+		return status(t, storage, job.ID)() == model.JobSucceeded
+	}, time.Second, 10*time.Millisecond, "job not completed")
+}
+
+func TestPool_retry(t *testing.T) {
+	pool, storage := newPool(t)
+	var attempts atomic.Int32
+
+	pool.Handle("test", func(ctx context.Context, job *model.Job) error {
+		attempts.Add(1)
+		return errors.New("failed")
+	})
+
+	start(t, pool)
+
+	job := enqueue(t, storage, "test", payload{}, Options{MaxAttempts: 3})
+
+	assert.Eventually(t, func() bool {
+		return status(t, storage, job.ID)() == model.JobDead
+	}, 2*time.Second, 10*time.Millisecond, "job not moved to dead")
+
+	assert.EqualValues(t, 3, attempts.Load())
+}
+
+func TestPool_permanent(t *testing.T) {
+	pool, storage := newPool(t)
+	var attempts atomic.Int32
+
+	HandleJSON(pool, "test", func(ctx context.Context, value payload) error {
+		attempts.Add(1)
+		return nil
+	})
+
+	pool.Handle("fatal", func(ctx context.Context, job *model.Job) error {
+		attempts.Add(1)
+		return Permanent(errors.New("fatal"))
+	})
+
+	start(t, pool)
+
+	// Данные, которые не удалось декодировать, и неисправимая ошибка не повторяются
+	invalid := enqueue(t, storage, "test", []int{1}, Options{})
+	fatal := enqueue(t, storage, "fatal", payload{}, Options{})
+
+	assert.Eventually(t, func() bool {
+		return status(t, storage, invalid.ID)() == model.JobDead && status(t, storage, fatal.ID)() == model.JobDead
+	}, 2*time.Second, 10*time.Millisecond, "jobs not moved to dead")
+
+	assert.EqualValues(t, 1, attempts.Load())
+}
+
+func TestPool_Close(t *testing.T) {
+	pool, storage := newPool(t)
+	started, finished := make(chan struct{}), make(chan struct{})
+
+	pool.Handle("test", func(ctx context.Context, job *model.Job) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		close(finished)
+		return nil
+	})
+
+	go pool.Run(context.Background())
+
+	job := enqueue(t, storage, "test", payload{}, Options{})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, pool.Close(ctx))
+
+	select {
+	case <-finished:
+	default:
+		t.Fatal("pool closed before job was processed")
+	}
+
+	assert.Equal(t, model.JobSucceeded, status(t, storage, job.ID)())
+}
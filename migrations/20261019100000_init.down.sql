@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS profiles;
+
+--bun:split
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id         BIGSERIAL PRIMARY KEY,
+    email      VARCHAR(255) UNIQUE,
+    phone      VARCHAR(32) UNIQUE,
+    password   VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    blocked_at TIMESTAMPTZ
+);
+
+--bun:split
+
+CREATE TABLE IF NOT EXISTS profiles
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(255),
+    surname    VARCHAR(255),
+    patronymic VARCHAR(255),
+    sex        BOOLEAN NOT NULL DEFAULT TRUE,
+    birthday   DATE,
+    country    VARCHAR(255),
+    city       VARCHAR(255),
+    address    VARCHAR(255)
+);
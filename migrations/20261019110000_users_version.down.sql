@@ -0,0 +1,8 @@
+ALTER TABLE profiles
+    DROP COLUMN IF EXISTS version;
+
+--bun:split
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS roles,
+    DROP COLUMN IF EXISTS version;
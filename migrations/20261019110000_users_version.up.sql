@@ -0,0 +1,8 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS roles   VARCHAR(64)[] NOT NULL DEFAULT '{}';
+
+--bun:split
+
+ALTER TABLE profiles
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_settings
+(
+    user_id    BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    settings   JSONB       NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id              BIGSERIAL PRIMARY KEY,
+    event_id        VARCHAR(64)  NOT NULL UNIQUE,
+    type            VARCHAR(128) NOT NULL,
+    aggregate_type  VARCHAR(64)  NOT NULL,
+    aggregate_id    VARCHAR(64)  NOT NULL,
+    payload         JSONB        NOT NULL,
+    occurred_at     TIMESTAMPTZ  NOT NULL,
+    published_at    TIMESTAMPTZ,
+    attempts        INT          NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT current_timestamp,
+    last_error      TEXT
+);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (aggregate_type, aggregate_id, id) WHERE published_at IS NULL;
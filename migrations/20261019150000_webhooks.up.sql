@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id          BIGSERIAL PRIMARY KEY,
+    url         VARCHAR(2048) NOT NULL,
+    event_types VARCHAR(128)[] NOT NULL DEFAULT '{}',
+    secret      VARCHAR(128)  NOT NULL,
+    enabled     BOOLEAN       NOT NULL DEFAULT TRUE,
+    failures    INT           NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ   NOT NULL DEFAULT current_timestamp,
+    updated_at  TIMESTAMPTZ
+);
+
+--bun:split
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              BIGSERIAL PRIMARY KEY,
+    webhook_id      BIGINT       NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        VARCHAR(64)  NOT NULL,
+    event_type      VARCHAR(128) NOT NULL,
+    payload         JSONB        NOT NULL,
+    status          VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    attempts        INT          NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT current_timestamp,
+    response_code   INT,
+    response_body   TEXT,
+    error           TEXT,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT current_timestamp,
+    delivered_at    TIMESTAMPTZ,
+    UNIQUE (webhook_id, event_id)
+);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
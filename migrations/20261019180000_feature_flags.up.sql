@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS feature_flags
+(
+    id          BIGSERIAL PRIMARY KEY,
+    key         VARCHAR(128) NOT NULL UNIQUE,
+    description TEXT,
+    enabled     BOOLEAN      NOT NULL DEFAULT FALSE,
+    rules       JSONB        NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT current_timestamp,
+    updated_at  TIMESTAMPTZ
+);
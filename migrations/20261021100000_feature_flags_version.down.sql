@@ -0,0 +1,2 @@
+ALTER TABLE feature_flags
+    DROP COLUMN IF EXISTS version;
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS содержит SQL миграции сервиса.
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLite содержит те же миграции для SQLite.
+var SQLite, _ = fs.Sub(sqliteFS, "sqlite")
+
+// For возвращает миграции для SQLite, если sqlite истинно, иначе для Postgres.
+func For(sqlite bool) fs.FS {
+	if sqlite {
+		return SQLite
+	}
+
+	return FS
+}
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    email      VARCHAR(255) UNIQUE,
+    phone      VARCHAR(32) UNIQUE,
+    password   VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT current_timestamp,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    blocked_at TIMESTAMP
+);
+
+--bun:split
+
+CREATE TABLE IF NOT EXISTS profiles
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    BIGINT NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(255),
+    surname    VARCHAR(255),
+    patronymic VARCHAR(255),
+    sex        BOOLEAN NOT NULL DEFAULT TRUE,
+    birthday   DATE,
+    country    VARCHAR(255),
+    city       VARCHAR(255),
+    address    VARCHAR(255)
+);
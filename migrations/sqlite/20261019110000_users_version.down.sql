@@ -0,0 +1,12 @@
+ALTER TABLE profiles
+    DROP COLUMN version;
+
+--bun:split
+
+ALTER TABLE users
+    DROP COLUMN roles;
+
+--bun:split
+
+ALTER TABLE users
+    DROP COLUMN version;
@@ -0,0 +1,13 @@
+-- Массивы Postgres хранятся в SQLite как JSON
+ALTER TABLE users
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+--bun:split
+
+ALTER TABLE users
+    ADD COLUMN roles TEXT DEFAULT '[]';
+
+--bun:split
+
+ALTER TABLE profiles
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_settings
+(
+    user_id    INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    settings   TEXT      NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id        VARCHAR(64)  NOT NULL UNIQUE,
+    type            VARCHAR(128) NOT NULL,
+    aggregate_type  VARCHAR(64)  NOT NULL,
+    aggregate_id    VARCHAR(64)  NOT NULL,
+    payload         TEXT         NOT NULL,
+    occurred_at     TIMESTAMP    NOT NULL,
+    published_at    TIMESTAMP,
+    attempts        INT          NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP    NOT NULL DEFAULT current_timestamp,
+    last_error      TEXT
+);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (aggregate_type, aggregate_id, id) WHERE published_at IS NULL;
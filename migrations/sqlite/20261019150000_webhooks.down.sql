@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+
+--bun:split
+
+DROP TABLE IF EXISTS webhooks;
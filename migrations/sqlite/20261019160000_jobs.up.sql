@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS jobs
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind         VARCHAR(128) NOT NULL,
+    payload      TEXT         NOT NULL DEFAULT '{}',
+    status       VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    unique_key   VARCHAR(255),
+    attempts     INT          NOT NULL DEFAULT 0,
+    max_attempts INT          NOT NULL DEFAULT 5,
+    run_at       TIMESTAMP    NOT NULL DEFAULT current_timestamp,
+    locked_at    TIMESTAMP,
+    locked_by    VARCHAR(255),
+    last_error   TEXT,
+    created_at   TIMESTAMP    NOT NULL DEFAULT current_timestamp,
+    finished_at  TIMESTAMP
+);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS jobs_pending_idx ON jobs (run_at) WHERE status = 'pending';
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS jobs_running_idx ON jobs (locked_at) WHERE status = 'running';
+
+--bun:split
+
+CREATE UNIQUE INDEX IF NOT EXISTS jobs_unique_key_idx ON jobs (kind, unique_key)
+    WHERE unique_key IS NOT NULL AND status IN ('pending', 'running');
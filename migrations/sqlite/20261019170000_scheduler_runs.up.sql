@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS scheduler_runs
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    task         VARCHAR(128) NOT NULL,
+    scheduled_at TIMESTAMP    NOT NULL,
+    manual       BOOLEAN      NOT NULL DEFAULT FALSE,
+    instance     VARCHAR(255) NOT NULL,
+    status       VARCHAR(16)  NOT NULL DEFAULT 'running',
+    error        TEXT,
+    started_at   TIMESTAMP    NOT NULL DEFAULT current_timestamp,
+    finished_at  TIMESTAMP,
+    UNIQUE (task, scheduled_at)
+);
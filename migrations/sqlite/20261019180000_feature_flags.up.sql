@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS feature_flags
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    key         VARCHAR(128) NOT NULL UNIQUE,
+    description TEXT,
+    enabled     BOOLEAN      NOT NULL DEFAULT FALSE,
+    rules       TEXT         NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMP    NOT NULL DEFAULT current_timestamp,
+    updated_at  TIMESTAMP
+);
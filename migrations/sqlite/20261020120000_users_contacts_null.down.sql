@@ -0,0 +1,2 @@
+-- Пустые строки не восстанавливаются: для нескольких пользователей они нарушат уникальность
+SELECT 1;
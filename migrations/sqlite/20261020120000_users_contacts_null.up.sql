@@ -0,0 +1,7 @@
+-- Пустые email и телефон хранятся как NULL, иначе уникальность допускает только одного
+-- пользователя без email или без телефона
+UPDATE users SET email = NULL WHERE email = '';
+
+--bun:split
+
+UPDATE users SET phone = NULL WHERE phone = '';
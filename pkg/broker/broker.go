@@ -0,0 +1,99 @@
+// Package broker определяет независимый от брокера сообщений интерфейс
+// публикации и подписки и его реализации: в памяти, Redis Streams и NATS JetStream.
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"service-template/pkg/queue"
+)
+
+// DefaultDeadLetterSuffix суффикс топика, в который переносятся необработанные сообщения.
+// Совпадает с суффиксом потоков queue, чтобы имена не зависели от драйвера.
+const DefaultDeadLetterSuffix = queue.DefaultDeadLetterSuffix
+
+var ErrNoSubscriptions = errors.New("broker: no subscriptions")
+
+// Message сообщение, полученное из брокера.
+type Message struct {
+	ID    string
+	Topic string
+	Data  []byte
+	// Attempt номер попытки обработки, начиная с 1.
+	Attempt int
+}
+
+// Handler обрабатывает сообщение. Если обработчик вернул ошибку,
+// сообщение будет обработано повторно после задержки.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Publisher публикует сообщения в топик.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, data []byte) error
+}
+
+// Subscriber доставляет сообщения топиков обработчикам.
+// Подписки регистрируются до вызова Run, Close ожидает завершения обрабатываемых сообщений.
+type Subscriber interface {
+	Subscribe(topic string, handler Handler)
+	Run(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+// Broker брокер сообщений.
+type Broker interface {
+	Publisher
+	Subscriber
+}
+
+// PublishJSON публикует значение в формате JSON.
+func PublishJSON(ctx context.Context, p Publisher, topic string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return p.Publish(ctx, topic, data)
+}
+
+// SubscribeJSON регистрирует обработчик, получающий данные сообщения, декодированные из JSON.
+// Сообщения, которые не удалось декодировать, сразу переносятся в DeadLetter топик.
+func SubscribeJSON[T any](s Subscriber, topic string, fn func(ctx context.Context, value T) error) {
+	s.Subscribe(topic, func(ctx context.Context, msg *Message) error {
+		var value T
+
+		if err := json.Unmarshal(msg.Data, &value); err != nil {
+			return Permanent(fmt.Errorf("decode %s: %w", msg.ID, err))
+		}
+
+		return fn(ctx, value)
+	})
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent помечает ошибку как неисправимую: сообщение не обрабатывается повторно,
+// а сразу переносится в DeadLetter топик.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent сообщает, помечена ли ошибка как неисправимая.
+func IsPermanent(err error) bool {
+	var perr *permanentError
+
+	return errors.As(err, &perr)
+}
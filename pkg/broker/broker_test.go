@@ -0,0 +1,190 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"service-template/pkg/queue"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type payload struct {
+	Name string `json:"name"`
+}
+
+func newRedis(t *testing.T) Broker {
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	log := zerolog.Nop()
+
+	return NewRedis(rdb, queue.Config{
+		Group:         "test",
+		Consumer:      "consumer-1",
+		Concurrency:   2,
+		MaxRetries:    2,
+		Backoff:       10 * time.Millisecond,
+		MaxBackoff:    20 * time.Millisecond,
+		ClaimInterval: 10 * time.Millisecond,
+		ClaimTimeout:  20 * time.Millisecond,
+		Block:         10 * time.Millisecond,
+	}, &log)
+}
+
+func newMemory(maxRetries, capacity int) *Memory {
+	return NewMemory(MemoryConfig{
+		Capacity:    capacity,
+		Concurrency: 2,
+		MaxRetries:  maxRetries,
+		Backoff:     10 * time.Millisecond,
+		MaxBackoff:  20 * time.Millisecond,
+	})
+}
+
+func newNATS(t *testing.T) Broker {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(5*time.Second), "nats server not ready")
+
+	log := zerolog.Nop()
+	b, err := NewNATS(NATSConfig{
+		URL:         srv.ClientURL(),
+		Stream:      "test",
+		Group:       "test",
+		Concurrency: 2,
+		MaxRetries:  2,
+		Backoff:     10 * time.Millisecond,
+		MaxBackoff:  20 * time.Millisecond,
+		FetchWait:   50 * time.Millisecond,
+	}, &log)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestBroker(t *testing.T) {
+	brokers := map[string]func(t *testing.T) Broker{
+		"memory": func(t *testing.T) Broker { return newMemory(2, 10) },
+		"redis":  newRedis,
+		"nats":   newNATS,
+	}
+
+	for name, newBroker := range brokers {
+		t.Run(name, func(t *testing.T) {
+			b := newBroker(t)
+			received := make(chan payload, 1)
+			attempts := make(chan int, 3)
+
+			SubscribeJSON(b, "events", func(ctx context.Context, value payload) error {
+				received <- value
+				return nil
+			})
+
+			b.Subscribe("retry", func(ctx context.Context, msg *Message) error {
+				attempts <- msg.Attempt
+				if msg.Attempt == 1 {
+					return errors.New("temporary")
+				}
+
+				return nil
+			})
+
+			go b.Run(context.Background())
+			defer b.Close(context.Background())
+
+			require.NoError(t, PublishJSON(context.Background(), b, "events", payload{Name: "test"}))
+			require.NoError(t, b.Publish(context.Background(), "retry", []byte("{}")))
+
+			select {
+			case value := <-received:
+				assert.Equal(t, "test", value.Name, "values not equal")
+			case <-time.After(5 * time.Second):
+				t.Fatal("message not received")
+			}
+
+			for want := 1; want <= 2; want++ {
+				select {
+				case attempt := <-attempts:
+					assert.Equal(t, want, attempt, "attempts not equal")
+				case <-time.After(5 * time.Second):
+					t.Fatalf("attempt %d not received", want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemory_deadLetter(t *testing.T) {
+	b := newMemory(1, 10)
+
+	var attempts []time.Time
+	b.Subscribe("events", func(ctx context.Context, msg *Message) error {
+		attempts = append(attempts, time.Now())
+		return errors.New("failed")
+	})
+
+	go b.Run(context.Background())
+	defer b.Close(context.Background())
+
+	require.NoError(t, b.Publish(context.Background(), "events", []byte("{}")))
+
+	assert.Eventually(t, func() bool {
+		dead := b.Dead("events")
+		return len(dead) == 1 && dead[0].Attempt == 2
+	}, 2*time.Second, 10*time.Millisecond, "message not moved to dead letter")
+
+	require.Len(t, attempts, 2)
+	assert.GreaterOrEqual(t, attempts[1].Sub(attempts[0]), 10*time.Millisecond, "retried without backoff")
+}
+
+func TestMemory_capacity(t *testing.T) {
+	b := newMemory(0, 2)
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	b.Subscribe("events", func(ctx context.Context, msg *Message) error {
+		<-release
+		return nil
+	})
+
+	go b.Run(ctx)
+	defer b.Close(ctx)
+
+	require.NoError(t, b.Publish(ctx, "events", []byte("{}")))
+	require.NoError(t, b.Publish(ctx, "events", []byte("{}")))
+	assert.ErrorIs(t, b.Publish(ctx, "events", []byte("{}")), ErrFull)
+
+	// Место освобождается после обработки сообщений
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		return b.Publish(ctx, "events", []byte("{}")) == nil
+	}, time.Second, 10*time.Millisecond, "capacity not released")
+}
+
+func TestMemory_noSubscribers(t *testing.T) {
+	b := newMemory(0, 1)
+	ctx := context.Background()
+
+	// Брокер без Run только публикует, сообщения без подписчика отбрасываются
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.Publish(ctx, "events", []byte("{}")))
+	}
+}
@@ -0,0 +1,229 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"service-template/pkg/runner"
+)
+
+var ErrFull = errors.New("broker: memory buffer is full")
+
+// MemoryConfig параметры брокера в памяти.
+type MemoryConfig struct {
+	// Capacity максимальное количество сообщений в брокере, включая обрабатываемые и ожидающие
+	// повторной обработки. Публикация в заполненный брокер завершается ошибкой ErrFull.
+	Capacity int
+	// Concurrency максимальное количество одновременно обрабатываемых сообщений.
+	Concurrency int
+	// MaxRetries количество повторов, после которого сообщение переносится в DeadLetter топик.
+	MaxRetries int
+	// Backoff начальная задержка повторной обработки, удваивается с каждой попыткой.
+	Backoff time.Duration
+	// MaxBackoff максимальная задержка повторной обработки.
+	MaxBackoff time.Duration
+	// DeadLetterSuffix суффикс имени DeadLetter топика.
+	DeadLetterSuffix string
+}
+
+// Memory брокер сообщений в памяти процесса. Предназначен для тестов и локальной разработки:
+// сообщения не переживают перезапуск, а сообщения топиков без подписчиков отбрасываются
+// при публикации.
+type Memory struct {
+	cfg MemoryConfig
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	pending  []*Message
+	size     int
+	dead     map[string][]*Message
+	notify   chan struct{}
+	seq      atomic.Uint64
+	runner   *runner.Runner
+}
+
+// NewMemory создает брокер в памяти. Сообщение, обработчик которого вернул ошибку,
+// обрабатывается повторно после задержки до MaxRetries раз и затем переносится
+// в DeadLetter топик. DeadLetter топик хранит не больше Capacity последних сообщений.
+func NewMemory(cfg MemoryConfig) *Memory {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = 1000
+	}
+
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	if cfg.DeadLetterSuffix == "" {
+		cfg.DeadLetterSuffix = DefaultDeadLetterSuffix
+	}
+
+	return &Memory{
+		cfg:      cfg,
+		handlers: make(map[string]Handler),
+		dead:     make(map[string][]*Message),
+		notify:   make(chan struct{}, 1),
+		runner:   runner.New(cfg.Concurrency),
+	}
+}
+
+func (m *Memory) Publish(_ context.Context, topic string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Сообщения без подписчика не занимают место, иначе брокер только для публикации
+	// заполнился бы и отклонял все сообщения
+	if _, ok := m.handlers[topic]; !ok {
+		return nil
+	}
+
+	if m.size >= m.cfg.Capacity {
+		return ErrFull
+	}
+
+	m.size++
+	m.pending = append(m.pending, &Message{
+		ID:      strconv.FormatUint(m.seq.Add(1), 10),
+		Topic:   topic,
+		Data:    append([]byte(nil), data...),
+		Attempt: 1,
+	})
+	m.wake()
+
+	return nil
+}
+
+func (m *Memory) Subscribe(topic string, handler Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.handlers[topic] = handler
+}
+
+// Run доставляет сообщения обработчикам до вызова Close или отмены контекста.
+func (m *Memory) Run(ctx context.Context) error {
+	ctx, finish := m.runner.Start(ctx)
+	defer finish()
+
+	m.mu.Lock()
+	empty := len(m.handlers) == 0
+	m.mu.Unlock()
+
+	if empty {
+		return ErrNoSubscriptions
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-m.notify:
+		}
+
+		for _, msg := range m.takePending() {
+			m.mu.Lock()
+			handler, ok := m.handlers[msg.Topic]
+			m.mu.Unlock()
+
+			if !ok {
+				m.release()
+				continue
+			}
+
+			msg := msg
+
+			m.runner.Go(func() {
+				m.process(handler, msg)
+			})
+		}
+	}
+}
+
+// Close прекращает доставку новых сообщений и ожидает завершения обрабатываемых,
+// но не дольше, чем до отмены ctx.
+func (m *Memory) Close(ctx context.Context) error {
+	return m.runner.Close(ctx)
+}
+
+// Dead возвращает сообщения, перенесенные в DeadLetter топик.
+func (m *Memory) Dead(topic string) []*Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Message(nil), m.dead[topic+m.cfg.DeadLetterSuffix]...)
+}
+
+func (m *Memory) process(handler Handler, msg *Message) {
+	err := call(handler, msg)
+	if err == nil {
+		m.release()
+		return
+	}
+
+	if IsPermanent(err) || msg.Attempt > m.cfg.MaxRetries {
+		m.mu.Lock()
+		topic := msg.Topic + m.cfg.DeadLetterSuffix
+		dead := append(m.dead[topic], msg)
+		if len(dead) > m.cfg.Capacity {
+			dead = dead[len(dead)-m.cfg.Capacity:]
+		}
+		m.dead[topic] = dead
+		m.size--
+		m.mu.Unlock()
+
+		return
+	}
+
+	// Сообщение остается в брокере и возвращается в очередь после задержки
+	retry := *msg
+	retry.Attempt++
+
+	time.AfterFunc(runner.Backoff(m.cfg.Backoff, m.cfg.MaxBackoff, msg.Attempt), func() {
+		m.mu.Lock()
+		m.pending = append(m.pending, &retry)
+		m.wake()
+		m.mu.Unlock()
+	})
+}
+
+// wake сообщает Run о новых сообщениях. Вызывается под m.mu.
+func (m *Memory) wake() {
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
+
+// release освобождает место обработанного или отброшенного сообщения.
+func (m *Memory) release() {
+	m.mu.Lock()
+	m.size--
+	m.mu.Unlock()
+}
+
+func (m *Memory) takePending() []*Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	msgs := m.pending
+	m.pending = nil
+
+	return msgs
+}
+
+// call вызывает обработчик, превращая панику в ошибку.
+func call(handler Handler, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	// Обработка не прерывается при остановке подписчика, чтобы дать ей завершиться
+	return handler(context.Background(), msg)
+}
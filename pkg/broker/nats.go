@@ -0,0 +1,349 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"service-template/pkg/runner"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog"
+)
+
+// Заголовки, добавляемые к сообщению при переносе в DeadLetter топик.
+const (
+	HeaderDeadTopic  = "Dead-Topic"
+	HeaderDeadID     = "Dead-Id"
+	HeaderDeadReason = "Dead-Reason"
+)
+
+// NATSConfig параметры брокера NATS JetStream.
+type NATSConfig struct {
+	// URL адрес сервера NATS, допускается список через запятую.
+	URL string
+	// Stream имя потока JetStream, в котором хранятся сообщения всех топиков.
+	Stream string
+	// Group имя группы потребителей, используется как префикс durable консьюмеров.
+	Group string
+	// Concurrency максимальное количество одновременно обрабатываемых сообщений.
+	Concurrency int
+	// MaxRetries количество повторов, после которого сообщение переносится в DeadLetter топик.
+	MaxRetries int
+	// Backoff начальная задержка повторной обработки, удваивается с каждой попыткой.
+	Backoff time.Duration
+	// MaxBackoff максимальная задержка повторной обработки.
+	MaxBackoff time.Duration
+	// AckWait время обработки, после которого сообщение доставляется повторно.
+	AckWait time.Duration
+	// FetchWait время ожидания новых сообщений.
+	FetchWait time.Duration
+	// Timeout время ожидания ответа сервера при публикации и управлении потоком.
+	Timeout time.Duration
+	// DeadLetterSuffix суффикс имени DeadLetter топика.
+	DeadLetterSuffix string
+}
+
+// NATS брокер сообщений на NATS JetStream. Топик соответствует субъекту,
+// который добавляется в поток при первой публикации или подписке.
+type NATS struct {
+	nc  *nats.Conn
+	js  nats.JetStreamContext
+	cfg NATSConfig
+	log *zerolog.Logger
+
+	mu       sync.Mutex
+	subjects map[string]bool
+	handlers map[string]Handler
+	topics   []string
+	runner   *runner.Runner
+}
+
+// NewNATS подключается к серверу NATS.
+func NewNATS(cfg NATSConfig, log *zerolog.Logger) (*NATS, error) {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	if cfg.DeadLetterSuffix == "" {
+		cfg.DeadLetterSuffix = DefaultDeadLetterSuffix
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	if cfg.FetchWait <= 0 {
+		cfg.FetchWait = 5 * time.Second
+	}
+
+	if cfg.AckWait <= 0 {
+		cfg.AckWait = 30 * time.Second
+	}
+
+	nc, err := nats.Connect(cfg.URL, nats.Name(cfg.Group), nats.Timeout(cfg.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("nats connect: %w", err)
+	}
+
+	js, err := nc.JetStream(nats.MaxWait(cfg.Timeout))
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("nats jetstream: %w", err)
+	}
+
+	return &NATS{
+		nc:       nc,
+		js:       js,
+		cfg:      cfg,
+		log:      log,
+		subjects: make(map[string]bool),
+		handlers: make(map[string]Handler),
+		runner:   runner.New(cfg.Concurrency),
+	}, nil
+}
+
+func (n *NATS) Publish(ctx context.Context, topic string, data []byte) error {
+	return n.publish(ctx, &nats.Msg{Subject: topic, Data: data})
+}
+
+func (n *NATS) Subscribe(topic string, handler Handler) {
+	if _, ok := n.handlers[topic]; !ok {
+		n.topics = append(n.topics, topic)
+	}
+
+	n.handlers[topic] = handler
+}
+
+// Run создает durable консьюмеры и обрабатывает сообщения до вызова Close или отмены контекста.
+func (n *NATS) Run(ctx context.Context) error {
+	ctx, finish := n.runner.Start(ctx)
+	defer finish()
+
+	if len(n.topics) == 0 {
+		return ErrNoSubscriptions
+	}
+
+	subs := make([]*nats.Subscription, 0, len(n.topics))
+	for _, topic := range n.topics {
+		if err := n.ensureSubjects(topic, topic+n.cfg.DeadLetterSuffix); err != nil {
+			return err
+		}
+
+		sub, err := n.js.PullSubscribe(topic, durableName(n.cfg.Group, topic),
+			nats.BindStream(n.cfg.Stream),
+			nats.ManualAck(),
+			nats.AckWait(n.cfg.AckWait),
+			nats.MaxAckPending(n.cfg.Concurrency),
+		)
+		if err != nil {
+			return fmt.Errorf("nats subscribe %s: %w", topic, err)
+		}
+
+		subs = append(subs, sub)
+	}
+
+	var wg sync.WaitGroup
+	for i := range subs {
+		wg.Add(1)
+
+		go func(sub *nats.Subscription, topic string) {
+			defer wg.Done()
+			n.fetchLoop(ctx, sub, topic)
+		}(subs[i], n.topics[i])
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// Close прекращает получение новых сообщений, ожидает завершения обрабатываемых,
+// но не дольше, чем до отмены ctx, и закрывает соединение.
+func (n *NATS) Close(ctx context.Context) error {
+	defer n.nc.Close()
+
+	if err := n.runner.Close(ctx); err != nil {
+		return fmt.Errorf("nats drain: %w", err)
+	}
+
+	return nil
+}
+
+func (n *NATS) fetchLoop(ctx context.Context, sub *nats.Subscription, topic string) {
+	for ctx.Err() == nil {
+		// Ждем хотя бы один свободный слот и запрашиваем не больше свободных слотов
+		free := n.runner.Free(ctx)
+		if free == 0 {
+			return
+		}
+
+		msgs, err := sub.Fetch(free, nats.MaxWait(n.cfg.FetchWait))
+		if err != nil {
+			if !errors.Is(err, nats.ErrTimeout) && ctx.Err() == nil {
+				n.log.Error().Err(err).Str("topic", topic).Msg("nats fetch")
+				runner.Sleep(ctx, n.cfg.Backoff)
+			}
+
+			continue
+		}
+
+		for _, msg := range msgs {
+			n.dispatch(topic, msg)
+		}
+	}
+}
+
+// dispatch запускает обработку сообщения, дожидаясь свободного слота.
+func (n *NATS) dispatch(topic string, msg *nats.Msg) {
+	n.runner.Go(func() {
+		n.process(topic, msg)
+	})
+}
+
+func (n *NATS) process(topic string, raw *nats.Msg) {
+	meta, err := raw.Metadata()
+	if err != nil {
+		n.log.Error().Err(err).Str("topic", topic).Msg("nats metadata")
+		return
+	}
+
+	msg := &Message{
+		ID:      fmt.Sprintf("%s:%d", meta.Stream, meta.Sequence.Stream),
+		Topic:   topic,
+		Data:    raw.Data,
+		Attempt: int(meta.NumDelivered),
+	}
+
+	log := n.log.With().Str("topic", topic).Str("id", msg.ID).Int("attempt", msg.Attempt).Logger()
+
+	// Сообщение, на котором потребители падали раньше, чем успевали вернуть ошибку
+	if msg.Attempt > n.cfg.MaxRetries+1 {
+		n.deadLetter(raw, msg, "max retries exceeded")
+		return
+	}
+
+	err = call(n.handlers[topic], msg)
+	if err == nil {
+		if err = raw.Ack(); err != nil {
+			log.Error().Err(err).Msg("nats ack")
+		}
+
+		return
+	}
+
+	if IsPermanent(err) || msg.Attempt > n.cfg.MaxRetries {
+		log.Error().Err(err).Msg("nats message moved to dead letter")
+		n.deadLetter(raw, msg, err.Error())
+
+		return
+	}
+
+	log.Warn().Err(err).Msg("nats message failed")
+
+	if err = raw.NakWithDelay(runner.Backoff(n.cfg.Backoff, n.cfg.MaxBackoff, msg.Attempt)); err != nil {
+		log.Error().Err(err).Msg("nats nak")
+	}
+}
+
+// deadLetter публикует сообщение в DeadLetter топик и прекращает его доставку.
+func (n *NATS) deadLetter(raw *nats.Msg, msg *Message, reason string) {
+	dead := nats.NewMsg(msg.Topic + n.cfg.DeadLetterSuffix)
+	dead.Data = msg.Data
+	dead.Header.Set(HeaderDeadTopic, msg.Topic)
+	dead.Header.Set(HeaderDeadID, msg.ID)
+	dead.Header.Set(HeaderDeadReason, reason)
+
+	// Если публикация не удалась, сообщение будет доставлено повторно
+	if err := n.publish(context.Background(), dead); err != nil {
+		n.log.Error().Err(err).Str("topic", msg.Topic).Str("id", msg.ID).Msg("nats dead letter")
+		return
+	}
+
+	if err := raw.Term(); err != nil {
+		n.log.Error().Err(err).Str("topic", msg.Topic).Str("id", msg.ID).Msg("nats term")
+	}
+}
+
+func (n *NATS) publish(ctx context.Context, msg *nats.Msg) error {
+	if err := n.ensureSubjects(msg.Subject); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	if _, err := n.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+		return fmt.Errorf("nats publish %s: %w", msg.Subject, err)
+	}
+
+	return nil
+}
+
+// ensureSubjects создает поток или добавляет в него недостающие субъекты.
+func (n *NATS) ensureSubjects(subjects ...string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	missing := false
+	for _, subject := range subjects {
+		if !n.subjects[subject] {
+			missing = true
+		}
+	}
+
+	if !missing {
+		return nil
+	}
+
+	info, err := n.js.StreamInfo(n.cfg.Stream)
+	switch {
+	case errors.Is(err, nats.ErrStreamNotFound):
+		_, err = n.js.AddStream(&nats.StreamConfig{
+			Name:     n.cfg.Stream,
+			Subjects: subjects,
+			Storage:  nats.FileStorage,
+		})
+		if err != nil {
+			return fmt.Errorf("nats create stream %s: %w", n.cfg.Stream, err)
+		}
+	case err != nil:
+		return fmt.Errorf("nats stream %s: %w", n.cfg.Stream, err)
+	default:
+		cfg := info.Config
+
+		known := make(map[string]bool, len(cfg.Subjects))
+		for _, subject := range cfg.Subjects {
+			known[subject] = true
+		}
+
+		update := false
+		for _, subject := range subjects {
+			if !known[subject] {
+				cfg.Subjects = append(cfg.Subjects, subject)
+				update = true
+			}
+		}
+
+		if update {
+			if _, err = n.js.UpdateStream(&cfg); err != nil {
+				return fmt.Errorf("nats update stream %s: %w", n.cfg.Stream, err)
+			}
+		}
+	}
+
+	for _, subject := range subjects {
+		n.subjects[subject] = true
+	}
+
+	return nil
+}
+
+// durableName имя durable консьюмера группы для топика.
+// Имена консьюмеров не могут содержать точки и символы подстановки.
+func durableName(group, topic string) string {
+	return group + "_" + strings.NewReplacer(".", "_", "*", "_", ">", "_", ":", "_").Replace(topic)
+}
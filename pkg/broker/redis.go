@@ -0,0 +1,62 @@
+package broker
+
+import (
+	"context"
+
+	"service-template/pkg/queue"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// Redis брокер сообщений на Redis Streams. Топик соответствует потоку,
+// доставка выполняется группой потребителей queue.Consumer.
+type Redis struct {
+	rdb      redis.UniversalClient
+	consumer *queue.Consumer
+}
+
+func NewRedis(rdb redis.UniversalClient, cfg queue.Config, log *zerolog.Logger) *Redis {
+	return &Redis{
+		rdb:      rdb,
+		consumer: queue.NewConsumer(rdb, cfg, log),
+	}
+}
+
+func (r *Redis) Publish(ctx context.Context, topic string, data []byte) error {
+	return r.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: topic,
+		Values: map[string]interface{}{queue.FieldData: string(data)},
+	}).Err()
+}
+
+func (r *Redis) Subscribe(topic string, handler Handler) {
+	r.consumer.Handle(topic, func(ctx context.Context, msg *queue.Message) error {
+		data, _ := msg.Values[queue.FieldData].(string)
+
+		err := handler(ctx, &Message{
+			ID:      msg.ID,
+			Topic:   msg.Stream,
+			Data:    []byte(data),
+			Attempt: msg.Attempt,
+		})
+		if err != nil && IsPermanent(err) {
+			return queue.Permanent(err)
+		}
+
+		return err
+	})
+}
+
+func (r *Redis) Run(ctx context.Context) error {
+	err := r.consumer.Run(ctx)
+	if err == queue.ErrNoHandlers {
+		return ErrNoSubscriptions
+	}
+
+	return err
+}
+
+func (r *Redis) Close(ctx context.Context) error {
+	return r.consumer.Close(ctx)
+}
@@ -0,0 +1,278 @@
+// Package certs загружает TLS-сертификаты и перечитывает их при изменении файлов,
+// чтобы ротация сертификатов не требовала перезапуска сервиса.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Reloader хранит текущие сертификат сервера и пул корневых сертификатов клиентов.
+type Reloader struct {
+	log      *zerolog.Logger
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime map[string]time.Time
+}
+
+// NewReloader загружает сертификат и ключ сервера. caFile необязателен и задает
+// корневые сертификаты для проверки клиентов.
+func NewReloader(log *zerolog.Logger, certFile, keyFile, caFile string) (*Reloader, error) {
+	r := &Reloader{
+		log:      log,
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload перечитывает файлы. При ошибке продолжают использоваться ранее загруженные сертификаты.
+func (r *Reloader) Reload() error {
+	modTime, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		if pool, err = LoadPool(r.caFile); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert, r.pool, r.modTime = &cert, pool, modTime
+
+	return nil
+}
+
+// Run проверяет изменение файлов с периодом interval и перечитывает их до отмены ctx.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !r.changed() {
+			continue
+		}
+
+		if err := r.Reload(); err != nil {
+			r.log.Error().Err(err).Str("cert", r.certFile).Msg("reload TLS certificates")
+			continue
+		}
+
+		r.log.Info().Str("cert", r.certFile).Msg("TLS certificates reloaded")
+	}
+}
+
+// Certificate возвращает текущий сертификат сервера.
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert
+}
+
+// ClientCAs возвращает текущий пул корневых сертификатов клиентов.
+func (r *Reloader) ClientCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.pool
+}
+
+// Config возвращает TLS-конфигурацию, которая для каждого соединения
+// использует актуальные сертификаты. Остальные параметры берутся из base.
+func (r *Reloader) Config(base *tls.Config) *tls.Config {
+	cfg := base.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.Certificates = []tls.Certificate{*r.Certificate()}
+		c.ClientCAs = r.ClientCAs()
+
+		return c, nil
+	}
+
+	return cfg
+}
+
+func (r *Reloader) changed() bool {
+	modTime, err := r.stat()
+	if err != nil {
+		// Файлы могут отсутствовать короткое время во время ротации
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for name, t := range modTime {
+		if !t.Equal(r.modTime[name]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Reloader) stat() (map[string]time.Time, error) {
+	modTime := make(map[string]time.Time, 3)
+
+	for _, name := range []string{r.certFile, r.keyFile, r.caFile} {
+		if name == "" {
+			continue
+		}
+
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+
+		modTime[name] = info.ModTime()
+	}
+
+	return modTime, nil
+}
+
+// LoadPool загружает пул сертификатов из PEM-файла.
+func LoadPool(name string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("load CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("load CA: no certificates in %s", name)
+	}
+
+	return pool, nil
+}
+
+// ClientConfig возвращает TLS-конфигурацию клиента. caFile задает корневые сертификаты
+// сервера, по умолчанию используются системные; certFile и keyFile задают сертификат клиента.
+// Все файлы необязательны.
+func ClientConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pool, err := LoadPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// Identity возвращает идентификатор сервиса из клиентского сертификата:
+// первый URI из SAN (например, SPIFFE ID), а если его нет, то Common Name.
+func Identity(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+
+	return cert.Subject.CommonName
+}
+
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseVersion преобразует версию TLS вида "1.2" в константу crypto/tls.
+func ParseVersion(version string) (uint16, error) {
+	v, ok := versions[version]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version %q", version)
+	}
+
+	return v, nil
+}
+
+// ParseCipherSuite возвращает идентификатор набора шифров по имени из crypto/tls,
+// например TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Небезопасные наборы не поддерживаются.
+func ParseCipherSuite(name string) (uint16, error) {
+	for _, s := range tls.CipherSuites() {
+		if s.Name == name {
+			return s.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupported cipher suite %q", name)
+}
+
+// ParseCipherSuites преобразует имена наборов шифров в идентификаторы.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, err := ParseCipherSuite(name)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":            tls.NoClientCert,
+	"request":         tls.RequestClientCert,
+	"verify_if_given": tls.VerifyClientCertIfGiven,
+	"require":         tls.RequireAndVerifyClientCert,
+}
+
+// ParseClientAuth преобразует режим проверки клиентских сертификатов:
+// none, request, verify_if_given или require.
+func ParseClientAuth(name string) (tls.ClientAuthType, error) {
+	t, ok := clientAuthTypes[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown client auth type %q", name)
+	}
+
+	return t, nil
+}
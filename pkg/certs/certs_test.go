@@ -0,0 +1,130 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert создает самоподписанный сертификат с указанным Common Name.
+func writeCert(t *testing.T, certFile, keyFile, name string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
+
+func commonName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "old")
+
+	log := zerolog.Nop()
+	r, err := NewReloader(&log, certFile, keyFile, certFile)
+	require.NoError(t, err)
+	assert.Equal(t, "old", commonName(t, r.Certificate()))
+	assert.NotNil(t, r.ClientCAs())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go r.Run(ctx, 10*time.Millisecond)
+
+	writeCert(t, certFile, keyFile, "new")
+
+	// Время изменения файла может совпасть с предыдущим на файловых системах с низкой точностью
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+
+	assert.Eventually(t, func() bool {
+		return commonName(t, r.Certificate()) == "new"
+	}, time.Second, 10*time.Millisecond)
+
+	cfg, err := r.Config(&tls.Config{MinVersion: tls.VersionTLS12}).GetConfigForClient(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "new", commonName(t, &cfg.Certificates[0]))
+	assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+}
+
+func TestReloader_invalid(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "valid")
+
+	log := zerolog.Nop()
+	r, err := NewReloader(&log, certFile, keyFile, "")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+	assert.Error(t, r.Reload())
+	assert.Equal(t, "valid", commonName(t, r.Certificate()), "certificate replaced by invalid one")
+}
+
+func TestIdentity(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}}
+	assert.Equal(t, "billing", Identity(cert))
+
+	cert.URIs = []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/ns/prod/sa/billing"}}
+	assert.Equal(t, "spiffe://example.org/ns/prod/sa/billing", Identity(cert))
+}
+
+func TestParse(t *testing.T) {
+	v, err := ParseVersion("1.3")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), v)
+
+	_, err = ParseVersion("1.0")
+	assert.Error(t, err)
+
+	ids, err := ParseCipherSuites([]string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"})
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, ids)
+
+	_, err = ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.Error(t, err, "insecure cipher suite accepted")
+
+	auth, err := ParseClientAuth("require")
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, auth)
+}
@@ -1,28 +1,176 @@
 package postgres
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"service-template/pkg/certs"
 
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
 )
 
+// NewPostgresDB подключается к Postgres и проверяет соединение.
 func NewPostgresDB(cfg *Config) (*bun.DB, error) {
-	sqldb := sql.OpenDB(pgdriver.NewConnector(
-		pgdriver.WithNetwork("tcp"),
-		pgdriver.WithAddr(cfg.Addr),
-		pgdriver.WithUser(cfg.User),
-		pgdriver.WithPassword(cfg.Pass),
-		pgdriver.WithDatabase(cfg.Name),
-		pgdriver.WithTLSConfig(nil),
-	))
-
-	db := bun.NewDB(sqldb, pgdialect.New())
+	db, err := OpenDB(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := db.Ping(); err != nil {
-		return nil, err
+		return nil, errors.Join(err, db.Close())
 	}
 
 	return db, nil
 }
+
+// OpenDB создает пул соединений с Postgres без подключения к серверу:
+// соединения устанавливаются при первом запросе.
+func OpenDB(cfg *Config) (*bun.DB, error) {
+	c, err := cfg.Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("postgres dsn: %w", err)
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []pgdriver.Option{
+		pgdriver.WithNetwork("tcp"),
+		pgdriver.WithAddr(c.Addr),
+		pgdriver.WithUser(c.User),
+		pgdriver.WithPassword(c.Pass),
+		pgdriver.WithDatabase(c.Name),
+		pgdriver.WithTLSConfig(tlsConfig),
+	}
+
+	if c.DialTimeout > 0 {
+		opts = append(opts, pgdriver.WithDialTimeout(c.DialTimeout))
+	}
+
+	if c.ReadTimeout > 0 {
+		opts = append(opts, pgdriver.WithReadTimeout(c.ReadTimeout))
+	}
+
+	if c.WriteTimeout > 0 {
+		opts = append(opts, pgdriver.WithWriteTimeout(c.WriteTimeout))
+	}
+
+	if len(c.params) > 0 {
+		opts = append(opts, pgdriver.WithConnParams(c.params))
+	}
+
+	sqldb := sql.OpenDB(pgdriver.NewConnector(opts...))
+	sqldb.SetMaxOpenConns(c.MaxOpenConns)
+	sqldb.SetConnMaxLifetime(c.ConnMaxLifetime)
+	sqldb.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+
+	if c.MaxIdleConns > 0 {
+		sqldb.SetMaxIdleConns(c.MaxIdleConns)
+	}
+
+	return bun.NewDB(sqldb, pgdialect.New()), nil
+}
+
+// tlsConfig возвращает TLS-конфигурацию для режима SSLMode с той же семантикой, что и в libpq.
+func (cfg *Config) tlsConfig() (*tls.Config, error) {
+	if cfg.SSLMode == "" || cfg.SSLMode == SSLDisable {
+		return nil, nil
+	}
+
+	tlsConfig, err := certs.ClientConfig(cfg.SSLRootCert, cfg.SSLCert, cfg.SSLKey)
+	if err != nil {
+		return nil, fmt.Errorf("postgres tls: %w", err)
+	}
+
+	mode := cfg.SSLMode
+
+	// Как и в libpq, require при заданном корневом сертификате работает как verify-ca
+	if mode == SSLRequire && cfg.SSLRootCert != "" {
+		mode = SSLVerifyCA
+	}
+
+	switch mode {
+	case SSLRequire:
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec
+	case SSLVerifyCA:
+		// Проверяется только цепочка сертификатов без имени хоста
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec
+		tlsConfig.VerifyPeerCertificate = verifyChain(tlsConfig.RootCAs)
+	case SSLVerifyFull:
+		host, _, err := net.SplitHostPort(cfg.Addr)
+		if err != nil {
+			host = cfg.Addr
+		}
+
+		tlsConfig.ServerName = host
+	default:
+		return nil, fmt.Errorf("postgres tls: unsupported sslmode %q", cfg.SSLMode)
+	}
+
+	return tlsConfig, nil
+}
+
+// IsSerializationFailure сообщает, что транзакция отменена из-за конфликта с параллельной
+// транзакцией или взаимной блокировки и может быть успешно повторена.
+func IsSerializationFailure(err error) bool {
+	var pgErr pgdriver.Error
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	code := pgErr.Field('C')
+
+	return code == "40001" || code == "40P01"
+}
+
+// IsConnectionRejected сообщает, что сервер отклонил подключение из-за неверных учетных данных
+// или несуществующей базы данных. Такую ошибку не исправит повторное подключение.
+func IsConnectionRejected(err error) bool {
+	var pgErr pgdriver.Error
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	code := pgErr.Field('C')
+
+	return strings.HasPrefix(code, "28") || code == "3D000"
+}
+
+func verifyChain(roots *x509.CertPool) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("postgres tls: no server certificate")
+		}
+
+		chain := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("postgres tls: %w", err)
+			}
+
+			chain = append(chain, cert)
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range chain[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := chain[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+
+		return err
+	}
+}
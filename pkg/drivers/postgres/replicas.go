@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+// lagQuery возвращает отставание реплики в секундах. Реплика, которая применила все
+// полученные изменения, не отстает, даже если на основном сервере давно не было записи.
+const lagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+// Replicas выбирает реплику для чтения по кругу среди доступных и отстающих
+// не больше допустимого.
+type Replicas struct {
+	log     *zerolog.Logger
+	dbs     []*bun.DB
+	healthy []atomic.Bool
+	next    atomic.Uint64
+	maxLag  time.Duration
+}
+
+// NewReplicas создает пулы соединений с репликами из cfg.Replicas с параметрами основного сервера.
+// Подключение к репликам не проверяется, поэтому недоступная реплика не мешает запуску сервиса.
+// До первой проверки в Run реплики считаются недоступными, и чтение идет из основной базы.
+func NewReplicas(cfg *Config, log *zerolog.Logger) (*Replicas, error) {
+	r := &Replicas{
+		log:     log,
+		dbs:     make([]*bun.DB, 0, len(cfg.Replicas)),
+		healthy: make([]atomic.Bool, len(cfg.Replicas)),
+		maxLag:  cfg.MaxReplicaLag,
+	}
+
+	for _, addr := range cfg.Replicas {
+		db, err := OpenDB(cfg.Replica(addr))
+		if err != nil {
+			return nil, errors.Join(err, r.Close())
+		}
+
+		r.dbs = append(r.dbs, db)
+	}
+
+	return r, nil
+}
+
+// DBs возвращает соединения с репликами.
+func (r *Replicas) DBs() []*bun.DB {
+	return r.dbs
+}
+
+// Next возвращает индекс следующей доступной реплики или false, если доступных реплик нет.
+func (r *Replicas) Next() (int, bool) {
+	healthy := make([]int, 0, len(r.dbs))
+	for i := range r.healthy {
+		if r.healthy[i].Load() {
+			healthy = append(healthy, i)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return 0, false
+	}
+
+	return healthy[r.next.Add(1)%uint64(len(healthy))], true
+}
+
+// Check проверяет доступность и отставание реплик.
+func (r *Replicas) Check(ctx context.Context) {
+	for i, db := range r.dbs {
+		var lag float64
+		err := db.NewRaw(lagQuery).Scan(ctx, &lag)
+
+		healthy := err == nil && time.Duration(lag*float64(time.Second)) <= r.maxLag
+		if r.healthy[i].Swap(healthy) != healthy {
+			r.log.Warn().Err(err).Int("replica", i).Float64("lag", lag).Bool("healthy", healthy).Msg("postgres replica state changed")
+		}
+	}
+}
+
+// Run проверяет реплики с периодом interval до отмены ctx.
+func (r *Replicas) Run(ctx context.Context, interval time.Duration) {
+	if len(r.dbs) == 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Replicas) Close() error {
+	var errs []error
+
+	for _, db := range r.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestReplicas_Next(t *testing.T) {
+	_, ok := (&Replicas{}).Next()
+	assert.False(t, ok, "replica selected without replicas")
+
+	r := &Replicas{
+		dbs:     make([]*bun.DB, 3),
+		healthy: make([]atomic.Bool, 3),
+	}
+
+	r.healthy[0].Store(true)
+	r.healthy[2].Store(true)
+
+	var selected []int
+	for i := 0; i < 4; i++ {
+		idx, ok := r.Next()
+		assert.True(t, ok)
+
+		selected = append(selected, idx)
+	}
+
+	assert.Equal(t, []int{2, 0, 2, 0}, selected, "unhealthy replica selected or order broken")
+
+	r.healthy[0].Store(false)
+	r.healthy[2].Store(false)
+
+	_, ok = r.Next()
+	assert.False(t, ok, "replica selected while all are lagging")
+}
+
+func TestNewReplicas_unavailable(t *testing.T) {
+	log := zerolog.Nop()
+
+	cfg := NewConfig()
+	cfg.User = "app"
+	cfg.Name = "service"
+	cfg.Replicas = []string{"127.0.0.1:1"}
+	cfg.DialTimeout = 100 * time.Millisecond
+
+	// Недоступная реплика не мешает запуску и не используется для чтения
+	r, err := NewReplicas(cfg, &log)
+	require.NoError(t, err)
+	defer r.Close()
+
+	_, ok := r.Next()
+	assert.False(t, ok, "replica selected before the first check")
+
+	r.Check(context.Background())
+
+	_, ok = r.Next()
+	assert.False(t, ok, "unavailable replica selected")
+}
@@ -2,20 +2,174 @@ package redisdb
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"service-template/pkg/certs"
+
+	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 )
 
-func NewRedisDB(cfg *Config) (*redis.Client, error) {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.Addr,
-		Password: cfg.Pass,
-		DB:       cfg.DB,
-	})
+// NewRedisDB подключается к Redis в режиме, указанном в конфигурации.
+// В режиме memory запускается встроенный сервер, данные которого теряются при закрытии клиента.
+func NewRedisDB(cfg *Config) (redis.UniversalClient, error) {
+	opts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	var rdb redis.UniversalClient
+
+	switch cfg.Mode {
+	case ModeMemory:
+		server, err := miniredis.Run()
+		if err != nil {
+			return nil, fmt.Errorf("redis memory: %w", err)
+		}
+
+		// Встроенный сервер доступен только локально, без пароля и TLS
+		simple := opts.Simple()
+		simple.Addr = server.Addr()
+		simple.Username, simple.Password, simple.TLSConfig = "", "", nil
+		rdb = &memoryClient{Client: redis.NewClient(simple), server: server}
+	case ModeSentinel:
+		rdb = redis.NewFailoverClient(opts.Failover())
+	case ModeCluster:
+		rdb = redis.NewClusterClient(opts.Cluster())
+	default:
+		rdb = redis.NewClient(opts.Simple())
+	}
 
 	if err := rdb.Ping(context.Background()).Err(); err != nil {
-		return nil, err
+		return nil, errors.Join(err, rdb.Close())
 	}
 
 	return rdb, nil
 }
+
+// IsConnectionRejected сообщает, что сервер отклонил подключение из-за неверных учетных данных
+// или недостатка прав. Такую ошибку не исправит повторное подключение.
+func IsConnectionRejected(err error) bool {
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return false
+	}
+
+	for _, prefix := range []string{"WRONGPASS", "NOAUTH", "NOPERM"} {
+		if redis.HasErrorPrefix(redisErr, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Options возвращает параметры клиента: значения из URL, переопределенные явно заданными полями.
+func (cfg *Config) Options() (*redis.UniversalOptions, error) {
+	opts := &redis.UniversalOptions{}
+
+	if cfg.URL != "" {
+		parsed, err := redis.ParseURL(cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("redis url: %w", err)
+		}
+
+		opts = universal(parsed)
+	}
+
+	if cfg.Mode == ModeSentinel || cfg.Mode == ModeCluster {
+		opts.Addrs = cfg.Addrs
+	} else if cfg.Addr != "" {
+		opts.Addrs = []string{cfg.Addr}
+	}
+
+	setString(&opts.MasterName, cfg.MasterName)
+	setString(&opts.Username, cfg.User)
+	setString(&opts.Password, cfg.Pass)
+	setString(&opts.SentinelUsername, cfg.SentinelUser)
+	setString(&opts.SentinelPassword, cfg.SentinelPass)
+	setInt(&opts.DB, cfg.DB)
+	setInt(&opts.PoolSize, cfg.PoolSize)
+	setInt(&opts.MinIdleConns, cfg.MinIdleConns)
+	setInt(&opts.MaxIdleConns, cfg.MaxIdleConns)
+
+	setDuration(&opts.ConnMaxLifetime, cfg.ConnMaxLifetime)
+	setDuration(&opts.ConnMaxIdleTime, cfg.ConnMaxIdleTime)
+	setDuration(&opts.PoolTimeout, cfg.PoolTimeout)
+	setDuration(&opts.DialTimeout, cfg.DialTimeout)
+	setDuration(&opts.ReadTimeout, cfg.ReadTimeout)
+	setDuration(&opts.WriteTimeout, cfg.WriteTimeout)
+
+	if cfg.TLS || opts.TLSConfig != nil {
+		tlsConfig, err := certs.ClientConfig(cfg.CACert, cfg.Cert, cfg.Key)
+		if err != nil {
+			return nil, fmt.Errorf("redis tls: %w", err)
+		}
+
+		// Имя сервера из URL rediss:// сохраняется для проверки сертификата
+		if opts.TLSConfig != nil {
+			tlsConfig.ServerName = opts.TLSConfig.ServerName
+		}
+
+		opts.TLSConfig = tlsConfig
+	}
+
+	return opts, nil
+}
+
+// universal преобразует параметры клиента одного узла, полученные из URL.
+func universal(o *redis.Options) *redis.UniversalOptions {
+	return &redis.UniversalOptions{
+		Addrs:           []string{o.Addr},
+		ClientName:      o.ClientName,
+		DB:              o.DB,
+		Username:        o.Username,
+		Password:        o.Password,
+		MaxRetries:      o.MaxRetries,
+		MinRetryBackoff: o.MinRetryBackoff,
+		MaxRetryBackoff: o.MaxRetryBackoff,
+		DialTimeout:     o.DialTimeout,
+		ReadTimeout:     o.ReadTimeout,
+		WriteTimeout:    o.WriteTimeout,
+		PoolFIFO:        o.PoolFIFO,
+		PoolSize:        o.PoolSize,
+		PoolTimeout:     o.PoolTimeout,
+		MinIdleConns:    o.MinIdleConns,
+		MaxIdleConns:    o.MaxIdleConns,
+		ConnMaxIdleTime: o.ConnMaxIdleTime,
+		ConnMaxLifetime: o.ConnMaxLifetime,
+		TLSConfig:       o.TLSConfig,
+	}
+}
+
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+func setInt(dst *int, value int) {
+	if value != 0 {
+		*dst = value
+	}
+}
+
+func setDuration(dst *time.Duration, value time.Duration) {
+	if value != 0 {
+		*dst = value
+	}
+}
+
+// memoryClient клиент встроенного сервера, который останавливается при закрытии клиента.
+type memoryClient struct {
+	*redis.Client
+	server *miniredis.Miniredis
+}
+
+func (c *memoryClient) Close() error {
+	defer c.server.Close()
+
+	return c.Client.Close()
+}
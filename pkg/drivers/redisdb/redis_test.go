@@ -0,0 +1,47 @@
+package redisdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRedisDB_memory(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Mode = ModeMemory
+	cfg.Pass = "secret"
+
+	require.NoError(t, cfg.Validate())
+
+	rdb, err := NewRedisDB(cfg)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, rdb.Set(ctx, "key", "value", 0).Err())
+	assert.Equal(t, "value", rdb.Get(ctx, "key").Val())
+
+	require.NoError(t, rdb.Close())
+	assert.Error(t, rdb.Ping(ctx).Err(), "client closed")
+}
+
+func TestIsConnectionRejected(t *testing.T) {
+	srv := miniredis.RunT(t)
+	srv.RequireAuth("secret")
+
+	cfg := NewConfig()
+	cfg.Addr = srv.Addr()
+	cfg.Pass = "wrong"
+
+	_, err := NewRedisDB(cfg)
+	require.Error(t, err)
+	assert.True(t, IsConnectionRejected(err), "wrong password: %v", err)
+
+	srv.Close()
+
+	_, err = NewRedisDB(cfg)
+	require.Error(t, err)
+	assert.False(t, IsConnectionRejected(err), "server unavailable: %v", err)
+}
@@ -0,0 +1,34 @@
+package sqlite
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Memory путь базы данных в памяти процесса, данные теряются при остановке.
+const Memory = ":memory:"
+
+// Config параметры SQLite. Если SQLite включен, он используется вместо Postgres.
+type Config struct {
+	Enabled bool `json:"enabled" yaml:"enabled" env:"X_SQLITE_ENABLED"`
+	// Path путь к файлу базы данных или :memory:.
+	Path string `json:"path" yaml:"path" env:"X_SQLITE_PATH"`
+	// BusyTimeout время ожидания блокировки базы другим соединением.
+	BusyTimeout time.Duration `json:"busy_timeout" yaml:"busy_timeout" env:"X_SQLITE_BUSY_TIMEOUT"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		Path:        "template.db",
+		BusyTimeout: 5 * time.Second,
+	}
+}
+
+func (cfg *Config) Validate() error {
+	return validation.ValidateStruct(cfg,
+		validation.Field(&cfg.Path, validation.When(cfg.Enabled, validation.Required)),
+		validation.Field(&cfg.BusyTimeout, validation.Min(time.Duration(0))),
+	)
+}
@@ -0,0 +1,71 @@
+// Package sqlite подключает bun к SQLite. Используется для локальной разработки
+// и тестов вместо Postgres.
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+func NewSQLiteDB(cfg *Config) (*bun.DB, error) {
+	sqldb, err := sql.Open("sqlite", cfg.dsn())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: %w", err)
+	}
+
+	// Каждое соединение с :memory: открывает отдельную пустую базу
+	if cfg.Path == Memory {
+		sqldb.SetMaxOpenConns(1)
+	}
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+
+	if err := db.Ping(); err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+
+	return db, nil
+}
+
+// Is сообщает, что db работает с SQLite. Нужен запросам, синтаксис которых
+// отличается от Postgres.
+func Is(db bun.IDB) bool {
+	return db.Dialect().Name() == dialect.SQLite
+}
+
+// IsBusy сообщает, что база заблокирована другой транзакцией дольше busy_timeout
+// и транзакцию можно повторить.
+func IsBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	// Младший байт расширенного кода — основной код ошибки
+	code := sqliteErr.Code() & 0xff
+
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+// dsn возвращает строку подключения. Транзакции сразу захватывают блокировку записи,
+// чтобы параллельные транзакции ожидали друг друга, а не завершались ошибкой.
+func (cfg *Config) dsn() string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.BusyTimeout.Milliseconds()))
+	params.Set("_txlock", "immediate")
+
+	if cfg.Path != Memory {
+		params.Add("_pragma", "journal_mode(WAL)")
+	}
+
+	return "file:" + cfg.Path + "?" + params.Encode()
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+type item struct {
+	bun.BaseModel `bun:"table:items"`
+	ID            uint64     `bun:"id,pk,autoincrement"`
+	Tags          []string   `bun:"tags,array"`
+	CreatedAt     *time.Time `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+func TestNewSQLiteDB(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Enabled = true
+	cfg.Path = filepath.Join(t.TempDir(), "test.db")
+
+	require.NoError(t, cfg.Validate())
+
+	db, err := NewSQLiteDB(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.True(t, Is(db))
+
+	ctx := context.Background()
+	_, err = db.NewCreateTable().Model((*item)(nil)).Exec(ctx)
+	require.NoError(t, err)
+
+	created := &item{Tags: []string{"a", "b"}}
+	_, err = db.NewInsert().Model(created).Returning("*").Exec(ctx)
+	require.NoError(t, err)
+	assert.NotZero(t, created.ID)
+	assert.NotNil(t, created.CreatedAt)
+
+	var loaded item
+	require.NoError(t, db.NewSelect().Model(&loaded).Where("id = ?", created.ID).Scan(ctx))
+	assert.Equal(t, []string{"a", "b"}, loaded.Tags)
+
+	var fk int
+	require.NoError(t, db.NewRaw("PRAGMA foreign_keys").Scan(ctx, &fk))
+	assert.Equal(t, 1, fk)
+}
+
+func TestNewSQLiteDB_memory(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Path = Memory
+
+	db, err := NewSQLiteDB(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Все запросы должны видеть одну и ту же базу
+	_, err = db.NewCreateTable().Model((*item)(nil)).Exec(context.Background())
+	require.NoError(t, err)
+
+	exists, err := db.NewSelect().Model((*item)(nil)).Exists(context.Background())
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
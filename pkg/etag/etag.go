@@ -0,0 +1,40 @@
+package etag
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Any значение If-Match, совпадающее с любой версией ресурса.
+const Any = "*"
+
+var ErrInvalid = errors.New("invalid entity tag")
+
+// Format формирует значение заголовка ETag по версии ресурса.
+func Format(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// Parse извлекает версию ресурса из заголовка If-Match.
+// Для пустого заголовка и "*" возвращается 0, т.е. версия не проверяется.
+func Parse(header string) (uint64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == Any {
+		return 0, nil
+	}
+
+	// Слабые теги сравниваем так же, как сильные
+	header = strings.TrimPrefix(header, "W/")
+
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, ErrInvalid
+	}
+
+	version, err := strconv.ParseUint(header[1:len(header)-1], 10, 64)
+	if err != nil || version == 0 {
+		return 0, ErrInvalid
+	}
+
+	return version, nil
+}
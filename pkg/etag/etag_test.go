@@ -0,0 +1,36 @@
+package etag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, `"42"`, Format(42), "wrong entity tag")
+}
+
+func TestParse(t *testing.T) {
+	version, err := Parse(Format(7))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), version, "versions not equal")
+
+	version, err = Parse(`W/"3"`)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), version, "weak tag not parsed")
+}
+
+func TestParse_any(t *testing.T) {
+	for _, header := range []string{"", Any, " * "} {
+		version, err := Parse(header)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), version, "version must be empty")
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for _, header := range []string{`7`, `"abc"`, `"0"`, `"`, `"1", "2"`} {
+		_, err := Parse(header)
+		assert.ErrorIs(t, err, ErrInvalid, header)
+	}
+}
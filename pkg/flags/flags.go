@@ -0,0 +1,101 @@
+// Package flags вычисляет значения флагов функциональности по правилам таргетинга.
+package flags
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+)
+
+// Flag флаг функциональности.
+type Flag struct {
+	Key string
+	// Enabled главный выключатель: выключенный флаг не включен ни для кого.
+	Enabled bool
+	// Rules правила таргетинга. Включенный флаг без правил включен для всех,
+	// иначе только для тех, кто подходит хотя бы под одно правило.
+	Rules []Rule
+}
+
+// Rule правило таргетинга. Пользователь подходит под правило,
+// если выполнены все заданные в нем условия.
+type Rule struct {
+	UserIDs      []uint64 `json:"user_ids,omitempty"`
+	Roles        []string `json:"roles,omitempty"`
+	Environments []string `json:"environments,omitempty"`
+	// Percentage доля пользователей от 0 до 100. Пользователь попадает в одну
+	// и ту же долю при каждом вычислении, поэтому увеличение доли не исключает
+	// уже включенных пользователей.
+	Percentage *int `json:"percentage,omitempty"`
+}
+
+// Target контекст вычисления флага. Нулевой UserID означает анонимного пользователя.
+type Target struct {
+	UserID      uint64
+	Roles       []string
+	Environment string
+}
+
+// Evaluate вычисляет значение флага для target.
+func (f *Flag) Evaluate(target Target) bool {
+	if f == nil || !f.Enabled {
+		return false
+	}
+
+	if len(f.Rules) == 0 {
+		return true
+	}
+
+	for _, rule := range f.Rules {
+		if rule.Match(f.Key, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match проверяет, подходит ли target под правило флага key.
+func (r Rule) Match(key string, target Target) bool {
+	if len(r.UserIDs) > 0 && !slices.Contains(r.UserIDs, target.UserID) {
+		return false
+	}
+
+	if len(r.Roles) > 0 && !containsAny(r.Roles, target.Roles) {
+		return false
+	}
+
+	if len(r.Environments) > 0 && !slices.Contains(r.Environments, target.Environment) {
+		return false
+	}
+
+	if r.Percentage != nil {
+		if target.UserID == 0 {
+			return false
+		}
+
+		return Bucket(key, target.UserID) < *r.Percentage
+	}
+
+	return true
+}
+
+// Bucket возвращает номер доли от 0 до 99, в которую попадает пользователь для флага key.
+// Доли разных флагов независимы.
+func Bucket(key string, userID uint64) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key + ":" + strconv.FormatUint(userID, 10)))
+
+	return int(h.Sum32() % 100)
+}
+
+func containsAny(list []string, values []string) bool {
+	for _, value := range values {
+		if slices.Contains(list, value) {
+			return true
+		}
+	}
+
+	return false
+}
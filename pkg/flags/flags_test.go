@@ -0,0 +1,110 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func percentage(n int) *int {
+	return &n
+}
+
+func TestFlag_Evaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		flag   *Flag
+		target Target
+		want   bool
+	}{
+		{
+			name:   "nil",
+			flag:   nil,
+			target: Target{UserID: 1},
+			want:   false,
+		},
+		{
+			name:   "disabled",
+			flag:   &Flag{Key: "f", Rules: []Rule{{UserIDs: []uint64{1}}}},
+			target: Target{UserID: 1},
+			want:   false,
+		},
+		{
+			name:   "enabled without rules",
+			flag:   &Flag{Key: "f", Enabled: true},
+			target: Target{},
+			want:   true,
+		},
+		{
+			name:   "user",
+			flag:   &Flag{Key: "f", Enabled: true, Rules: []Rule{{UserIDs: []uint64{1, 2}}}},
+			target: Target{UserID: 2},
+			want:   true,
+		},
+		{
+			name:   "other user",
+			flag:   &Flag{Key: "f", Enabled: true, Rules: []Rule{{UserIDs: []uint64{1, 2}}}},
+			target: Target{UserID: 3},
+			want:   false,
+		},
+		{
+			name:   "role",
+			flag:   &Flag{Key: "f", Enabled: true, Rules: []Rule{{Roles: []string{"admin"}}}},
+			target: Target{UserID: 3, Roles: []string{"user", "admin"}},
+			want:   true,
+		},
+		{
+			name:   "all conditions of rule",
+			flag:   &Flag{Key: "f", Enabled: true, Rules: []Rule{{Roles: []string{"admin"}, Environments: []string{"staging"}}}},
+			target: Target{UserID: 3, Roles: []string{"admin"}, Environment: "production"},
+			want:   false,
+		},
+		{
+			name: "any rule",
+			flag: &Flag{Key: "f", Enabled: true, Rules: []Rule{
+				{Environments: []string{"staging"}},
+				{UserIDs: []uint64{3}},
+			}},
+			target: Target{UserID: 3, Environment: "production"},
+			want:   true,
+		},
+		{
+			name:   "full rollout",
+			flag:   &Flag{Key: "f", Enabled: true, Rules: []Rule{{Percentage: percentage(100)}}},
+			target: Target{UserID: 42},
+			want:   true,
+		},
+		{
+			name:   "zero rollout",
+			flag:   &Flag{Key: "f", Enabled: true, Rules: []Rule{{Percentage: percentage(0)}}},
+			target: Target{UserID: 42},
+			want:   false,
+		},
+		{
+			name:   "rollout for anonymous",
+			flag:   &Flag{Key: "f", Enabled: true, Rules: []Rule{{Percentage: percentage(100)}}},
+			target: Target{},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.flag.Evaluate(tt.target), "values not equal")
+		})
+	}
+}
+
+func TestBucket(t *testing.T) {
+	enabled := 0
+	for id := uint64(1); id <= 10000; id++ {
+		bucket := Bucket("flag", id)
+		assert.Equal(t, bucket, Bucket("flag", id), "bucket is not stable")
+
+		if bucket < 25 {
+			enabled++
+		}
+	}
+
+	assert.InDelta(t, 2500, enabled, 250, "rollout is not uniform")
+}
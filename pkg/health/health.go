@@ -0,0 +1,231 @@
+// Package health выполняет проверки живости и готовности сервиса.
+// Проверки живости показывают, что процесс работоспособен и его не нужно перезапускать,
+// проверки готовности — что сервис может принимать запросы.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// ErrShutdown возвращается проверкой готовности после начала остановки сервиса.
+var ErrShutdown = errors.New("shutting down")
+
+// Check проверка состояния зависимости или компонента сервиса.
+type Check func(ctx context.Context) error
+
+// Result результат одной проверки.
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report отчет о выполнении проверок.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Up возвращает true, если все проверки выполнены успешно.
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+type check struct {
+	name  string
+	check Check
+}
+
+// Health реестр проверок живости и готовности.
+type Health struct {
+	timeout  time.Duration
+	mu       sync.RWMutex
+	live     []check
+	ready    []check
+	shutdown atomic.Bool
+}
+
+// New создает реестр проверок, каждая проверка выполняется не дольше timeout.
+func New(timeout time.Duration) *Health {
+	return &Health{timeout: timeout}
+}
+
+// Live регистрирует проверку живости, она также входит в проверку готовности.
+func (h *Health) Live(name string, c Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.live = append(h.live, check{name: name, check: c})
+}
+
+// Ready регистрирует проверку готовности.
+func (h *Health) Ready(name string, c Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.ready = append(h.ready, check{name: name, check: c})
+}
+
+// Worker регистрирует фоновый процесс, проверка живости которого не проходит,
+// если процесс не запущен или завершился до остановки сервиса.
+func (h *Health) Worker(name string) *Worker {
+	w := &Worker{health: h}
+	h.Live(name, w.check)
+
+	return w
+}
+
+// Shutdown переводит сервис в состояние остановки, после чего проверка готовности не проходит.
+func (h *Health) Shutdown() {
+	h.shutdown.Store(true)
+}
+
+// Liveness выполняет проверки живости.
+func (h *Health) Liveness(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := append([]check(nil), h.live...)
+	h.mu.RUnlock()
+
+	return h.run(ctx, checks)
+}
+
+// Readiness выполняет проверки живости и готовности.
+func (h *Health) Readiness(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := append(append([]check(nil), h.live...), h.ready...)
+	h.mu.RUnlock()
+
+	if h.shutdown.Load() {
+		checks = append(checks, check{name: "shutdown", check: func(context.Context) error {
+			return ErrShutdown
+		}})
+	}
+
+	return h.run(ctx, checks)
+}
+
+// run выполняет проверки параллельно.
+func (h *Health) run(ctx context.Context, checks []check) Report {
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, c := range checks {
+		wg.Add(1)
+
+		go func(c check) {
+			defer wg.Done()
+
+			result := h.execute(ctx, c.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[c.name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(c)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func (h *Health) execute(ctx context.Context, c Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := call(ctx, c)
+
+	result := Result{Status: StatusUp, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// call выполняет проверку, не дожидаясь ее завершения после отмены контекста.
+func call(ctx context.Context, c Check) error {
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		done <- c(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timeout: %w", ctx.Err())
+	}
+}
+
+// Worker состояние фонового процесса.
+type Worker struct {
+	health  *Health
+	mu      sync.Mutex
+	running bool
+	err     error
+}
+
+// Run отмечает процесс запущенным на время выполнения fn.
+func (w *Worker) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	w.Start()
+
+	err := fn(ctx)
+	w.Stop(err)
+
+	return err
+}
+
+// Start отмечает процесс запущенным.
+func (w *Worker) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running, w.err = true, nil
+}
+
+// Stop отмечает процесс завершенным с ошибкой err.
+func (w *Worker) Stop(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running, w.err = false, err
+}
+
+func (w *Worker) check(context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch {
+	case w.running || w.health.shutdown.Load():
+		return nil
+	case w.err != nil:
+		return fmt.Errorf("worker stopped: %w", w.err)
+	}
+
+	return errors.New("worker is not running")
+}
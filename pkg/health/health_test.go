@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Readiness(t *testing.T) {
+	h := New(time.Second)
+	h.Ready("postgres", func(context.Context) error { return nil })
+	h.Ready("redis", func(context.Context) error { return errors.New("connection refused") })
+
+	report := h.Readiness(context.Background())
+	assert.False(t, report.Up(), "report is up")
+	assert.Equal(t, StatusUp, report.Checks["postgres"].Status)
+	assert.Equal(t, StatusDown, report.Checks["redis"].Status)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+
+	// Проверки готовности не входят в проверку живости
+	assert.True(t, h.Liveness(context.Background()).Up(), "liveness is down")
+}
+
+func TestHealth_timeout(t *testing.T) {
+	h := New(10 * time.Millisecond)
+	h.Ready("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	report := h.Readiness(context.Background())
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "timeout not applied")
+	assert.Equal(t, StatusDown, report.Checks["slow"].Status)
+	assert.Contains(t, report.Checks["slow"].Error, "timeout")
+}
+
+func TestHealth_Shutdown(t *testing.T) {
+	h := New(time.Second)
+	assert.True(t, h.Readiness(context.Background()).Up(), "readiness is down")
+
+	h.Shutdown()
+
+	report := h.Readiness(context.Background())
+	assert.False(t, report.Up(), "readiness is up after shutdown")
+	assert.Equal(t, ErrShutdown.Error(), report.Checks["shutdown"].Error)
+}
+
+func TestWorker(t *testing.T) {
+	h := New(time.Second)
+	w := h.Worker("jobs")
+
+	assert.False(t, h.Liveness(context.Background()).Up(), "worker is up before start")
+
+	w.Start()
+	assert.True(t, h.Liveness(context.Background()).Up(), "worker is down after start")
+
+	w.Stop(errors.New("connection lost"))
+	report := h.Liveness(context.Background())
+	assert.False(t, report.Up(), "worker is up after failure")
+	assert.Contains(t, report.Checks["jobs"].Error, "connection lost")
+
+	// Остановка процессов при завершении сервиса не считается отказом
+	h.Shutdown()
+	assert.True(t, h.Liveness(context.Background()).Up(), "worker is down after shutdown")
+}
@@ -0,0 +1,303 @@
+// Package lifecycle управляет запуском и остановкой компонентов сервиса.
+// Компоненты запускаются с учетом зависимостей и останавливаются в обратном порядке.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"service-template/pkg/health"
+
+	"github.com/rs/zerolog"
+)
+
+// Состояния компонента.
+const (
+	StatePending  = "pending"
+	StateRunning  = "running"
+	StateStopping = "stopping"
+	StateStopped  = "stopped"
+	StateFailed   = "failed"
+)
+
+// Component компонент сервиса. Все функции необязательны.
+type Component struct {
+	Name string
+	// DependsOn компоненты, которые должны быть запущены раньше и остановлены позже этого.
+	DependsOn []string
+	// Start инициализирует компонент. Ошибка прерывает запуск сервиса.
+	Start func(ctx context.Context) error
+	// Run выполняется в отдельной горутине после Start до остановки компонента.
+	// Контекст Run отменяется перед вызовом Stop.
+	Run func(ctx context.Context) error
+	// Stop останавливает компонент и дожидается завершения выполняемой работы.
+	Stop func(ctx context.Context) error
+	// StopTimeout ограничивает время Stop, по умолчанию ограничено только общим временем остановки.
+	StopTimeout time.Duration
+	// Critical завершение Run компонента с ошибкой приводит к остановке сервиса,
+	// а пока компонент не выполняется, не проходит проверка живости. Сбой остальных
+	// компонентов не требует перезапуска сервиса и отражается только в их состоянии.
+	Critical bool
+	// Checks проверки готовности компонента.
+	Checks map[string]health.Check
+}
+
+// Status состояние компонента.
+type Status struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+type component struct {
+	Component
+	state  string
+	err    error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Manager запускает и останавливает зарегистрированные компоненты.
+type Manager struct {
+	log        *zerolog.Logger
+	health     *health.Health
+	mu         sync.Mutex
+	components []*component
+	started    []*component
+	failed     chan error
+}
+
+func New(log *zerolog.Logger, h *health.Health) *Manager {
+	return &Manager{
+		log:    log,
+		health: h,
+		failed: make(chan error, 1),
+	}
+}
+
+// Register регистрирует компонент. Вызывается до Start.
+func (m *Manager) Register(c Component) {
+	m.components = append(m.components, &component{Component: c, state: StatePending})
+
+	for name, check := range c.Checks {
+		m.health.Ready(name, check)
+	}
+}
+
+// Failed возвращает канал, в который отправляется ошибка критичного компонента.
+func (m *Manager) Failed() <-chan error {
+	return m.failed
+}
+
+// Start запускает компоненты в порядке зависимостей. При ошибке уже запущенные
+// компоненты остаются запущенными и должны быть остановлены вызовом Stop.
+func (m *Manager) Start(ctx context.Context) error {
+	order, err := m.order()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range order {
+		m.log.Info().Str("component", c.Name).Msg("start component")
+
+		if c.Start != nil {
+			if err = c.Start(ctx); err != nil {
+				m.setState(c, StateFailed, err)
+				return fmt.Errorf("start %s: %w", c.Name, err)
+			}
+		}
+
+		m.mu.Lock()
+		m.started = append(m.started, c)
+		m.mu.Unlock()
+
+		if c.Run != nil {
+			m.run(c)
+		}
+
+		m.setState(c, StateRunning, nil)
+	}
+
+	return nil
+}
+
+// Stop останавливает запущенные компоненты в обратном порядке, но не дольше, чем до отмены ctx.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	var errs []error
+
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+
+		m.log.Info().Str("component", c.Name).Msg("stop component")
+		m.setState(c, StateStopping, nil)
+
+		if err := m.stop(ctx, c); err != nil {
+			m.setState(c, StateFailed, err)
+			errs = append(errs, fmt.Errorf("stop %s: %w", c.Name, err))
+			continue
+		}
+
+		m.setState(c, StateStopped, nil)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Status возвращает состояние компонентов в порядке регистрации.
+func (m *Manager) Status() []Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]Status, 0, len(m.components))
+	for _, c := range m.components {
+		s := Status{Name: c.Name, State: c.state}
+		if c.err != nil {
+			s.Error = c.err.Error()
+		}
+
+		list = append(list, s)
+	}
+
+	return list
+}
+
+// run запускает Run компонента. Критичный компонент отслеживается в проверке живости.
+func (m *Manager) run(c *component) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	run := c.Run
+	if c.Critical {
+		w := m.health.Worker(c.Name)
+		w.Start()
+
+		run = func(ctx context.Context) error {
+			return w.Run(ctx, c.Run)
+		}
+	}
+
+	go func() {
+		defer close(c.done)
+
+		err := run(ctx)
+		if err == nil {
+			return
+		}
+
+		err = fmt.Errorf("%s: %w", c.Name, err)
+		m.setState(c, StateFailed, err)
+		m.log.Error().Err(err).Str("component", c.Name).Msg("component failed")
+
+		if c.Critical {
+			select {
+			case m.failed <- err:
+			default:
+			}
+		}
+	}()
+}
+
+func (m *Manager) stop(ctx context.Context, c *component) error {
+	if c.StopTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.StopTimeout)
+		defer cancel()
+	}
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	var err error
+	if c.Stop != nil {
+		err = c.Stop(ctx)
+	}
+
+	if c.done != nil {
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			err = errors.Join(err, fmt.Errorf("wait run: %w", ctx.Err()))
+		}
+	}
+
+	return err
+}
+
+func (m *Manager) setState(c *component, state string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Ошибку выполнения не затирает последующая остановка
+	if c.state == StateFailed && state != StateFailed {
+		return
+	}
+
+	c.state, c.err = state, err
+}
+
+// order возвращает компоненты в порядке запуска: каждый компонент следует за своими
+// зависимостями, а независимые компоненты сохраняют порядок регистрации.
+func (m *Manager) order() ([]*component, error) {
+	byName := make(map[string]*component, len(m.components))
+	for _, c := range m.components {
+		if _, ok := byName[c.Name]; ok {
+			return nil, fmt.Errorf("duplicate component %q", c.Name)
+		}
+
+		byName[c.Name] = c
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	marks := make(map[string]int, len(m.components))
+	order := make([]*component, 0, len(m.components))
+
+	var visit func(c *component, path []string) error
+	visit = func(c *component, path []string) error {
+		switch marks[c.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %v", append(path, c.Name))
+		}
+
+		marks[c.Name] = visiting
+
+		for _, name := range c.DependsOn {
+			dep, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("component %q depends on unknown component %q", c.Name, name)
+			}
+
+			if err := visit(dep, append(path, c.Name)); err != nil {
+				return err
+			}
+		}
+
+		marks[c.Name] = visited
+		order = append(order, c)
+
+		return nil
+	}
+
+	for _, c := range m.components {
+		if err := visit(c, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
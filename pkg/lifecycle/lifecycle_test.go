@@ -0,0 +1,162 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"service-template/pkg/health"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newManager() *Manager {
+	log := zerolog.Nop()
+	return New(&log, health.New(time.Second))
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) component(name string, deps ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: deps,
+		Start: func(context.Context) error {
+			r.add("start " + name)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestManager_order(t *testing.T) {
+	m := newManager()
+	r := &recorder{}
+
+	m.Register(r.component("http", "storage", "cache"))
+	m.Register(r.component("storage"))
+	m.Register(r.component("cache", "storage"))
+	m.Register(r.component("metrics"))
+
+	ctx := context.Background()
+	require.NoError(t, m.Start(ctx))
+	require.NoError(t, m.Stop(ctx))
+
+	assert.Equal(t, []string{
+		"start storage", "start cache", "start http", "start metrics",
+		"stop metrics", "stop http", "stop cache", "stop storage",
+	}, r.events)
+
+	for _, s := range m.Status() {
+		assert.Equal(t, StateStopped, s.State, s.Name)
+	}
+}
+
+func TestManager_invalid(t *testing.T) {
+	r := &recorder{}
+
+	m := newManager()
+	m.Register(r.component("a", "b"))
+	m.Register(r.component("b", "a"))
+	assert.ErrorContains(t, m.Start(context.Background()), "dependency cycle")
+
+	m = newManager()
+	m.Register(r.component("a", "missing"))
+	assert.ErrorContains(t, m.Start(context.Background()), "unknown component")
+
+	assert.Empty(t, r.events, "components started")
+}
+
+func TestManager_startFailed(t *testing.T) {
+	m := newManager()
+	r := &recorder{}
+
+	m.Register(r.component("storage"))
+	m.Register(Component{
+		Name:      "http",
+		DependsOn: []string{"storage"},
+		Start:     func(context.Context) error { return errors.New("port in use") },
+	})
+
+	ctx := context.Background()
+	assert.ErrorContains(t, m.Start(ctx), "start http: port in use")
+	require.NoError(t, m.Stop(ctx))
+
+	assert.Equal(t, []string{"start storage", "stop storage"}, r.events, "started components not stopped")
+}
+
+func TestManager_run(t *testing.T) {
+	h := health.New(time.Second)
+	log := zerolog.Nop()
+	m := New(&log, h)
+	stopped := make(chan struct{})
+
+	m.Register(Component{
+		Name: "worker",
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			close(stopped)
+			return nil
+		},
+	})
+	m.Register(Component{
+		Name: "relay",
+		Run: func(ctx context.Context) error {
+			return errors.New("broker unavailable")
+		},
+	})
+	m.Register(Component{
+		Name:     "listener",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			return errors.New("address already in use")
+		},
+	})
+
+	ctx := context.Background()
+	require.NoError(t, m.Start(ctx))
+
+	select {
+	case err := <-m.Failed():
+		assert.ErrorContains(t, err, "listener: address already in use")
+	case <-time.After(time.Second):
+		t.Fatal("critical failure not reported")
+	}
+
+	// Живость зависит только от критичных компонентов
+	report := h.Liveness(ctx)
+	assert.False(t, report.Up(), "liveness is up after critical failure")
+	assert.Contains(t, report.Checks, "listener")
+	assert.NotContains(t, report.Checks, "relay")
+	assert.NotContains(t, report.Checks, "worker")
+
+	require.NoError(t, m.Stop(ctx))
+
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("run context not cancelled")
+	}
+
+	status := m.Status()
+	assert.Equal(t, StateStopped, status[0].State)
+	assert.Equal(t, StateFailed, status[1].State, "non-critical failure not reported")
+	assert.Equal(t, StateFailed, status[2].State, "failure overwritten by stop")
+}
@@ -0,0 +1,81 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Election параметры выбора лидера.
+type Election struct {
+	// Key имя блокировки, которую удерживает лидер.
+	Key string
+	// Retry интервал попыток стать лидером.
+	Retry time.Duration
+	// OnElected вызывается, когда экземпляр стал лидером. Контекст отменяется
+	// при потере лидерства или остановке выбора, после чего OnElected должен вернуть управление.
+	OnElected func(ctx context.Context)
+	// OnRevoked вызывается после завершения OnElected, когда экземпляр перестал быть лидером.
+	OnRevoked func()
+}
+
+// Elect участвует в выборе лидера до отмены ctx. Лидером становится экземпляр,
+// захвативший блокировку Key. Если OnElected вернул управление до потери лидерства,
+// блокировка освобождается и выбор начинается заново.
+func Elect(ctx context.Context, locker Locker, e Election) error {
+	for {
+		l, err := Acquire(ctx, locker, e.Key, e.Retry)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			// Ошибка соединения, повторяем попытку после задержки
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(e.Retry):
+				continue
+			}
+		}
+
+		lead(ctx, l, e)
+
+		if err = l.Unlock(context.Background()); err != nil && !errors.Is(err, ErrLost) {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// lead вызывает обработчики лидерства и ожидает его окончания.
+func lead(ctx context.Context, l Lock, e Election) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		if e.OnElected != nil {
+			e.OnElected(leaderCtx)
+		}
+	}()
+
+	select {
+	case <-l.Lost():
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	cancel()
+	<-done
+
+	if e.OnRevoked != nil {
+		e.OnRevoked()
+	}
+}
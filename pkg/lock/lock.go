@@ -0,0 +1,54 @@
+// Package lock реализует распределенные блокировки на Redis и Postgres
+// и выбор лидера среди экземпляров сервиса.
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotAcquired блокировка захвачена другим владельцем.
+	ErrNotAcquired = errors.New("lock: not acquired")
+	// ErrLost блокировка потеряна, например, из-за истечения срока или разрыва соединения.
+	ErrLost = errors.New("lock: lost")
+)
+
+// Lock захваченная блокировка.
+type Lock interface {
+	// Key имя блокировки.
+	Key() string
+	// Token монотонно возрастающий номер захвата блокировки. Передается во внешние
+	// системы, чтобы они отклоняли запросы владельцев с устаревшим номером.
+	// Реализации без поддержки номеров возвращают 0.
+	Token() uint64
+	// Lost закрывается, когда блокировка потеряна.
+	Lost() <-chan struct{}
+	// Unlock освобождает блокировку. Возвращает ErrLost, если блокировка уже потеряна.
+	Unlock(ctx context.Context) error
+}
+
+// Locker захватывает блокировки, общие для всех экземпляров сервиса.
+type Locker interface {
+	// TryLock захватывает блокировку key без ожидания.
+	// Если блокировка захвачена другим владельцем, возвращает ErrNotAcquired.
+	TryLock(ctx context.Context, key string) (Lock, error)
+}
+
+// Acquire ожидает освобождения блокировки, повторяя попытки захвата
+// с интервалом retry, до отмены ctx.
+func Acquire(ctx context.Context, locker Locker, key string, retry time.Duration) (Lock, error) {
+	for {
+		l, err := locker.TryLock(ctx, key)
+		if !errors.Is(err, ErrNotAcquired) {
+			return l, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedis(t *testing.T, ttl time.Duration) (*Redis, *miniredis.Miniredis) {
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+
+	return NewRedis(rdb, ttl), srv
+}
+
+func TestRedis_TryLock(t *testing.T) {
+	locker, _ := newRedis(t, time.Minute)
+	ctx := context.Background()
+
+	first, err := locker.TryLock(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), first.Token(), "tokens not equal")
+
+	_, err = locker.TryLock(ctx, "key")
+	assert.ErrorIs(t, err, ErrNotAcquired, "lock acquired twice")
+
+	require.NoError(t, first.Unlock(ctx))
+
+	second, err := locker.TryLock(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), second.Token(), "token not incremented")
+	require.NoError(t, second.Unlock(ctx))
+}
+
+func TestRedis_lost(t *testing.T) {
+	locker, srv := newRedis(t, 30*time.Millisecond)
+	ctx := context.Background()
+
+	l, err := locker.TryLock(ctx, "key")
+	require.NoError(t, err)
+
+	key, _ := redisKeys("key")
+
+	// Аренда продлевается, пока блокировка не потеряна
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, srv.Exists(key), "lease not extended")
+
+	srv.Set(key, "other")
+
+	select {
+	case <-l.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lock not lost")
+	}
+
+	assert.ErrorIs(t, l.Unlock(ctx), ErrLost, "errors not equal")
+	assert.True(t, srv.Exists(key), "other owner lock released")
+}
+
+func TestRedis_keySlot(t *testing.T) {
+	// Значения из документации CLUSTER KEYSLOT
+	assert.Equal(t, 12182, keySlot("foo"))
+	assert.Equal(t, 11058, keySlot("somekey"))
+
+	for _, name := range []string{"key", "scheduler:users.purge_deleted", "with{braces}", "{"} {
+		lockKey, fenceKey := redisKeys(name)
+		assert.Equal(t, keySlot(lockKey), keySlot(fenceKey), "keys of %q in different slots", name)
+	}
+}
+
+// keySlot вычисляет слот ключа в Redis Cluster.
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	// CRC16 XMODEM
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return int(crc) % 16384
+}
+
+func TestElect(t *testing.T) {
+	locker, _ := newRedis(t, time.Minute)
+
+	var leaders atomic.Int32
+	var revoked atomic.Int32
+
+	election := Election{
+		Key:   "leader",
+		Retry: 10 * time.Millisecond,
+		OnElected: func(ctx context.Context) {
+			leaders.Add(1)
+			<-ctx.Done()
+		},
+		OnRevoked: func() {
+			leaders.Add(-1)
+			revoked.Add(1)
+		},
+	}
+
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+
+	firstDone := make(chan error, 1)
+	go func() { firstDone <- Elect(firstCtx, locker, election) }()
+
+	require.Eventually(t, func() bool { return leaders.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	go Elect(secondCtx, locker, election)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), leaders.Load(), "more than one leader")
+
+	stopFirst()
+	require.NoError(t, <-firstDone)
+
+	assert.Eventually(t, func() bool {
+		return revoked.Load() == 1 && leaders.Load() == 1
+	}, time.Second, 5*time.Millisecond, "leadership not transferred")
+}
+
+func TestAcquire(t *testing.T) {
+	locker, _ := newRedis(t, time.Minute)
+
+	l, err := locker.TryLock(context.Background(), "key")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = Acquire(ctx, locker, "key", 10*time.Millisecond)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "lock acquired while held")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		l.Unlock(context.Background())
+	}()
+
+	l, err = Acquire(context.Background(), locker, "key", 10*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, l.Unlock(context.Background()))
+}
@@ -0,0 +1,127 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Postgres сессионные advisory блокировки Postgres. Блокировка удерживается
+// на отдельном соединении из пула и снимается сервером при разрыве соединения,
+// поэтому соединение периодически проверяется. Номера захвата не поддерживаются.
+type Postgres struct {
+	db       *sql.DB
+	interval time.Duration
+}
+
+// NewPostgres создает блокировки, проверяющие соединение с интервалом interval.
+func NewPostgres(db *sql.DB, interval time.Duration) *Postgres {
+	return &Postgres{
+		db:       db,
+		interval: interval,
+	}
+}
+
+func (p *Postgres) TryLock(ctx context.Context, key string) (Lock, error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	if err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if !ok {
+		conn.Close()
+		return nil, ErrNotAcquired
+	}
+
+	l := &postgresLock{
+		conn:     conn,
+		key:      key,
+		interval: p.interval,
+		lost:     make(chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go l.watch()
+
+	return l, nil
+}
+
+type postgresLock struct {
+	conn     *sql.Conn
+	key      string
+	interval time.Duration
+
+	lost     chan struct{}
+	lostOnce sync.Once
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func (l *postgresLock) Key() string {
+	return l.key
+}
+
+func (l *postgresLock) Token() uint64 {
+	return 0
+}
+
+func (l *postgresLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *postgresLock) Unlock(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.done
+
+	defer l.conn.Close()
+
+	select {
+	case <-l.lost:
+		return ErrLost
+	default:
+	}
+
+	var ok bool
+	if err := l.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", l.key).Scan(&ok); err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrLost
+	}
+
+	return nil
+}
+
+// watch проверяет соединение, на котором удерживается блокировка.
+func (l *postgresLock) watch() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.interval)
+			err := l.conn.PingContext(ctx)
+			cancel()
+
+			if err != nil {
+				l.lostOnce.Do(func() { close(l.lost) })
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// newPostgres подключается к базе из X_TEST_POSTGRES_DSN. Если переменная не задана,
+// тест пропускается.
+func newPostgres(t *testing.T) *sql.DB {
+	dsn := os.Getenv("X_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("X_TEST_POSTGRES_DSN is not set")
+	}
+
+	db := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(dsn)))
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestPostgres_TryLock(t *testing.T) {
+	db := newPostgres(t)
+	first, second := NewPostgres(db, time.Second), NewPostgres(db, time.Second)
+	ctx := context.Background()
+
+	l, err := first.TryLock(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "key", l.Key())
+	assert.Zero(t, l.Token(), "tokens are not supported")
+
+	// Блокировка удерживается соединением, а не экземпляром Postgres
+	_, err = second.TryLock(ctx, "key")
+	assert.ErrorIs(t, err, ErrNotAcquired, "lock acquired twice")
+
+	other, err := second.TryLock(ctx, "other")
+	require.NoError(t, err)
+	require.NoError(t, other.Unlock(ctx))
+
+	require.NoError(t, l.Unlock(ctx))
+
+	l, err = second.TryLock(ctx, "key")
+	require.NoError(t, err)
+	require.NoError(t, l.Unlock(ctx))
+}
+
+func TestPostgres_lost(t *testing.T) {
+	db := newPostgres(t)
+	locker := NewPostgres(db, 10*time.Millisecond)
+	ctx := context.Background()
+
+	l, err := locker.TryLock(ctx, "key")
+	require.NoError(t, err)
+
+	// Разрыв соединения снимает блокировку на сервере
+	_, err = db.ExecContext(ctx, `SELECT pg_terminate_backend(pid) FROM pg_locks
+		WHERE locktype = 'advisory' AND objid = hashtext('key')::oid`)
+	require.NoError(t, err)
+
+	select {
+	case <-l.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lock not lost")
+	}
+
+	assert.ErrorIs(t, l.Unlock(ctx), ErrLost, "errors not equal")
+
+	l, err = locker.TryLock(ctx, "key")
+	require.NoError(t, err, "lock not released by server")
+	require.NoError(t, l.Unlock(ctx))
+}
@@ -0,0 +1,178 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Префиксы ключей Redis.
+const (
+	redisLockPrefix  = "lock:"
+	redisFencePrefix = "lock-fence:"
+)
+
+// redisKeys возвращает ключи блокировки и счетчика номеров захвата. Имя блокировки
+// заключается в hash tag, чтобы в Redis Cluster оба ключа скрипта попали в один слот.
+func redisKeys(key string) (string, string) {
+	tag := "{" + key + "}"
+
+	return redisLockPrefix + tag, redisFencePrefix + tag
+}
+
+// acquireScript захватывает блокировку и выдает следующий номер захвата.
+var acquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// extendScript продлевает блокировку, только если она принадлежит владельцу.
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript удаляет блокировку, только если она принадлежит владельцу.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Redis блокировки на ключах Redis с ограниченным сроком аренды. Пока блокировка
+// не освобождена, аренда продлевается каждую треть срока. Если продлить аренду
+// не удалось, блокировка считается потерянной.
+type Redis struct {
+	rdb redis.Cmdable
+	ttl time.Duration
+}
+
+func NewRedis(rdb redis.Cmdable, ttl time.Duration) *Redis {
+	return &Redis{
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
+
+func (r *Redis) TryLock(ctx context.Context, key string) (Lock, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	owner := hex.EncodeToString(buf)
+	lockKey, fenceKey := redisKeys(key)
+
+	token, err := acquireScript.Run(ctx, r.rdb, []string{lockKey, fenceKey}, owner, r.ttl.Milliseconds()).Uint64()
+	if err != nil {
+		return nil, err
+	}
+
+	if token == 0 {
+		return nil, ErrNotAcquired
+	}
+
+	l := &redisLock{
+		rdb:     r.rdb,
+		ttl:     r.ttl,
+		key:     key,
+		lockKey: lockKey,
+		owner:   owner,
+		token:   token,
+		lost:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go l.keepalive()
+
+	return l, nil
+}
+
+type redisLock struct {
+	rdb     redis.Cmdable
+	ttl     time.Duration
+	key     string
+	lockKey string
+	owner   string
+	token   uint64
+
+	lost     chan struct{}
+	lostOnce sync.Once
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func (l *redisLock) Key() string {
+	return l.key
+}
+
+func (l *redisLock) Token() uint64 {
+	return l.token
+}
+
+func (l *redisLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *redisLock) Unlock(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.done
+
+	n, err := releaseScript.Run(ctx, l.rdb, []string{l.lockKey}, l.owner).Int()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		l.markLost()
+		return ErrLost
+	}
+
+	return nil
+}
+
+// keepalive продлевает аренду до освобождения или потери блокировки.
+func (l *redisLock) keepalive() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	expires := time.Now().Add(l.ttl)
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithDeadline(context.Background(), expires)
+			n, err := extendScript.Run(ctx, l.rdb, []string{l.lockKey}, l.owner, l.ttl.Milliseconds()).Int()
+			cancel()
+
+			switch {
+			case err == nil && n == 1:
+				expires = time.Now().Add(l.ttl)
+			case err == nil || errors.Is(err, context.DeadlineExceeded) || time.Now().After(expires):
+				// Ключ принадлежит другому владельцу или аренда истекла
+				l.markLost()
+				return
+			}
+		}
+	}
+}
+
+func (l *redisLock) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
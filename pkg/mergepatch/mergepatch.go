@@ -0,0 +1,28 @@
+package mergepatch
+
+// Apply применяет JSON Merge Patch (RFC 7396) к документу и возвращает результат.
+// Значение nil в патче удаляет ключ, вложенные объекты объединяются рекурсивно.
+// Исходный документ не изменяется.
+func Apply(target, patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(target)+len(patch))
+	for key, val := range target {
+		result[key] = val
+	}
+
+	for key, val := range patch {
+		if val == nil {
+			delete(result, key)
+			continue
+		}
+
+		if patchObj, ok := val.(map[string]interface{}); ok {
+			targetObj, _ := result[key].(map[string]interface{})
+			result[key] = Apply(targetObj, patchObj)
+			continue
+		}
+
+		result[key] = val
+	}
+
+	return result
+}
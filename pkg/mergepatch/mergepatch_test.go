@@ -0,0 +1,31 @@
+package mergepatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	target := map[string]interface{}{"a": "b", "c": map[string]interface{}{"d": "e", "f": "g"}}
+	patch := map[string]interface{}{"a": "z", "c": map[string]interface{}{"f": nil}}
+
+	result := Apply(target, patch)
+	assert.Equal(t, map[string]interface{}{"a": "z", "c": map[string]interface{}{"d": "e"}}, result)
+	assert.Equal(t, "b", target["a"], "target must not be modified")
+}
+
+func TestApply_delete(t *testing.T) {
+	result := Apply(map[string]interface{}{"a": "b", "b": "c"}, map[string]interface{}{"a": nil})
+	assert.Equal(t, map[string]interface{}{"b": "c"}, result)
+}
+
+func TestApply_replaceScalar(t *testing.T) {
+	result := Apply(map[string]interface{}{"a": "c"}, map[string]interface{}{"a": map[string]interface{}{"b": "c"}})
+	assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{"b": "c"}}, result)
+}
+
+func TestApply_emptyTarget(t *testing.T) {
+	result := Apply(nil, map[string]interface{}{"a": map[string]interface{}{"bb": map[string]interface{}{"ccc": nil}}})
+	assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{"bb": map[string]interface{}{}}}, result)
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/uptrace/bun"
+)
+
+// QueryHook метрики запросов bun.
+type QueryHook struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+var _ bun.QueryHook = (*QueryHook)(nil)
+
+func NewQueryHook(reg prometheus.Registerer) *QueryHook {
+	h := &QueryHook{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Database query duration by operation.",
+			Buckets: storageBuckets,
+		}, []string{"operation"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_query_errors_total",
+			Help: "Number of failed database queries by operation.",
+		}, []string{"operation"}),
+	}
+
+	reg.MustRegister(h.duration, h.errors)
+
+	return h
+}
+
+func (h *QueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *QueryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	operation := event.Operation()
+
+	h.duration.WithLabelValues(operation).Observe(time.Since(event.StartTime).Seconds())
+
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		h.errors.WithLabelValues(operation).Inc()
+	}
+}
+
+// RegisterDBStats регистрирует метрики пула соединений sql.DB.
+func RegisterDBStats(reg prometheus.Registerer, db *sql.DB, name string) {
+	reg.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"service-template/pkg/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTP метрики HTTP-запросов.
+type HTTP struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewHTTP(reg prometheus.Registerer) *HTTP {
+	m := &HTTP{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests by route and status.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request duration by route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+
+	reg.MustRegister(m.requests, m.duration)
+
+	return m
+}
+
+// Middleware учитывает запросы. В метку route попадает шаблон маршрута,
+// а не путь запроса, чтобы число рядов не зависело от параметров.
+func (m *HTTP) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		status := problem.StatusCode(c, err)
+
+		labels := prometheus.Labels{
+			"method": c.Method(),
+			"route":  c.Route().Path,
+			"status": strconv.Itoa(status),
+		}
+
+		m.requests.With(labels).Inc()
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
@@ -0,0 +1,29 @@
+// Package metrics собирает метрики Prometheus HTTP-сервера, Postgres и Redis.
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Границы гистограмм длительности запросов к БД и Redis, в секундах.
+var storageBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+
+// Handler возвращает обработчик HTTP-запросов, отдающий метрики в формате Prometheus.
+func Handler(gatherer prometheus.Gatherer) fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
+
+// NewRegistry создает реестр метрик со сборщиками рантайма Go и процесса.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return reg
+}
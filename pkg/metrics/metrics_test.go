@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"service-template/pkg/problem"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_Middleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewHTTP(reg)
+
+	app := fiber.New()
+	app.Use(m.Middleware())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		switch c.Params("id") {
+		case "0":
+			return fiber.ErrNotFound
+		case "-1":
+			return problem.New(fiber.StatusConflict, "conflict", "conflict")
+		}
+
+		return c.SendString("ok")
+	})
+	app.Get("/metrics", Handler(reg))
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/0", "/users/-1"} {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/users/:id", "200")), "success requests")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/users/:id", "404")), "error requests")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/users/:id", "409")), "problem requests")
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestRedisHook(t *testing.T) {
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	ctx := context.Background()
+
+	reg := prometheus.NewRegistry()
+	hook := NewRedisHook(reg)
+	rdb.AddHook(hook)
+	RegisterRedisPool(reg, rdb)
+
+	require.NoError(t, rdb.Set(ctx, "key", "value", 0).Err())
+	assert.ErrorIs(t, rdb.Get(ctx, "missing").Err(), redis.Nil)
+	assert.Error(t, rdb.Incr(ctx, "key").Err())
+
+	assert.Equal(t, 1, testutil.CollectAndCount(hook.duration.WithLabelValues("set").(prometheus.Histogram)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(hook.errors.WithLabelValues("get")), "redis.Nil counted as error")
+	assert.Equal(t, 1.0, testutil.ToFloat64(hook.errors.WithLabelValues("incr")), "error not counted")
+	assert.Equal(t, 1.0, testutil.ToFloat64(hook.dials.WithLabelValues("success")), "dial not counted")
+
+	count, err := testutil.GatherAndCount(reg, "redis_pool_connections")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
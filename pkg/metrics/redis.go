@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook метрики команд go-redis.
+type RedisHook struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	dials    *prometheus.CounterVec
+}
+
+var _ redis.Hook = (*RedisHook)(nil)
+
+func NewRedisHook(reg prometheus.Registerer) *RedisHook {
+	h := &RedisHook{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "redis_command_duration_seconds",
+			Help:    "Redis command duration by command.",
+			Buckets: storageBuckets,
+		}, []string{"command"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_command_errors_total",
+			Help: "Number of failed Redis commands by command.",
+		}, []string{"command"}),
+		dials: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_dials_total",
+			Help: "Number of Redis connection attempts by result.",
+		}, []string{"result"}),
+	}
+
+	reg.MustRegister(h.duration, h.errors, h.dials)
+
+	return h
+}
+
+func (h *RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+
+		h.dials.WithLabelValues(result).Inc()
+
+		return conn, err
+	}
+}
+
+func (h *RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+
+		h.observe(cmd.Name(), start, err)
+
+		return err
+	}
+}
+
+func (h *RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+
+		h.observe("pipeline", start, err)
+
+		return err
+	}
+}
+
+func (h *RedisHook) observe(command string, start time.Time, err error) {
+	h.duration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+
+	// redis.Nil означает отсутствие ключа, а не ошибку выполнения
+	if err != nil && !errors.Is(err, redis.Nil) {
+		h.errors.WithLabelValues(command).Inc()
+	}
+}
+
+// PoolStatser клиент Redis с пулом соединений.
+type PoolStatser interface {
+	PoolStats() *redis.PoolStats
+}
+
+// RedisPool метрики пула соединений go-redis.
+type RedisPool struct {
+	client   PoolStatser
+	hits     *prometheus.Desc
+	misses   *prometheus.Desc
+	timeouts *prometheus.Desc
+	total    *prometheus.Desc
+	idle     *prometheus.Desc
+	stale    *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*RedisPool)(nil)
+
+// RegisterRedisPool регистрирует метрики пула соединений клиента Redis.
+func RegisterRedisPool(reg prometheus.Registerer, client PoolStatser) {
+	reg.MustRegister(NewRedisPool(client))
+}
+
+func NewRedisPool(client PoolStatser) *RedisPool {
+	return &RedisPool{
+		client:   client,
+		hits:     prometheus.NewDesc("redis_pool_hits_total", "Number of times a free connection was found in the pool.", nil, nil),
+		misses:   prometheus.NewDesc("redis_pool_misses_total", "Number of times a free connection was not found in the pool.", nil, nil),
+		timeouts: prometheus.NewDesc("redis_pool_timeouts_total", "Number of times a wait for a connection timed out.", nil, nil),
+		total:    prometheus.NewDesc("redis_pool_connections", "Number of connections in the pool.", nil, nil),
+		idle:     prometheus.NewDesc("redis_pool_idle_connections", "Number of idle connections in the pool.", nil, nil),
+		stale:    prometheus.NewDesc("redis_pool_stale_connections_total", "Number of stale connections removed from the pool.", nil, nil),
+	}
+}
+
+func (p *RedisPool) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.hits
+	ch <- p.misses
+	ch <- p.timeouts
+	ch <- p.total
+	ch <- p.idle
+	ch <- p.stale
+}
+
+func (p *RedisPool) Collect(ch chan<- prometheus.Metric) {
+	stats := p.client.PoolStats()
+
+	ch <- prometheus.MustNewConstMetric(p.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(p.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(p.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(p.total, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(p.idle, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(p.stale, prometheus.CounterValue, float64(stats.StaleConns))
+}
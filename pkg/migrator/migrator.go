@@ -1,14 +1,17 @@
 package migrator
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"strings"
 
 	"service-template/internal/config"
-	"service-template/pkg/drivers/postgres"
+	storage "service-template/internal/db"
 
 	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/migrate"
 	"github.com/urfave/cli/v2"
 )
@@ -24,7 +27,7 @@ func MigrateCommands() *cli.Command {
 		Flags: []cli.Flag{
 			&cli.PathFlag{
 				Name:     "migrations",
-				Usage:    "Load migrations from `DIR`",
+				Usage:    "Load migrations from `DIR`, subdirectories are ignored",
 				Aliases:  []string{"m"},
 				Required: true,
 				Action: func(c *cli.Context, path cli.Path) error {
@@ -38,7 +41,7 @@ func MigrateCommands() *cli.Command {
 					}
 
 					migrations = migrate.NewMigrations(migrate.WithMigrationsDirectory(c.String("migrations")))
-					return migrations.Discover(os.DirFS(path))
+					return migrations.Discover(flat{os.DirFS(path)})
 				},
 			},
 		},
@@ -47,7 +50,7 @@ func MigrateCommands() *cli.Command {
 				Name:  "init",
 				Usage: "create migration tables",
 				Action: func(c *cli.Context) error {
-					db, err := postgres.NewPostgresDB(cfg.Postgres)
+					db, err := connect(c.Context, cfg)
 					if err != nil {
 						return err
 					}
@@ -62,7 +65,7 @@ func MigrateCommands() *cli.Command {
 				Name:  "migrate",
 				Usage: "migrate database",
 				Action: func(c *cli.Context) error {
-					db, err := postgres.NewPostgresDB(cfg.Postgres)
+					db, err := connect(c.Context, cfg)
 					if err != nil {
 						return err
 					}
@@ -90,7 +93,7 @@ func MigrateCommands() *cli.Command {
 				Name:  "rollback",
 				Usage: "rollback the last migration group",
 				Action: func(c *cli.Context) error {
-					db, err := postgres.NewPostgresDB(cfg.Postgres)
+					db, err := connect(c.Context, cfg)
 					if err != nil {
 						return err
 					}
@@ -118,7 +121,7 @@ func MigrateCommands() *cli.Command {
 				Name:  "lock",
 				Usage: "lock migrations",
 				Action: func(c *cli.Context) error {
-					db, err := postgres.NewPostgresDB(cfg.Postgres)
+					db, err := connect(c.Context, cfg)
 					if err != nil {
 						return err
 					}
@@ -133,7 +136,7 @@ func MigrateCommands() *cli.Command {
 				Name:  "unlock",
 				Usage: "unlock migrations",
 				Action: func(c *cli.Context) error {
-					db, err := postgres.NewPostgresDB(cfg.Postgres)
+					db, err := connect(c.Context, cfg)
 					if err != nil {
 						return err
 					}
@@ -148,7 +151,7 @@ func MigrateCommands() *cli.Command {
 				Name:  "create_go",
 				Usage: "create Go migration",
 				Action: func(c *cli.Context) error {
-					db, err := postgres.NewPostgresDB(cfg.Postgres)
+					db, err := connect(c.Context, cfg)
 					if err != nil {
 						return err
 					}
@@ -172,7 +175,7 @@ func MigrateCommands() *cli.Command {
 				Name:  "create_sql",
 				Usage: "create up and down SQL migrations",
 				Action: func(c *cli.Context) error {
-					db, err := postgres.NewPostgresDB(cfg.Postgres)
+					db, err := connect(c.Context, cfg)
 					if err != nil {
 						return err
 					}
@@ -197,7 +200,7 @@ func MigrateCommands() *cli.Command {
 				Name:  "status",
 				Usage: "print migrations status",
 				Action: func(c *cli.Context) error {
-					db, err := postgres.NewPostgresDB(cfg.Postgres)
+					db, err := connect(c.Context, cfg)
 					if err != nil {
 						return err
 					}
@@ -221,7 +224,7 @@ func MigrateCommands() *cli.Command {
 				Name:  "mark_applied",
 				Usage: "mark migrations as applied without actually running them",
 				Action: func(c *cli.Context) error {
-					db, err := postgres.NewPostgresDB(cfg.Postgres)
+					db, err := connect(c.Context, cfg)
 					if err != nil {
 						return err
 					}
@@ -248,3 +251,30 @@ func MigrateCommands() *cli.Command {
 		},
 	}
 }
+
+// connect подключается к SQL БД из конфигурации.
+func connect(ctx context.Context, cfg *config.Config) (*bun.DB, error) {
+	return storage.Open(ctx, cfg, &log.Logger)
+}
+
+// flat скрывает вложенные каталоги, в которых лежат миграции для других СУБД,
+// например migrations/sqlite.
+type flat struct {
+	fs.FS
+}
+
+func (f flat) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(f.FS, name)
+	if err != nil {
+		return nil, err
+	}
+
+	files := entries[:0]
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, entry)
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,53 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"service-template/pkg/drivers/sqlite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun/migrate"
+)
+
+var testMigrations = fstest.MapFS{
+	"20260101000000_items.up.sql":          {Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY)")},
+	"20260101000000_items.down.sql":        {Data: []byte("DROP TABLE items")},
+	"sqlite/20260101000000_items.up.sql":   {Data: []byte("SELECT broken")},
+	"sqlite/20260101000000_items.down.sql": {Data: []byte("SELECT broken")},
+}
+
+func TestApply(t *testing.T) {
+	cfg := sqlite.NewConfig()
+	cfg.Path = sqlite.Memory
+
+	db, err := sqlite.NewSQLiteDB(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	fsys := flat{testMigrations}
+
+	group, err := Apply(ctx, db, fsys)
+	require.NoError(t, err)
+	assert.Len(t, group.Migrations, 1)
+
+	ms, err := Unapplied(ctx, db, fsys)
+	require.NoError(t, err)
+	assert.Empty(t, ms)
+
+	exists, err := db.NewSelect().Table("items").Exists(ctx)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestFlat(t *testing.T) {
+	migrations := migrate.NewMigrations()
+	require.NoError(t, migrations.Discover(flat{testMigrations}))
+
+	sorted := migrations.Sorted()
+	require.Len(t, sorted, 1)
+	assert.Equal(t, "20260101000000", sorted[0].Name)
+}
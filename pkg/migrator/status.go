@@ -0,0 +1,45 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+// Unapplied возвращает миграции из fsys, которые еще не применены к БД.
+func Unapplied(ctx context.Context, db *bun.DB, fsys fs.FS) (migrate.MigrationSlice, error) {
+	migrations := migrate.NewMigrations()
+	if err := migrations.Discover(fsys); err != nil {
+		return nil, fmt.Errorf("discover migrations: %w", err)
+	}
+
+	ms, err := migrate.NewMigrator(db, migrations).MigrationsWithStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrations status: %w", err)
+	}
+
+	return ms.Unapplied(), nil
+}
+
+// Apply создает таблицы миграций, если их нет, и применяет миграции из fsys.
+func Apply(ctx context.Context, db *bun.DB, fsys fs.FS) (*migrate.MigrationGroup, error) {
+	migrations := migrate.NewMigrations()
+	if err := migrations.Discover(fsys); err != nil {
+		return nil, fmt.Errorf("discover migrations: %w", err)
+	}
+
+	migrator := migrate.NewMigrator(db, migrations)
+	if err := migrator.Init(ctx); err != nil {
+		return nil, fmt.Errorf("init migrations: %w", err)
+	}
+
+	group, err := migrator.Migrate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	return group, nil
+}
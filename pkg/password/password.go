@@ -0,0 +1,142 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrMismatch      = errors.New("password mismatch")
+	ErrUnknownFormat = errors.New("unknown password hash format")
+)
+
+// Hash хеширует пароль алгоритмом bcrypt.
+func Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes), nil
+}
+
+// IsHash проверяет, что строка является хешем поддерживаемого формата (bcrypt или argon2id).
+func IsHash(hash string) bool {
+	switch {
+	case isBcrypt(hash):
+		_, err := bcrypt.Cost([]byte(hash))
+		return err == nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		_, _, _, err := decodeArgon2(hash)
+		return err == nil
+	}
+
+	return false
+}
+
+// Compare сравнивает пароль с хешем bcrypt или argon2id.
+func Compare(hash, password string) error {
+	switch {
+	case isBcrypt(hash):
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrMismatch
+			}
+
+			return err
+		}
+
+		return nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return compareArgon2(hash, password)
+	}
+
+	return ErrUnknownFormat
+}
+
+// HashArgon2 хеширует пароль алгоритмом argon2id в формате PHC.
+func HashArgon2(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := argon2Params{memory: 64 * 1024, time: 3, threads: 2}
+	key := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, 32)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Допустимые параметры хеша argon2id. Хеши импортируются извне, поэтому параметры
+// ограничиваются: нулевые вызывают панику argon2, а слишком большие исчерпывают память.
+const (
+	argon2MaxMemory = 1024 * 1024 // 1 ГиБ в КиБ
+	argon2MinSalt   = 8
+	argon2MinKey    = 16
+)
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func compareArgon2(hash, password string) error {
+	p, salt, key, err := decodeArgon2(hash)
+	if err != nil {
+		return err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+// decodeArgon2 разбирает хеш вида $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+func decodeArgon2(hash string) (p argon2Params, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	if p.time < 1 || p.threads < 1 || p.memory < 1 || p.memory > argon2MaxMemory {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil || len(salt) < argon2MinSalt {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) < argon2MinKey {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,52 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash_bcrypt(t *testing.T) {
+	hash, err := Hash("secret")
+	assert.NoError(t, err)
+	assert.True(t, IsHash(hash), "bcrypt hash not recognized")
+	assert.NoError(t, Compare(hash, "secret"))
+	assert.ErrorIs(t, Compare(hash, "wrong"), ErrMismatch)
+}
+
+func TestHash_argon2(t *testing.T) {
+	hash, err := HashArgon2("secret")
+	assert.NoError(t, err)
+	assert.True(t, IsHash(hash), "argon2 hash not recognized")
+	assert.NoError(t, Compare(hash, "secret"))
+	assert.ErrorIs(t, Compare(hash, "wrong"), ErrMismatch)
+}
+
+func TestIsHash_invalid(t *testing.T) {
+	for _, hash := range []string{"", "secret", "$argon2id$v=19$m=1", "$2a$invalid"} {
+		assert.False(t, IsHash(hash), hash)
+	}
+
+	assert.ErrorIs(t, Compare("secret", "secret"), ErrUnknownFormat)
+}
+
+func TestIsHash_argon2Params(t *testing.T) {
+	const (
+		salt = "c29tZXNhbHRzb21lc2FsdA"
+		key  = "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	)
+
+	assert.True(t, IsHash("$argon2id$v=19$m=65536,t=3,p=2$"+salt+"$"+key))
+
+	for _, hash := range []string{
+		"$argon2id$v=19$m=65536,t=0,p=1$" + salt + "$" + key,
+		"$argon2id$v=19$m=65536,t=3,p=0$" + salt + "$" + key,
+		"$argon2id$v=19$m=0,t=3,p=1$" + salt + "$" + key,
+		"$argon2id$v=19$m=4294967295,t=3,p=1$" + salt + "$" + key,
+		"$argon2id$v=19$m=65536,t=3,p=1$$" + key,
+		"$argon2id$v=19$m=65536,t=3,p=1$" + salt + "$a2V5",
+	} {
+		assert.False(t, IsHash(hash), hash)
+		assert.ErrorIs(t, Compare(hash, "secret"), ErrUnknownFormat, hash)
+	}
+}
@@ -0,0 +1,89 @@
+package problem
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/rs/zerolog"
+)
+
+// Problem тело ответа с ошибкой (RFC 7807).
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      string            `json:"code"`
+	RequestID string            `json:"request_id,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// Handler возвращает обработчик ошибок fiber, отдающий их в формате problem+json.
+// Внутренние ошибки логируются, а клиент получает только код internal_error.
+func Handler(log *zerolog.Logger) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		p := From(err)
+		p.Instance = c.OriginalURL()
+		p.RequestID = c.GetRespHeader(fiber.HeaderXRequestID)
+
+		if p.Status >= fiber.StatusInternalServerError {
+			log.Error().Err(err).
+				Str("request_id", p.RequestID).
+				Str("method", c.Method()).
+				Str("url", p.Instance).
+				Msg("request failed")
+		}
+
+		if err := c.Status(p.Status).JSON(p); err != nil {
+			return err
+		}
+
+		// JSON выставляет application/json, поэтому тип заменяется после записи тела
+		c.Set(fiber.HeaderContentType, MIMEApplicationProblemJSON)
+
+		return nil
+	}
+}
+
+// StatusCode возвращает код ответа на запрос. Для middleware, получившего ошибку от c.Next(),
+// код ответа еще не выставлен, поэтому он определяется так же, как в Handler.
+func StatusCode(c *fiber.Ctx, err error) int {
+	if err != nil {
+		return From(err).Status
+	}
+
+	return c.Response().StatusCode()
+}
+
+// From строит тело ответа для ошибки err.
+func From(err error) Problem {
+	var e *Error
+	var fe *fiber.Error
+
+	switch {
+	case errors.As(err, &e):
+	case errors.As(err, &fe):
+		e = New(fe.Code, code(fe.Code), fe.Message)
+	default:
+		e = ErrInternal
+	}
+
+	// Подробности внутренних ошибок не показываются клиенту
+	if e.Status >= fiber.StatusInternalServerError {
+		e = New(e.Status, e.Code, "")
+		if e.Code == "" || e.Status == fiber.StatusInternalServerError {
+			e.Code = ErrInternal.Code
+		}
+	}
+
+	return Problem{
+		Type:   "about:blank",
+		Title:  utils.StatusMessage(e.Status),
+		Status: e.Status,
+		Detail: e.Error(),
+		Code:   e.Code,
+		Errors: e.Fields,
+	}
+}
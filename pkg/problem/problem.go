@@ -0,0 +1,128 @@
+// Package problem описывает ошибки с машиночитаемым кодом и HTTP-статусом
+// и отдает их клиенту в формате application/problem+json (RFC 7807).
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// MIMEApplicationProblemJSON тип содержимого ответа с ошибкой.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Общие ошибки, не относящиеся к конкретному сервису.
+var (
+	ErrMalformedBody = New(http.StatusBadRequest, "malformed_body", "malformed request body")
+	ErrValidation    = New(http.StatusBadRequest, "validation_failed", "validation failed")
+	ErrInternal      = New(http.StatusInternalServerError, "internal_error", "internal server error")
+)
+
+// Error ошибка с машиночитаемым кодом и HTTP-статусом. Ошибки сравниваются
+// по коду, поэтому копии с деталями остаются равны исходной ошибке для errors.Is.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Detail  string
+	// Fields ошибки проверки полей запроса.
+	Fields map[string]string
+}
+
+// New создает ошибку с кодом code, который не должен меняться между версиями API.
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return e.Message + ": " + e.Detail
+	}
+
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetail возвращает копию ошибки с описанием конкретного случая.
+func (e *Error) WithDetail(detail string) *Error {
+	c := *e
+	c.Detail = detail
+
+	return &c
+}
+
+// WithStatus возвращает копию ошибки с другим HTTP-статусом и тем же кодом.
+func (e *Error) WithStatus(status int) *Error {
+	c := *e
+	c.Status = status
+
+	return &c
+}
+
+// WithFields возвращает копию ошибки с ошибками полей из validation.Errors.
+// Для других ошибок в описание попадает текст err.
+func (e *Error) WithFields(err error) *Error {
+	c := *e
+
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		c.Fields = make(map[string]string)
+		flatten(c.Fields, "", errs)
+	} else {
+		c.Detail = err.Error()
+	}
+
+	return &c
+}
+
+// Invalid преобразует ошибку проверки запроса в ErrValidation. Внутренние ошибки
+// правил проверки возвращаются без изменений.
+func Invalid(err error) error {
+	var internal validation.InternalError
+	if errors.As(err, &internal) {
+		return err
+	}
+
+	return ErrValidation.WithFields(err)
+}
+
+// Malformed преобразует ошибку разбора тела запроса в ErrMalformedBody.
+func Malformed(err error) error {
+	return ErrMalformedBody.WithDetail(err.Error())
+}
+
+// flatten раскладывает вложенные ошибки полей в плоский список с ключами вида parent.child.
+func flatten(fields map[string]string, prefix string, errs validation.Errors) {
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		var nested validation.Errors
+		if errors.As(errs[key], &nested) {
+			flatten(fields, name, nested)
+			continue
+		}
+
+		fields[name] = errs[key].Error()
+	}
+}
+
+// code возвращает код ошибки для HTTP-статуса, например, not_found для 404.
+func code(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
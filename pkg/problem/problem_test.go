@@ -0,0 +1,98 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotFound = New(fiber.StatusNotFound, "user_not_found", "user not found")
+
+func TestError_Is(t *testing.T) {
+	err := fmt.Errorf("user get: %w", errNotFound.WithDetail("id 1"))
+
+	assert.ErrorIs(t, err, errNotFound, "copy with detail is not equal")
+	assert.NotErrorIs(t, err, ErrValidation)
+	assert.Equal(t, "user get: user not found: id 1", err.Error())
+}
+
+func TestInvalid(t *testing.T) {
+	err := validation.Errors{
+		"email": errors.New("must be a valid email address"),
+		"profile": validation.Errors{
+			"name": errors.New("cannot be blank"),
+		},
+	}
+
+	var e *Error
+	require.ErrorAs(t, Invalid(err), &e)
+	assert.Equal(t, ErrValidation.Code, e.Code)
+	assert.Equal(t, map[string]string{
+		"email":        "must be a valid email address",
+		"profile.name": "cannot be blank",
+	}, e.Fields)
+
+	internal := validation.NewInternalError(errors.New("rule failed"))
+	assert.Equal(t, internal, Invalid(internal), "internal error converted")
+}
+
+func TestHandler(t *testing.T) {
+	log := zerolog.Nop()
+
+	app := fiber.New(fiber.Config{ErrorHandler: Handler(&log)})
+	app.Use(requestid.New())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		return fmt.Errorf("user get: %w", errNotFound)
+	})
+	app.Get("/internal", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+	app.Post("/users", func(c *fiber.Ctx) error {
+		return Invalid(validation.Errors{"email": errors.New("cannot be blank")})
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		code   string
+		detail string
+		errors map[string]string
+	}{
+		{"domain", fiber.MethodGet, "/users/1", fiber.StatusNotFound, "user_not_found", "user not found", nil},
+		{"internal", fiber.MethodGet, "/internal", fiber.StatusInternalServerError, "internal_error", "", nil},
+		{"validation", fiber.MethodPost, "/users", fiber.StatusBadRequest, "validation_failed", "validation failed", map[string]string{"email": "cannot be blank"}},
+		{"fiber", fiber.MethodGet, "/missing", fiber.StatusNotFound, "not_found", "Cannot GET /missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, MIMEApplicationProblemJSON, resp.Header.Get(fiber.HeaderContentType))
+
+			var p Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.code, p.Code)
+			assert.Equal(t, tt.detail, p.Detail)
+			assert.Equal(t, tt.errors, p.Errors)
+			assert.Equal(t, tt.path, p.Instance)
+			assert.Equal(t, resp.Header.Get(fiber.HeaderXRequestID), p.RequestID)
+			assert.NotEmpty(t, p.RequestID)
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"service-template/pkg/runner"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// FieldData поле сообщения, в котором хранятся данные в формате JSON.
+const FieldData = "data"
+
+// DefaultDeadLetterSuffix суффикс имени потока недоставленных сообщений.
+const DefaultDeadLetterSuffix = ":dead"
+
+// Поля, добавляемые к сообщению при переносе в очередь недоставленных сообщений.
+const (
+	FieldDeadStream = "dead_stream"
+	FieldDeadID     = "dead_id"
+	FieldDeadReason = "dead_reason"
+)
+
+var ErrNoHandlers = errors.New("queue: no handlers registered")
+
+// Message сообщение из Redis Stream.
+type Message struct {
+	ID     string
+	Stream string
+	Values map[string]interface{}
+	// Attempt номер попытки обработки, начиная с 1.
+	Attempt int
+}
+
+// Handler обрабатывает сообщение. Если обработчик вернул ошибку,
+// сообщение будет обработано повторно после задержки.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Config параметры потребителя.
+type Config struct {
+	// Group имя группы потребителей.
+	Group string
+	// Consumer имя потребителя в группе, должно быть уникальным для экземпляра сервиса.
+	Consumer string
+	// Concurrency максимальное количество одновременно обрабатываемых сообщений.
+	Concurrency int
+	// MaxRetries количество повторов, после которого сообщение переносится в DeadLetter поток.
+	MaxRetries int
+	// Backoff начальная задержка повторной обработки, удваивается с каждой попыткой.
+	Backoff time.Duration
+	// MaxBackoff максимальная задержка повторной обработки.
+	MaxBackoff time.Duration
+	// ClaimInterval периодичность проверки зависших сообщений.
+	ClaimInterval time.Duration
+	// ClaimTimeout время, после которого неподтвержденное сообщение считается брошенным
+	// и может быть взято другим потребителем. Должно заметно превышать время обработки
+	// сообщения, иначе сообщение, которое еще обрабатывается, будет обработано повторно.
+	ClaimTimeout time.Duration
+	// Block время ожидания новых сообщений.
+	Block time.Duration
+	// DeadLetterSuffix суффикс имени потока недоставленных сообщений.
+	DeadLetterSuffix string
+}
+
+// Consumer читает сообщения из потоков Redis в составе группы потребителей
+// и передает их зарегистрированным обработчикам. В Redis Cluster все потоки
+// потребителя читаются одной командой, поэтому их имена должны содержать общий
+// hash tag, например {queue}:events.
+type Consumer struct {
+	rdb      redis.UniversalClient
+	cfg      Config
+	log      *zerolog.Logger
+	handlers map[string]Handler
+	streams  []string
+	runner   *runner.Runner
+}
+
+func NewConsumer(rdb redis.UniversalClient, cfg Config, log *zerolog.Logger) *Consumer {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	if cfg.ClaimTimeout <= 0 {
+		cfg.ClaimTimeout = time.Minute
+	}
+
+	if cfg.DeadLetterSuffix == "" {
+		cfg.DeadLetterSuffix = DefaultDeadLetterSuffix
+	}
+
+	return &Consumer{
+		rdb:      rdb,
+		cfg:      cfg,
+		log:      log,
+		handlers: make(map[string]Handler),
+		runner:   runner.New(cfg.Concurrency),
+	}
+}
+
+// Handle регистрирует обработчик сообщений потока. Вызывается до Run.
+func (c *Consumer) Handle(stream string, handler Handler) {
+	if _, ok := c.handlers[stream]; !ok {
+		c.streams = append(c.streams, stream)
+	}
+
+	c.handlers[stream] = handler
+}
+
+// HandleJSON регистрирует обработчик, получающий данные сообщения, декодированные из JSON.
+// Сообщения, которые не удалось декодировать, сразу переносятся в DeadLetter поток.
+func HandleJSON[T any](c *Consumer, stream string, fn func(ctx context.Context, value T) error) {
+	c.Handle(stream, func(ctx context.Context, msg *Message) error {
+		var value T
+
+		data, _ := msg.Values[FieldData].(string)
+		if err := json.Unmarshal([]byte(data), &value); err != nil {
+			return Permanent(fmt.Errorf("decode %s: %w", msg.ID, err))
+		}
+
+		return fn(ctx, value)
+	})
+}
+
+// Publish добавляет сообщение с данными в формате JSON в поток.
+func Publish(ctx context.Context, rdb redis.UniversalClient, stream string, value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		Values: map[string]interface{}{FieldData: string(data)},
+	}).Result()
+}
+
+// Run создает группы потребителей и обрабатывает сообщения до вызова Close или отмены контекста.
+func (c *Consumer) Run(ctx context.Context) error {
+	ctx, finish := c.runner.Start(ctx)
+	defer finish()
+
+	if len(c.streams) == 0 {
+		return ErrNoHandlers
+	}
+
+	for _, stream := range c.streams {
+		err := c.rdb.XGroupCreateMkStream(ctx, stream, c.cfg.Group, "0").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("create group %s for %s: %w", c.cfg.Group, stream, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		c.claimLoop(ctx)
+	}()
+
+	c.readLoop(ctx)
+	wg.Wait()
+
+	return nil
+}
+
+// Close прекращает чтение новых сообщений и ожидает завершения обрабатываемых,
+// но не дольше, чем до отмены ctx.
+func (c *Consumer) Close(ctx context.Context) error {
+	if err := c.runner.Close(ctx); err != nil {
+		return fmt.Errorf("queue drain: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Consumer) readLoop(ctx context.Context) {
+	args := make([]string, 0, len(c.streams)*2)
+	args = append(args, c.streams...)
+	for range c.streams {
+		args = append(args, ">")
+	}
+
+	for ctx.Err() == nil {
+		// Ждем хотя бы один свободный слот и читаем не больше свободных слотов
+		free := c.runner.Free(ctx)
+		if free == 0 {
+			return
+		}
+
+		res, err := c.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.cfg.Group,
+			Consumer: c.cfg.Consumer,
+			Streams:  args,
+			Count:    int64(free),
+			Block:    c.cfg.Block,
+		}).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+				c.log.Error().Err(err).Msg("queue read")
+				runner.Sleep(ctx, c.cfg.Backoff)
+			}
+
+			continue
+		}
+
+		for _, stream := range res {
+			for _, msg := range stream.Messages {
+				c.dispatch(&Message{ID: msg.ID, Stream: stream.Stream, Values: msg.Values, Attempt: 1})
+			}
+		}
+	}
+}
+
+// dispatch запускает обработку сообщения, дожидаясь свободного слота.
+func (c *Consumer) dispatch(msg *Message) {
+	c.runner.Go(func() {
+		c.process(msg)
+	})
+}
+
+func (c *Consumer) process(msg *Message) {
+	log := c.log.With().Str("stream", msg.Stream).Str("id", msg.ID).Int("attempt", msg.Attempt).Logger()
+
+	// Обработка не прерывается при остановке потребителя, чтобы дать ей завершиться
+	ctx := context.Background()
+
+	err := c.call(ctx, msg)
+	if err == nil {
+		if err = c.rdb.XAck(ctx, msg.Stream, c.cfg.Group, msg.ID).Err(); err != nil {
+			log.Error().Err(err).Msg("queue ack")
+		}
+
+		return
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || msg.Attempt > c.cfg.MaxRetries {
+		log.Error().Err(err).Msg("queue message moved to dead letter")
+		c.deadLetter(ctx, msg, err.Error())
+
+		return
+	}
+
+	// Сообщение остается в списке ожидающих и будет взято повторно после задержки
+	log.Warn().Err(err).Msg("queue message failed")
+}
+
+func (c *Consumer) call(ctx context.Context, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return c.handlers[msg.Stream](ctx, msg)
+}
+
+// claimLoop периодически забирает сообщения, обработка которых завершилась ошибкой
+// или которые были получены упавшими потребителями, и обрабатывает их повторно.
+func (c *Consumer) claimLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.ClaimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, stream := range c.streams {
+				if err := c.claim(ctx, stream); err != nil && ctx.Err() == nil {
+					c.log.Error().Err(err).Str("stream", stream).Msg("queue claim")
+				}
+			}
+		}
+	}
+}
+
+func (c *Consumer) claim(ctx context.Context, stream string) error {
+	// Сообщения, задержка которых еще не прошла, пропускаются, поэтому PEL просматривается
+	// страницами, пока не наберется Concurrency сообщений или записи не закончатся
+	count := int64(c.cfg.Concurrency)
+	start := "-"
+	claimed := 0
+
+	for claimed < c.cfg.Concurrency {
+		pending, err := c.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: stream,
+			Group:  c.cfg.Group,
+			Idle:   c.cfg.ClaimTimeout + c.cfg.Backoff,
+			Start:  start,
+			End:    "+",
+			Count:  count,
+		}).Result()
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range pending {
+			if claimed == c.cfg.Concurrency {
+				break
+			}
+
+			// Задержка повторной обработки растет с количеством доставок и отсчитывается
+			// после ClaimTimeout, чтобы не забрать сообщение, которое еще обрабатывается
+			minIdle := c.cfg.ClaimTimeout + runner.Backoff(c.cfg.Backoff, c.cfg.MaxBackoff, int(entry.RetryCount))
+			if entry.Idle < minIdle {
+				continue
+			}
+
+			msgs, err := c.rdb.XClaim(ctx, &redis.XClaimArgs{
+				Stream:   stream,
+				Group:    c.cfg.Group,
+				Consumer: c.cfg.Consumer,
+				MinIdle:  minIdle,
+				Messages: []string{entry.ID},
+			}).Result()
+			if err != nil {
+				return err
+			}
+
+			for _, msg := range msgs {
+				m := &Message{ID: msg.ID, Stream: stream, Values: msg.Values, Attempt: int(entry.RetryCount) + 1}
+
+				// Сообщение, на котором потребители падали раньше, чем успевали вернуть ошибку
+				if m.Attempt > c.cfg.MaxRetries+1 {
+					c.deadLetter(ctx, m, "max retries exceeded")
+					continue
+				}
+
+				// XCLAIM увеличивает счетчик доставок
+				c.dispatch(m)
+				claimed++
+			}
+		}
+
+		if int64(len(pending)) < count {
+			return nil
+		}
+
+		if start, err = nextID(pending[len(pending)-1].ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nextID возвращает наименьший идентификатор записи потока, следующий за id.
+// Используется вместо исключающей границы "(id", которую поддерживает только Redis 6.2+.
+func nextID(id string) (string, error) {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return "", fmt.Errorf("invalid stream id %q", id)
+	}
+
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid stream id %q: %w", id, err)
+	}
+
+	if n < math.MaxUint64 {
+		return ms + "-" + strconv.FormatUint(n+1, 10), nil
+	}
+
+	t, err := strconv.ParseUint(ms, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid stream id %q: %w", id, err)
+	}
+
+	return strconv.FormatUint(t+1, 10) + "-0", nil
+}
+
+// deadLetter переносит сообщение в поток недоставленных сообщений и подтверждает его.
+func (c *Consumer) deadLetter(ctx context.Context, msg *Message, reason string) {
+	values := make(map[string]interface{}, len(msg.Values)+3)
+	for key, val := range msg.Values {
+		values[key] = val
+	}
+
+	values[FieldDeadStream] = msg.Stream
+	values[FieldDeadID] = msg.ID
+	values[FieldDeadReason] = reason
+
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: msg.Stream + c.cfg.DeadLetterSuffix, Values: values})
+		pipe.XAck(ctx, msg.Stream, c.cfg.Group, msg.ID)
+
+		return nil
+	})
+	if err != nil {
+		c.log.Error().Err(err).Str("stream", msg.Stream).Str("id", msg.ID).Msg("queue dead letter")
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent помечает ошибку как неисправимую: сообщение сразу переносится
+// в DeadLetter поток без повторных попыток.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
@@ -0,0 +1,203 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type payload struct {
+	Name string `json:"name"`
+}
+
+func newConsumer(t *testing.T) (*Consumer, *redis.Client) {
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	log := zerolog.Nop()
+
+	return NewConsumer(rdb, Config{
+		Group:         "test",
+		Consumer:      "consumer-1",
+		Concurrency:   2,
+		MaxRetries:    2,
+		Backoff:       10 * time.Millisecond,
+		MaxBackoff:    20 * time.Millisecond,
+		ClaimInterval: 10 * time.Millisecond,
+		ClaimTimeout:  20 * time.Millisecond,
+		Block:         10 * time.Millisecond,
+	}, &log), rdb
+}
+
+func TestConsumer_HandleJSON(t *testing.T) {
+	consumer, rdb := newConsumer(t)
+	received := make(chan payload, 1)
+
+	HandleJSON(consumer, "stream", func(ctx context.Context, value payload) error {
+		received <- value
+		return nil
+	})
+
+	go consumer.Run(context.Background())
+	defer consumer.Close(context.Background())
+
+	_, err := Publish(context.Background(), rdb, "stream", payload{Name: "test"})
+	require.NoError(t, err)
+
+	select {
+	case value := <-received:
+		assert.Equal(t, "test", value.Name, "values not equal")
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not received")
+	}
+
+	assert.Eventually(t, func() bool {
+		pending, err := rdb.XPending(context.Background(), "stream", "test").Result()
+		return err == nil && pending.Count == 0
+	}, time.Second, 10*time.Millisecond, "message not acknowledged")
+}
+
+func TestConsumer_deadLetter(t *testing.T) {
+	consumer, rdb := newConsumer(t)
+	var attempts atomic.Int32
+
+	consumer.Handle("stream", func(ctx context.Context, msg *Message) error {
+		attempts.Add(1)
+		return errors.New("failed")
+	})
+
+	go consumer.Run(context.Background())
+	defer consumer.Close(context.Background())
+
+	_, err := Publish(context.Background(), rdb, "stream", payload{Name: "test"})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return rdb.XLen(context.Background(), "stream:dead").Val() == 1
+	}, 2*time.Second, 10*time.Millisecond, "message not moved to dead letter")
+
+	assert.Equal(t, int32(3), attempts.Load(), "wrong number of attempts")
+}
+
+func TestConsumer_Close(t *testing.T) {
+	consumer, rdb := newConsumer(t)
+	started, finished := make(chan struct{}), make(chan struct{})
+
+	consumer.Handle("stream", func(ctx context.Context, msg *Message) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		close(finished)
+		return nil
+	})
+
+	go consumer.Run(context.Background())
+
+	_, err := Publish(context.Background(), rdb, "stream", payload{Name: "test"})
+	require.NoError(t, err)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, consumer.Close(ctx))
+
+	select {
+	case <-finished:
+	default:
+		t.Fatal("consumer closed before message was processed")
+	}
+}
+
+func TestConsumer_claimTimeout(t *testing.T) {
+	consumer, rdb := newConsumer(t)
+	consumer.cfg.ClaimTimeout = time.Second
+
+	var attempts atomic.Int32
+	done := make(chan struct{})
+
+	consumer.Handle("stream", func(ctx context.Context, msg *Message) error {
+		// Обработка дольше задержки повторной обработки, но меньше ClaimTimeout
+		if attempts.Add(1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+			close(done)
+		}
+
+		return nil
+	})
+
+	go consumer.Run(context.Background())
+	defer consumer.Close(context.Background())
+
+	_, err := Publish(context.Background(), rdb, "stream", payload{Name: "test"})
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not processed")
+	}
+
+	assert.Equal(t, int32(1), attempts.Load(), "message in progress claimed again")
+}
+
+func TestConsumer_claimBackoffPage(t *testing.T) {
+	consumer, rdb := newConsumer(t)
+	consumer.cfg.Concurrency = 1
+	consumer.cfg.MaxBackoff = time.Hour
+
+	ctx := context.Background()
+	require.NoError(t, rdb.XGroupCreateMkStream(ctx, "stream", consumer.cfg.Group, "0").Err())
+
+	first, err := Publish(ctx, rdb, "stream", payload{Name: "first"})
+	require.NoError(t, err)
+	second, err := Publish(ctx, rdb, "stream", payload{Name: "second"})
+	require.NoError(t, err)
+
+	// Оба сообщения получены упавшим потребителем, первое доставлялось много раз
+	// и еще ждет повторной обработки
+	require.NoError(t, rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: consumer.cfg.Group, Consumer: "crashed", Streams: []string{"stream", ">"}, Count: 2,
+	}).Err())
+	for i := 0; i < 5; i++ {
+		require.NoError(t, rdb.XClaim(ctx, &redis.XClaimArgs{
+			Stream: "stream", Group: consumer.cfg.Group, Consumer: "crashed", Messages: []string{first},
+		}).Err())
+	}
+
+	processed := make(chan string, 2)
+	consumer.Handle("stream", func(ctx context.Context, msg *Message) error {
+		processed <- msg.ID
+		return nil
+	})
+
+	go consumer.Run(ctx)
+	defer consumer.Close(ctx)
+
+	// Сообщение в задержке не занимает всю страницу PEL
+	select {
+	case id := <-processed:
+		assert.Equal(t, second, id)
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("message behind backoff entry not claimed")
+	}
+}
+
+func TestNextID(t *testing.T) {
+	id, err := nextID("1700000000000-5")
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000000-6", id)
+
+	id, err = nextID("1700000000000-18446744073709551615")
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000001-0", id)
+
+	_, err = nextID("invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+// Package retry повторяет операцию с экспоненциальной задержкой, например
+// подключение к зависимостям, которые еще не запустились.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Config параметры повторов. Нулевой MaxWait отключает повторы.
+type Config struct {
+	// InitialInterval задержка перед первым повтором.
+	InitialInterval time.Duration
+	// MaxInterval максимальная задержка между повторами.
+	MaxInterval time.Duration
+	// MaxWait общее время ожидания, после которого возвращается последняя ошибка.
+	MaxWait time.Duration
+	// Jitter доля случайного отклонения задержки от 0 до 1.
+	Jitter float64
+}
+
+// Do выполняет fn, пока она не завершится успешно, не истечет MaxWait или не будет отменен ctx.
+// Последняя попытка выполняется по истечении MaxWait. Ошибка, помеченная Permanent,
+// возвращается без повторов. Каждая неудачная попытка записывается в log с именем зависимости name.
+func Do(ctx context.Context, cfg Config, log *zerolog.Logger, name string, fn func(ctx context.Context) error) error {
+	start := time.Now()
+	interval := cfg.InitialInterval
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				log.Info().Str("dependency", name).Int("attempt", attempt).Dur("waited", time.Since(start)).Msg("dependency is available")
+			}
+
+			return nil
+		}
+
+		remaining := cfg.MaxWait - time.Since(start)
+		if IsPermanent(err) || cfg.MaxWait <= 0 || remaining <= 0 {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		delay := cfg.delay(interval)
+		if delay > remaining {
+			delay = remaining
+		}
+
+		log.Warn().Err(err).Str("dependency", name).Int("attempt", attempt).Dur("retry_in", delay).Msg("dependency is not available")
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", name, err)
+		case <-time.After(delay):
+		}
+
+		interval *= 2
+		if interval > cfg.MaxInterval {
+			interval = cfg.MaxInterval
+		}
+	}
+}
+
+// Permanent помечает ошибку как неисправимую повтором, например, неверные учетные данные.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent сообщает, помечена ли ошибка как неисправимая.
+func IsPermanent(err error) bool {
+	var perr *permanentError
+
+	return errors.As(err, &perr)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// delay возвращает interval со случайным отклонением в пределах Jitter.
+func (cfg Config) delay(interval time.Duration) time.Duration {
+	if cfg.Jitter <= 0 || interval <= 0 {
+		return interval
+	}
+
+	return interval + time.Duration((rand.Float64()*2-1)*cfg.Jitter*float64(interval))
+}
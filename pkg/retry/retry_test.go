@@ -0,0 +1,114 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+var errRefused = errors.New("connection refused")
+
+func TestDo(t *testing.T) {
+	log := zerolog.Nop()
+	cfg := Config{InitialInterval: time.Millisecond, MaxInterval: 4 * time.Millisecond, MaxWait: time.Second, Jitter: 0.5}
+
+	attempts := 0
+	err := Do(context.Background(), cfg, &log, "postgres", func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errRefused
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestDo_maxWait(t *testing.T) {
+	log := zerolog.Nop()
+	cfg := Config{InitialInterval: 10 * time.Millisecond, MaxInterval: 10 * time.Millisecond, MaxWait: 50 * time.Millisecond}
+
+	start := time.Now()
+	err := Do(context.Background(), cfg, &log, "redis", func(context.Context) error {
+		return errRefused
+	})
+
+	assert.ErrorIs(t, err, errRefused)
+	assert.EqualError(t, err, "redis: connection refused")
+	assert.Less(t, time.Since(start), time.Second, "max wait exceeded")
+
+	attempts := 0
+	err = Do(context.Background(), Config{}, &log, "redis", func(context.Context) error {
+		attempts++
+		return errRefused
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts, "retried without max wait")
+}
+
+func TestDo_lastAttempt(t *testing.T) {
+	log := zerolog.Nop()
+	cfg := Config{InitialInterval: time.Hour, MaxInterval: time.Hour, MaxWait: 30 * time.Millisecond}
+
+	// Задержка больше оставшегося времени сокращается, и перед ошибкой выполняется последняя попытка
+	start := time.Now()
+	attempts := 0
+	err := Do(context.Background(), cfg, &log, "postgres", func(context.Context) error {
+		attempts++
+		if attempts < 2 {
+			return errRefused
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.GreaterOrEqual(t, time.Since(start), cfg.MaxWait)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestDo_permanent(t *testing.T) {
+	log := zerolog.Nop()
+	cfg := Config{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, MaxWait: time.Second}
+
+	attempts := 0
+	err := Do(context.Background(), cfg, &log, "postgres", func(context.Context) error {
+		attempts++
+		return Permanent(errRefused)
+	})
+
+	assert.ErrorIs(t, err, errRefused)
+	assert.True(t, IsPermanent(err))
+	assert.EqualError(t, err, "postgres: connection refused")
+	assert.Equal(t, 1, attempts, "permanent error retried")
+}
+
+func TestDo_cancel(t *testing.T) {
+	log := zerolog.Nop()
+	cfg := Config{InitialInterval: time.Hour, MaxInterval: time.Hour, MaxWait: 2 * time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, Do(ctx, cfg, &log, "postgres", func(context.Context) error {
+		return errRefused
+	}), errRefused)
+}
+
+func TestConfig_delay(t *testing.T) {
+	cfg := Config{Jitter: 0.2}
+
+	for i := 0; i < 100; i++ {
+		delay := cfg.delay(time.Second)
+		assert.GreaterOrEqual(t, delay, 800*time.Millisecond)
+		assert.LessOrEqual(t, delay, 1200*time.Millisecond)
+	}
+}
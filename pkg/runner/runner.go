@@ -0,0 +1,174 @@
+// Package runner общий каркас фоновых обработчиков: выполнение до вызова Close или отмены
+// контекста, ограничение количества одновременно выполняемой работы и ожидание ее завершения
+// при остановке.
+package runner
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Runner управляет запуском и остановкой обработчика. Run обработчика вызывает Start,
+// запускает работу через Go, а Close обработчика вызывает Close.
+type Runner struct {
+	slots    chan struct{}
+	inflight sync.WaitGroup
+	started  atomic.Bool
+	ctx      context.Context
+	stop     context.CancelFunc
+	done     chan struct{}
+}
+
+// New создает Runner, выполняющий одновременно не больше concurrency функций.
+// Нулевое значение concurrency снимает ограничение.
+func New(concurrency int) *Runner {
+	ctx, stop := context.WithCancel(context.Background())
+
+	r := &Runner{
+		ctx:  ctx,
+		stop: stop,
+		done: make(chan struct{}),
+	}
+
+	if concurrency > 0 {
+		r.slots = make(chan struct{}, concurrency)
+	}
+
+	return r
+}
+
+// Start вызывается в начале Run обработчика. Возвращает контекст, который отменяется при отмене
+// parent или вызове Close, и функцию, которую нужно вызвать при выходе из Run.
+func (r *Runner) Start(parent context.Context) (context.Context, func()) {
+	r.started.Store(true)
+
+	go func() {
+		select {
+		case <-parent.Done():
+			r.stop()
+		case <-r.ctx.Done():
+		}
+	}()
+
+	return r.ctx, func() { close(r.done) }
+}
+
+// Close отменяет контекст Run и ожидает выхода из Run и завершения функций, запущенных Go,
+// но не дольше, чем до отмены ctx.
+func (r *Runner) Close(ctx context.Context) error {
+	r.stop()
+
+	if !r.started.Load() {
+		return nil
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		<-r.done
+		r.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Free ожидает хотя бы одного свободного слота и возвращает количество свободных слотов.
+// Возвращает 0, если ctx отменен раньше.
+func (r *Runner) Free(ctx context.Context) int {
+	if r.slots == nil {
+		return 1
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0
+	}
+
+	free := 1 + cap(r.slots) - len(r.slots)
+	<-r.slots
+
+	return free
+}
+
+// Go выполняет fn в отдельной горутине, дожидаясь свободного слота.
+// Close ожидает завершения fn.
+func (r *Runner) Go(fn func()) {
+	if r.slots != nil {
+		r.slots <- struct{}{}
+	}
+
+	r.inflight.Add(1)
+
+	go func() {
+		defer func() {
+			if r.slots != nil {
+				<-r.slots
+			}
+
+			r.inflight.Done()
+		}()
+
+		fn()
+	}()
+}
+
+// Poll вызывает fn каждые interval до отмены ctx. Пока fn сообщает, что обработала полную
+// пачку, она вызывается снова без ожидания. Ошибка fn передается в onError и откладывает
+// следующий вызов до очередного интервала.
+func Poll(ctx context.Context, interval time.Duration, fn func(ctx context.Context) (bool, error), onError func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for ctx.Err() == nil {
+				more, err := fn(ctx)
+				if err != nil {
+					onError(err)
+					break
+				}
+
+				if !more {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Sleep ожидает d или отмены ctx.
+func Sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// Backoff экспоненциальная задержка перед попыткой attempt: base, удвоенная за каждую
+// предыдущую попытку, но не больше max. Нулевое значение max снимает ограничение.
+func Backoff(base, max time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && (max <= 0 || delay < max); i++ {
+		delay *= 2
+	}
+
+	if max > 0 && delay > max {
+		delay = max
+	}
+
+	return delay
+}
@@ -0,0 +1,148 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_Close(t *testing.T) {
+	r := New(2)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+
+		ctx, finish := r.Start(context.Background())
+		defer finish()
+
+		r.Go(func() {
+			close(started)
+			<-release
+		})
+
+		<-ctx.Done()
+	}()
+
+	<-started
+
+	// Выполняемая функция не завершилась, Close ждет до отмены своего контекста
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, r.Close(ctx), context.DeadlineExceeded)
+	<-exited
+
+	close(release)
+	assert.NoError(t, r.Close(context.Background()))
+}
+
+func TestRunner_Close_notStarted(t *testing.T) {
+	assert.NoError(t, New(1).Close(context.Background()))
+}
+
+func TestRunner_Start_parentCanceled(t *testing.T) {
+	r := New(1)
+
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, finish := r.Start(parent)
+	defer finish()
+
+	cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context is not canceled with parent")
+	}
+}
+
+func TestRunner_Go_concurrency(t *testing.T) {
+	r := New(2)
+	ctx := context.Background()
+
+	var running, peak atomic.Int32
+
+	for i := 0; i < 10; i++ {
+		r.Go(func() {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		})
+	}
+
+	_, finish := r.Start(ctx)
+	finish()
+	require.NoError(t, r.Close(ctx))
+
+	assert.EqualValues(t, 2, peak.Load())
+	assert.Equal(t, 2, r.Free(ctx))
+}
+
+func TestRunner_Free_canceled(t *testing.T) {
+	r := New(1)
+
+	block := make(chan struct{})
+	r.Go(func() { <-block })
+	defer close(block)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Zero(t, r.Free(ctx))
+}
+
+func TestPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls, errs atomic.Int32
+
+	go Poll(ctx, 10*time.Millisecond, func(context.Context) (bool, error) {
+		switch calls.Add(1) {
+		case 1, 2:
+			// Полные пачки обрабатываются без ожидания интервала
+			return true, nil
+		case 3:
+			return false, nil
+		case 4:
+			return true, errors.New("failed")
+		}
+
+		cancel()
+
+		return false, nil
+	}, func(error) { errs.Add(1) })
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("poll is not finished")
+	}
+
+	assert.EqualValues(t, 5, calls.Load())
+	assert.EqualValues(t, 1, errs.Load())
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, Backoff(time.Second, time.Minute, 0))
+	assert.Equal(t, time.Second, Backoff(time.Second, time.Minute, 1))
+	assert.Equal(t, 4*time.Second, Backoff(time.Second, time.Minute, 3))
+	assert.Equal(t, time.Minute, Backoff(time.Second, time.Minute, 20))
+	assert.Equal(t, 8*time.Second, Backoff(time.Second, 0, 4))
+}
@@ -0,0 +1,72 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version версия схемы подписи.
+const Version = "v1"
+
+var (
+	ErrMalformed = errors.New("malformed signature")
+	ErrMismatch  = errors.New("signature mismatch")
+	ErrExpired   = errors.New("signature timestamp out of tolerance")
+)
+
+// Sign подписывает тело запроса HMAC-SHA256 вместе с меткой времени.
+// Результат имеет вид "t=<unix>,v1=<hex>".
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+	return fmt.Sprintf("t=%s,%s=%s", ts, Version, digest(secret, ts, body))
+}
+
+// Verify проверяет подпись и то, что метка времени отличается от now не более чем на tolerance.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var ts, sig string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrMalformed
+		}
+
+		switch key {
+		case "t":
+			ts = value
+		case Version:
+			sig = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return ErrMalformed
+	}
+
+	if diff := now.Sub(time.Unix(unix, 0)); diff > tolerance || diff < -tolerance {
+		return ErrExpired
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(digest(secret, ts, body))) {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+func digest(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
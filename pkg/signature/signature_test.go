@@ -0,0 +1,39 @@
+package signature
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var body = []byte(`{"type":"user.registered"}`)
+
+func TestSign(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	header := Sign("secret", now, body)
+
+	assert.Contains(t, header, "t=1700000000,v1=", "wrong header format")
+	assert.NoError(t, Verify("secret", header, body, now, time.Minute))
+}
+
+func TestVerify_mismatch(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	header := Sign("secret", now, body)
+
+	assert.ErrorIs(t, Verify("other", header, body, now, time.Minute), ErrMismatch)
+	assert.ErrorIs(t, Verify("secret", header, []byte("{}"), now, time.Minute), ErrMismatch)
+}
+
+func TestVerify_expired(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	header := Sign("secret", now, body)
+
+	assert.ErrorIs(t, Verify("secret", header, body, now.Add(10*time.Minute), time.Minute), ErrExpired)
+}
+
+func TestVerify_malformed(t *testing.T) {
+	for _, header := range []string{"", "t=abc,v1=00", "t=1700000000", "garbage"} {
+		assert.ErrorIs(t, Verify("secret", header, body, time.Unix(1700000000, 0), time.Minute), ErrMalformed, header)
+	}
+}
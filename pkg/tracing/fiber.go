@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"service-template/pkg/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware создает спан для каждого HTTP-запроса, продолжая трассировку из заголовка traceparent.
+// Контекст со спаном доступен обработчикам через c.UserContext().
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+
+		ctx, span := Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(c.Method()),
+				semconv.HTTPTarget(c.OriginalURL()),
+				semconv.HTTPClientIP(c.IP()),
+			),
+		)
+		defer span.End()
+
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		status := problem.StatusCode(c, err)
+
+		// Шаблон маршрута известен только после выполнения обработчика
+		route := c.Route().Path
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPStatusCode(status))
+
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+
+			if err != nil {
+				span.RecordError(err)
+			}
+		}
+
+		return err
+	}
+}
+
+// headerCarrier адаптирует заголовки запроса fiber к propagation.TextMapCarrier.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+var _ propagation.TextMapCarrier = headerCarrier{}
+
+func (h headerCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	headers := h.c.GetReqHeaders()
+
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,24 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Logger возвращает логгер, добавляющий в записи идентификаторы трассировки и спана из ctx.
+// Если в ctx нет спана, возвращается log.
+func Logger(ctx context.Context, log *zerolog.Logger) *zerolog.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return log
+	}
+
+	logger := log.With().
+		Str("trace_id", sc.TraceID().String()).
+		Str("span_id", sc.SpanID().String()).
+		Logger()
+
+	return &logger
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedisHook создает спаны для команд go-redis. Аргументы команд в спаны
+// не попадают, так как могут содержать токены и персональные данные.
+type RedisHook struct{}
+
+var _ redis.Hook = RedisHook{}
+
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := Start(ctx, "redis.dial", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBSystemRedis))
+
+		conn, err := next(ctx, network, addr)
+		End(span, &err)
+
+		return conn, err
+	}
+}
+
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := Start(ctx, "redis."+cmd.Name(),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperation(cmd.Name())),
+		)
+		defer span.End()
+
+		err := next(ctx, cmd)
+		record(span, err)
+
+		return err
+	}
+}
+
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := Start(ctx, "redis.pipeline",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemRedis, attribute.Int("db.redis.num_cmd", len(cmds))),
+		)
+		defer span.End()
+
+		err := next(ctx, cmds)
+		record(span, err)
+
+		return err
+	}
+}
+
+func record(span trace.Span, err error) {
+	// redis.Nil означает отсутствие ключа, а не ошибку выполнения
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,99 @@
+// Package tracing настраивает трассировку OpenTelemetry и инструментирует
+// HTTP-сервер, Redis и методы сервисов.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"service-template/pkg/problem"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Name имя инструментирующей библиотеки.
+const Name = "service-template"
+
+// Экспортеры трассировки.
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Config конфигурация трассировки.
+type Config struct {
+	ServiceName string
+	Exporter    string
+	// Endpoint адрес OTLP/HTTP коллектора в формате host:port.
+	Endpoint string
+	Insecure bool
+	// SampleRatio доля трассируемых запросов, если решение не принято вызывающим сервисом.
+	SampleRatio float64
+}
+
+// Setup создает провайдер трассировки и устанавливает его глобальным вместе с
+// пропагатором W3C Trace Context. Возвращает функцию, отправляющую накопленные
+// спаны и останавливающую провайдер.
+func Setup(ctx context.Context, cfg Config) (func(ctx context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		err = fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("tracing exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Start создает спан с именем name.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(Name).Start(ctx, name, opts...)
+}
+
+// End завершает спан и записывает в него ошибку, если *err не nil. Спан отмечается ошибкой
+// только для внутренних и непредвиденных ошибок: ошибки клиента вроде 404 сбоем не являются.
+// Предназначен для вызова через defer с именованным результатом err.
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+
+		if problem.From(*err).Status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+	}
+
+	span.End()
+}
@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"service-template/pkg/problem"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := newRecorder(t)
+
+	var out bytes.Buffer
+	log := zerolog.New(&out)
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		Logger(c.UserContext(), &log).Info().Msg("handled")
+		return fiber.ErrBadGateway
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/users/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "GET /users/:id", span.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String(), "trace not continued")
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String(), "parent not extracted")
+	assert.Equal(t, codes.Error, span.Status().Code)
+
+	assert.Contains(t, out.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`, "trace id not logged")
+	assert.Contains(t, out.String(), span.SpanContext().SpanID().String(), "span id not logged")
+}
+
+func TestRedisHook(t *testing.T) {
+	recorder := newRecorder(t)
+
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	rdb.AddHook(RedisHook{})
+
+	ctx, parent := Start(context.Background(), "parent")
+	assert.ErrorIs(t, rdb.Get(ctx, "missing").Err(), redis.Nil)
+	parent.End()
+
+	var get sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "redis.get" {
+			get = span
+		}
+	}
+
+	require.NotNil(t, get, "command span not recorded")
+	assert.Equal(t, parent.SpanContext().SpanID(), get.Parent().SpanID(), "span not nested")
+	assert.Equal(t, codes.Unset, get.Status().Code, "redis.Nil recorded as error")
+}
+
+func TestEnd(t *testing.T) {
+	recorder := newRecorder(t)
+
+	end := func(name string, err error) sdktrace.ReadOnlySpan {
+		_, span := Start(context.Background(), name)
+		End(span, &err)
+
+		spans := recorder.Ended()
+
+		return spans[len(spans)-1]
+	}
+
+	assert.Equal(t, codes.Unset, end("ok", nil).Status().Code)
+	assert.Equal(t, codes.Unset, end("not found", problem.New(http.StatusNotFound, "not_found", "not found")).Status().Code)
+	assert.Equal(t, codes.Unset, end("conflict", fmt.Errorf("update: %w", fiber.ErrConflict)).Status().Code)
+	assert.Equal(t, codes.Error, end("unavailable", fiber.ErrServiceUnavailable).Status().Code)
+	assert.Equal(t, codes.Error, end("unexpected", errors.New("connection refused")).Status().Code)
+}
@@ -0,0 +1,57 @@
+package txmanager
+
+import (
+	"database/sql"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Уровни изоляции транзакций.
+const (
+	IsolationReadCommitted  = "read committed"
+	IsolationRepeatableRead = "repeatable read"
+	IsolationSerializable   = "serializable"
+)
+
+// Config параметры транзакций.
+type Config struct {
+	// Isolation уровень изоляции, пустой — уровень БД по умолчанию. В SQLite транзакции
+	// всегда сериализуемые, и параметр не используется.
+	Isolation string `json:"isolation" yaml:"isolation" env:"X_TX_ISOLATION"`
+	// MaxAttempts количество попыток выполнить транзакцию, отмененную из-за конфликта
+	// с параллельной транзакцией.
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts" env:"X_TX_MAX_ATTEMPTS"`
+	// Backoff задержка перед первым повтором, удваивается с каждой попыткой.
+	Backoff time.Duration `json:"backoff" yaml:"backoff" env:"X_TX_BACKOFF"`
+}
+
+// NewConfig возвращает конфигурацию со значениями по умолчанию.
+func NewConfig() *Config {
+	return &Config{
+		MaxAttempts: 3,
+		Backoff:     20 * time.Millisecond,
+	}
+}
+
+func (cfg Config) Validate() error {
+	return validation.ValidateStruct(&cfg,
+		validation.Field(&cfg.Isolation, validation.In(IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable)),
+		validation.Field(&cfg.MaxAttempts, validation.Required, validation.Min(1)),
+		validation.Field(&cfg.Backoff, validation.Min(time.Duration(0))),
+	)
+}
+
+// level возвращает уровень изоляции для database/sql.
+func (cfg *Config) level() sql.IsolationLevel {
+	switch cfg.Isolation {
+	case IsolationReadCommitted:
+		return sql.LevelReadCommitted
+	case IsolationRepeatableRead:
+		return sql.LevelRepeatableRead
+	case IsolationSerializable:
+		return sql.LevelSerializable
+	}
+
+	return sql.LevelDefault
+}
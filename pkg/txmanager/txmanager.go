@@ -0,0 +1,99 @@
+// Package txmanager выполняет работу нескольких репозиториев в одной транзакции.
+// Транзакция хранится в контексте, и репозитории, получающие соединение через DB,
+// используют ее без явной передачи.
+package txmanager
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"service-template/pkg/drivers/postgres"
+	"service-template/pkg/drivers/sqlite"
+
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type txKey struct{}
+
+// Manager запускает транзакции в БД.
+type Manager struct {
+	db  *bun.DB
+	cfg *Config
+	log *zerolog.Logger
+}
+
+func NewManager(db *bun.DB, cfg *Config, log *zerolog.Logger) *Manager {
+	return &Manager{
+		db:  db,
+		cfg: cfg,
+		log: log,
+	}
+}
+
+// WithinTx выполняет fn в транзакции, доступной репозиториям через контекст fn.
+// Если ctx уже содержит транзакцию, fn выполняется в точке сохранения внутри нее:
+// ошибка fn откатывает только изменения fn, а фиксирует их внешняя транзакция.
+//
+// Транзакция верхнего уровня, отмененная из-за конфликта с параллельной транзакцией,
+// повторяется до MaxAttempts раз, поэтому fn может выполниться несколько раз и не должна
+// изменять состояние вне транзакции, от которого зависит повтор.
+func (m *Manager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := From(ctx); ok {
+		return tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, sp))
+		})
+	}
+
+	backoff := m.cfg.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := m.db.RunInTx(ctx, m.options(), func(ctx context.Context, tx bun.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+		if err == nil || attempt >= m.cfg.MaxAttempts || !Retryable(err) {
+			return err
+		}
+
+		m.log.Warn().Err(err).Int("attempt", attempt).Dur("retry_in", backoff).Msg("transaction conflict, retrying")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// options возвращает параметры транзакции верхнего уровня.
+func (m *Manager) options() *sql.TxOptions {
+	if sqlite.Is(m.db) || m.cfg.Isolation == "" {
+		return nil
+	}
+
+	return &sql.TxOptions{Isolation: m.cfg.level()}
+}
+
+// From возвращает транзакцию из контекста.
+func From(ctx context.Context) (bun.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(bun.Tx)
+
+	return tx, ok
+}
+
+// DB возвращает транзакцию из контекста или db, если контекст создан вне транзакции.
+func DB(ctx context.Context, db bun.IDB) bun.IDB {
+	if tx, ok := From(ctx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// Retryable сообщает, что транзакция отменена из-за конфликта и ее можно повторить.
+func Retryable(err error) bool {
+	return postgres.IsSerializationFailure(err) || sqlite.IsBusy(err)
+}
@@ -0,0 +1,192 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"service-template/pkg/drivers/sqlite"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+var errAbort = errors.New("abort")
+
+type item struct {
+	bun.BaseModel `bun:"table:items"`
+	ID            uint64 `bun:"id,pk,autoincrement"`
+	Name          string `bun:"name"`
+}
+
+func newDB(t *testing.T, path string, busyTimeout time.Duration) *bun.DB {
+	t.Helper()
+
+	cfg := sqlite.NewConfig()
+	cfg.Path = path
+	cfg.BusyTimeout = busyTimeout
+
+	db, err := sqlite.NewSQLiteDB(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.NewCreateTable().Model((*item)(nil)).IfNotExists().Exec(context.Background())
+	require.NoError(t, err)
+
+	return db
+}
+
+func newManager(db *bun.DB, cfg *Config) *Manager {
+	log := zerolog.Nop()
+
+	return NewManager(db, cfg, &log)
+}
+
+func add(ctx context.Context, db bun.IDB, name string) error {
+	_, err := DB(ctx, db).NewInsert().Model(&item{Name: name}).Exec(ctx)
+
+	return err
+}
+
+func names(t *testing.T, db bun.IDB) []string {
+	t.Helper()
+
+	var list []string
+	require.NoError(t, db.NewSelect().Model((*item)(nil)).Column("name").Order("id").Scan(context.Background(), &list))
+
+	return list
+}
+
+func TestManager_WithinTx(t *testing.T) {
+	db := newDB(t, sqlite.Memory, time.Second)
+	m := newManager(db, NewConfig())
+	ctx := context.Background()
+
+	_, ok := From(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, bun.IDB(db), DB(ctx, db))
+
+	err := m.WithinTx(ctx, func(ctx context.Context) error {
+		_, ok := From(ctx)
+		assert.True(t, ok)
+
+		return add(ctx, db, "committed")
+	})
+	require.NoError(t, err)
+
+	err = m.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, add(ctx, db, "rolled back"))
+
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	assert.Equal(t, []string{"committed"}, names(t, db))
+}
+
+func TestManager_WithinTx_nested(t *testing.T) {
+	db := newDB(t, sqlite.Memory, time.Second)
+	m := newManager(db, NewConfig())
+	ctx := context.Background()
+
+	err := m.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, add(ctx, db, "outer"))
+
+		// Ошибка вложенной транзакции откатывает только ее изменения
+		err := m.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, add(ctx, db, "inner rolled back"))
+
+			return errAbort
+		})
+		assert.ErrorIs(t, err, errAbort)
+
+		return m.WithinTx(ctx, func(ctx context.Context) error {
+			return add(ctx, db, "inner")
+		})
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"outer", "inner"}, names(t, db))
+
+	// Откат внешней транзакции откатывает и зафиксированные вложенные
+	err = m.WithinTx(ctx, func(ctx context.Context) error {
+		if err := m.WithinTx(ctx, func(ctx context.Context) error {
+			return add(ctx, db, "nested")
+		}); err != nil {
+			return err
+		}
+
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	assert.Equal(t, []string{"outer", "inner"}, names(t, db))
+}
+
+func TestManager_WithinTx_retry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db := newDB(t, path, 10*time.Millisecond)
+	other := newDB(t, path, 10*time.Millisecond)
+	ctx := context.Background()
+
+	// Транзакция другого соединения удерживает блокировку записи
+	lock, err := other.BeginTx(ctx, nil)
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.MaxAttempts = 2
+	cfg.Backoff = 10 * time.Millisecond
+
+	err = newManager(db, cfg).WithinTx(ctx, func(ctx context.Context) error {
+		return add(ctx, db, "busy")
+	})
+	require.Error(t, err)
+	assert.True(t, Retryable(err))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		lock.Rollback()
+	}()
+
+	cfg.MaxAttempts = 10
+	cfg.Backoff = 20 * time.Millisecond
+
+	attempts := 0
+	err = newManager(db, cfg).WithinTx(ctx, func(ctx context.Context) error {
+		attempts++
+		return add(ctx, db, "retried")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts, "fn runs once the lock is released")
+
+	assert.Equal(t, []string{"retried"}, names(t, db))
+
+	// Ошибки, не связанные с конфликтом, не повторяются
+	attempts = 0
+	err = newManager(db, cfg).WithinTx(ctx, func(ctx context.Context) error {
+		attempts++
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+	assert.Equal(t, 1, attempts)
+	assert.False(t, Retryable(err))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := NewConfig()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Isolation = IsolationSerializable
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Isolation = "snapshot"
+	assert.Error(t, cfg.Validate())
+
+	cfg = NewConfig()
+	cfg.MaxAttempts = 0
+	assert.Error(t, cfg.Validate())
+}